@@ -0,0 +1,2 @@
+ALTER TABLE usuarios
+    DROP COLUMN versao_token;
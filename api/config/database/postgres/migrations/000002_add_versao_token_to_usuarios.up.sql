@@ -0,0 +1,2 @@
+ALTER TABLE usuarios
+    ADD COLUMN versao_token int not null default 0;
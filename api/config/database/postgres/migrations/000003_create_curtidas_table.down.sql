@@ -0,0 +1,7 @@
+ALTER TABLE publicacoes
+    ADD COLUMN curtidas int default 0;
+
+UPDATE publicacoes p
+SET curtidas = (SELECT COUNT(*) FROM curtidas c WHERE c.publicacao_id = p.id);
+
+DROP TABLE curtidas;
@@ -0,0 +1,16 @@
+CREATE TABLE curtidas
+(
+    publicacao_id int not null,
+    FOREIGN KEY (publicacao_id)
+    REFERENCES publicacoes (id)
+    ON DELETE CASCADE,
+    usuario_id int not null,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios (id)
+    ON DELETE CASCADE,
+    curtidaEm timestamp default current_timestamp,
+    primary key (publicacao_id, usuario_id)
+);
+
+ALTER TABLE publicacoes
+    DROP COLUMN curtidas;
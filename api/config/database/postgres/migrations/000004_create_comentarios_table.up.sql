@@ -0,0 +1,16 @@
+CREATE TABLE comentarios
+(
+    id            int generated always as identity primary key,
+    publicacao_id int          not null,
+    FOREIGN KEY (publicacao_id)
+    REFERENCES publicacoes (id)
+    ON DELETE CASCADE,
+    autor_id      int          not null,
+    FOREIGN KEY (autor_id)
+    REFERENCES usuarios (id)
+    ON DELETE CASCADE,
+    conteudo      varchar(300) not null,
+    criadoEm      timestamp default current_timestamp
+);
+
+CREATE INDEX comentarios_publicacao_id_idx ON comentarios (publicacao_id);
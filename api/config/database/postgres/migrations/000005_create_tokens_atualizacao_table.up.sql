@@ -0,0 +1,17 @@
+CREATE TABLE tokens_atualizacao
+(
+    id          int generated always as identity primary key,
+    usuario_id  int         not null,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios (id)
+    ON DELETE CASCADE,
+    familia     varchar(64) not null,
+    hash        varchar(64) not null unique,
+    expiraEm    timestamp   not null,
+    usadoEm     timestamp,
+    revogadoEm  timestamp,
+    criadoEm    timestamp default current_timestamp
+);
+
+CREATE INDEX tokens_atualizacao_familia_idx ON tokens_atualizacao (familia);
+CREATE INDEX tokens_atualizacao_usuario_id_idx ON tokens_atualizacao (usuario_id);
@@ -0,0 +1,4 @@
+DROP TABLE tentativas_login;
+
+ALTER TABLE usuarios
+    DROP COLUMN administrador;
@@ -0,0 +1,22 @@
+ALTER TABLE usuarios
+    ADD COLUMN administrador boolean not null default false;
+
+CREATE TABLE tentativas_login
+(
+    id             int generated always as identity primary key,
+    email          varchar(255) not null,
+    usuario_id     int,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios (id)
+    ON DELETE SET NULL,
+    ip             varchar(45)  not null,
+    resultado      varchar(20)  not null,
+    responsavel_id int,
+    FOREIGN KEY (responsavel_id)
+    REFERENCES usuarios (id)
+    ON DELETE SET NULL,
+    criadoEm       timestamp default current_timestamp
+);
+
+CREATE INDEX tentativas_login_email_idx ON tentativas_login (email, criadoEm);
+CREATE INDEX tentativas_login_ip_idx ON tentativas_login (ip, criadoEm);
@@ -0,0 +1,6 @@
+DROP TABLE codigos_recuperacao;
+
+ALTER TABLE usuarios
+    DROP COLUMN totp_segredo,
+    DROP COLUMN totp_ativo,
+    DROP COLUMN totp_ultimo_passo;
@@ -0,0 +1,18 @@
+ALTER TABLE usuarios
+    ADD COLUMN totp_segredo      varchar(64),
+    ADD COLUMN totp_ativo        boolean not null default false,
+    ADD COLUMN totp_ultimo_passo bigint  not null default 0;
+
+CREATE TABLE codigos_recuperacao
+(
+    id         int generated always as identity primary key,
+    usuario_id int         not null,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios (id)
+    ON DELETE CASCADE,
+    hash       varchar(64) not null,
+    usadoEm    timestamp,
+    criadoEm   timestamp default current_timestamp
+);
+
+CREATE INDEX codigos_recuperacao_usuario_id_idx ON codigos_recuperacao (usuario_id);
@@ -0,0 +1 @@
+DROP TABLE redefinicoes_senha;
@@ -0,0 +1,14 @@
+CREATE TABLE redefinicoes_senha
+(
+    id         int generated always as identity primary key,
+    usuario_id int         not null,
+    FOREIGN KEY (usuario_id)
+    REFERENCES usuarios (id)
+    ON DELETE CASCADE,
+    hash       varchar(64) not null unique,
+    expiraEm   timestamp   not null,
+    usadoEm    timestamp,
+    criadoEm   timestamp default current_timestamp
+);
+
+CREATE INDEX redefinicoes_senha_usuario_id_idx ON redefinicoes_senha (usuario_id);
@@ -0,0 +1,4 @@
+ALTER TABLE usuarios ADD COLUMN email_verificado boolean not null default false;
+
+-- As contas criadas antes da verificação continuam com acesso completo.
+UPDATE usuarios SET email_verificado = true;
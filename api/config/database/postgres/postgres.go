@@ -0,0 +1,35 @@
+// Package postgres embute no binário os arquivos SQL do banco, para que a API
+// consiga aplicar o schema sem depender de arquivos no disco.
+package postgres
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed migrations/*.sql
+var migracoes embed.FS
+
+//go:embed seeds/*.sql
+var sementes embed.FS
+
+// Migracoes retorna os arquivos de migração, no formato NNNNNN_nome.up.sql e
+// NNNNNN_nome.down.sql.
+func Migracoes() fs.FS {
+	return subdiretorio(migracoes, "migrations")
+}
+
+// Sementes retorna os dados iniciais usados em desenvolvimento. Os arquivos
+// são idempotentes e executados em ordem alfabética.
+func Sementes() fs.FS {
+	return subdiretorio(sementes, "seeds")
+}
+
+func subdiretorio(arquivos embed.FS, diretorio string) fs.FS {
+	sub, erro := fs.Sub(arquivos, diretorio)
+	if erro != nil {
+		panic(erro)
+	}
+
+	return sub
+}
@@ -0,0 +1,29 @@
+INSERT INTO usuarios (nome, nick, email, senha)
+VALUES
+    ('Usuário 1', 'usuario_1', 'usuario1@gmail.com', '$2a$10$Pf9VaZrjfAsF14i5Zmzcbe6WbLLPnFh0U7zQAc1V6r0bt/XVw8Ml.'),
+    ('Usuário 2', 'usuario_2', 'usuario2@gmail.com', '$2a$10$Pf9VaZrjfAsF14i5Zmzcbe6WbLLPnFh0U7zQAc1V6r0bt/XVw8Ml.'),
+    ('Usuário 3', 'usuario_3', 'usuario3@gmail.com', '$2a$10$Pf9VaZrjfAsF14i5Zmzcbe6WbLLPnFh0U7zQAc1V6r0bt/XVw8Ml.')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO seguidores(usuario_id, seguidor_id)
+SELECT u.id, s.id
+FROM (VALUES
+    ('usuario_1', 'usuario_2'),
+    ('usuario_3', 'usuario_1'),
+    ('usuario_1', 'usuario_3')
+) AS dados(usuario, seguidor)
+INNER JOIN usuarios u ON u.nick = dados.usuario
+INNER JOIN usuarios s ON s.nick = dados.seguidor
+ON CONFLICT DO NOTHING;
+
+INSERT INTO publicacoes(titulo, conteudo, autor_id)
+SELECT dados.titulo, dados.conteudo, u.id
+FROM (VALUES
+    ('Publicação do Usuário 1', 'Essa é a publicação do usuário 1! Oba!', 'usuario_1'),
+    ('Publicação do Usuário 2', 'Essa é a publicação do usuário 2! Oba!', 'usuario_2'),
+    ('Publicação do Usuário 3', 'Essa é a publicação do usuário 3! Oba!', 'usuario_3')
+) AS dados(titulo, conteudo, autor)
+INNER JOIN usuarios u ON u.nick = dados.autor
+WHERE NOT EXISTS (
+    SELECT 1 FROM publicacoes p WHERE p.autor_id = u.id AND p.titulo = dados.titulo
+);
@@ -0,0 +1 @@
+UPDATE usuarios SET administrador = true WHERE nick = 'usuario_1';
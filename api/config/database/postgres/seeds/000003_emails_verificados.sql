@@ -0,0 +1 @@
+UPDATE usuarios SET email_verificado = true WHERE nick IN ('usuario_1', 'usuario_2', 'usuario_3');
@@ -1,37 +1,223 @@
 package main
 
 import (
+	"api/config/database/postgres"
+	"api/src/autenticacao"
 	"api/src/banco"
 	"api/src/config"
 	"api/src/controllers"
+	"api/src/email"
+	"api/src/limitador"
+	"api/src/logs"
 	"api/src/metrics"
+	"api/src/migrador"
+	"api/src/rastreamento"
 	"api/src/repositorios"
 	"api/src/router"
+	"api/src/servidor"
+	"context"
+	"database/sql"
 	"fmt"
 	"log"
-	"net/http"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/redis/go-redis/v9"
 )
 
+// tempoLimiteEnvioEmail é o prazo de cada envio de email, feito em segundo
+// plano.
+const tempoLimiteEnvioEmail = 30 * time.Second
+
 func main() {
-	config.Carregar()
+	cfg, erro := config.Carregar(os.Args[1:])
+	if erro != nil {
+		log.Fatalf("Configuração inválida:\n%v", erro)
+	}
+
+	logger, erro := logs.Novo(cfg.Logs.Nivel, cfg.Logs.Formato, os.Stdout)
+	if erro != nil {
+		log.Fatalf("Erro ao configurar os logs: %v", erro)
+	}
+	slog.SetDefault(logger)
+	slog.Info("Configuração carregada", "config", cfg)
+
+	encerrarRastreamento, erro := rastreamento.Configurar(context.Background(), rastreamento.Configuracao{
+		Exportador:  cfg.Rastreamento.Exportador,
+		NomeServico: cfg.Rastreamento.NomeServico,
+		Amostragem:  cfg.Rastreamento.Amostragem,
+	})
+	if erro != nil {
+		encerrarComErro("Erro ao configurar o rastreamento", erro)
+	}
 
 	metrics.Init()
 
-	db, err := banco.Conectar()
-	if err != nil {
-		log.Fatalf("Erro ao conectar ao banco: %v", err)
+	db, erro := banco.Conectar(cfg.Banco)
+	if erro != nil {
+		encerrarComErro("Erro ao conectar ao banco", erro)
+	}
+	metrics.RegistrarBanco(db)
+
+	migracoes, erro := prepararBanco(context.Background(), db, cfg.Banco)
+	if erro != nil {
+		encerrarComErro("Erro ao preparar o banco", erro)
 	}
-	defer db.Close()
+
+	enviadorDeEmail := email.NovoEnviadorEmSegundoPlano(criarEnviadorDeEmail(cfg.Email), tempoLimiteEnvioEmail)
 
 	repositorioUsuarios := repositorios.NovoRepositorioDeUsuarios(db)
-	usuarioController := controllers.NovoUsuarioController(repositorioUsuarios)
+	repositorioTokens := repositorios.NovoRepositorioDeTokens(db)
+	chaveiro, erro := criarChaveiro(cfg.Autenticacao)
+	if erro != nil {
+		encerrarComErro("Erro ao carregar as chaves dos tokens", erro)
+	}
+	emissor := autenticacao.NovoEmissor(chaveiro, cfg.Autenticacao.EmissorTokens, cfg.Autenticacao.AudienciaTokens)
+	repositorioTentativas := repositorios.NovoRepositorioDeTentativasLogin(db)
+	repositorioDoisFatores := repositorios.NovoRepositorioDeDoisFatores(db)
+	usuarioController := controllers.NovoUsuarioController(repositorioUsuarios, repositorioTokens, emissor, repositorioTentativas, autenticacao.PoliticaDeBloqueio{
+		MaxFalhas:      cfg.Autenticacao.MaxFalhasLogin,
+		MaxFalhasPorIP: cfg.Autenticacao.MaxFalhasLoginPorIP,
+		Janela:         cfg.Autenticacao.JanelaFalhasLogin,
+		TempoBloqueio:  cfg.Autenticacao.TempoBloqueioLogin,
+		AtrasoInicial:  cfg.Autenticacao.AtrasoInicialLogin,
+		AtrasoMaximo:   cfg.Autenticacao.AtrasoMaximoLogin,
+	}, repositorioDoisFatores, enviadorDeEmail, cfg.Email.URLVerificacaoEmail)
 
 	repositorioPublicacoes := repositorios.NovoRepositorioDePublicacoes(db)
 	publicacoesController := controllers.NovoPublicacoesController(repositorioPublicacoes)
 
-	fmt.Printf("Rodando a API na porta %d", config.Porta)
+	repositorioSeguidores := repositorios.NovoRepositorioDeSeguidores(db)
+	seguidoresController := controllers.NovoSeguidoresController(repositorioSeguidores)
+
+	repositorioComentarios := repositorios.NovoRepositorioDeComentarios(db)
+	comentariosController := controllers.NovoComentariosController(repositorioComentarios, repositorioPublicacoes)
+
+	s := servidor.Novo(fmt.Sprintf(":%d", cfg.Servidor.Porta), servidor.Tempos{
+		Leitura:               cfg.Servidor.TempoLimiteLeitura,
+		Escrita:               cfg.Servidor.TempoLimiteEscrita,
+		Ocioso:                cfg.Servidor.TempoLimiteOcioso,
+		Encerramento:          cfg.Servidor.TempoEncerramento,
+		EsperaAntesDeEncerrar: cfg.Servidor.EsperaAntesDeEncerrar,
+	})
+	s.AoEncerrar("banco", func(ctx context.Context) error {
+		return db.Close()
+	})
+	s.AoEncerrar("rastreamento", encerrarRastreamento)
+
+	armazenamentoDeLimites, encerrarLimites := criarArmazenamentoDeLimites(cfg.LimiteDeRequisicoes)
+	s.AoEncerrar("limite de requisições", encerrarLimites)
+
+	repositorioRedefinicoes := repositorios.NovoRepositorioDeRedefinicoesSenha(db)
+	senhaController := controllers.NovoSenhaController(repositorioUsuarios, repositorioRedefinicoes, enviadorDeEmail, cfg.Email.URLRedefinicaoSenha, cfg.Autenticacao.DuracaoRedefinicaoSenha)
+	// As redefinições em andamento ainda podem agendar emails, por isso são
+	// aguardadas antes dos envios.
+	s.AoEncerrar("redefinições de senha", senhaController.Aguardar)
+	s.AoEncerrar("envio de emails", enviadorDeEmail.Aguardar)
+
+	saudeController := controllers.NovoSaudeController(db, migracoes, s.Encerrando, cfg.Servidor.TempoLimiteVerificacaoSaude)
+
+	r := router.Gerar(cfg.Banco.TempoLimiteConsulta, armazenamentoDeLimites, usuarioController, publicacoesController, seguidoresController, comentariosController, senhaController, saudeController)
+
+	ctx, pararDeEscutarSinais := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer pararDeEscutarSinais()
+
+	slog.Info("Rodando a API", "porta", cfg.Servidor.Porta)
+
+	if erro := s.Executar(ctx, r); erro != nil {
+		encerrarComErro("Erro ao executar o servidor", erro)
+	}
+}
+
+func encerrarComErro(mensagem string, erro error) {
+	slog.Error(mensagem, "erro", erro)
+	os.Exit(1)
+}
+
+// criarArmazenamentoDeLimites escolhe onde ficam os baldes do limite de
+// requisições. A função retornada fecha a conexão com o Redis, quando houver.
+func criarArmazenamentoDeLimites(configuracao config.LimiteDeRequisicoes) (limitador.Armazenamento, func(context.Context) error) {
+	if configuracao.Armazenamento != "redis" {
+		return limitador.NovoArmazenamentoEmMemoria(), func(context.Context) error { return nil }
+	}
+
+	cliente := redis.NewClient(&redis.Options{
+		Addr:     configuracao.EnderecoRedis,
+		Password: configuracao.SenhaRedis,
+		DB:       configuracao.BancoRedis,
+	})
+
+	return limitador.NovoArmazenamentoRedis(cliente), func(context.Context) error { return cliente.Close() }
+}
+
+// criarChaveiro monta as chaves dos tokens. Sem JWT_SIGNING_KEY_FILE, os
+// tokens são assinados com HS256 e a SECRET_KEY. Com ele, a SECRET_KEY, quando
+// definida, passa a só verificar, para que os tokens HS256 já emitidos valham
+// até expirar.
+func criarChaveiro(configuracao config.Autenticacao) (*autenticacao.Chaveiro, error) {
+	var verificacao []autenticacao.Chave
+	for _, caminho := range configuracao.ArquivosChavesVerificacao {
+		chave, erro := autenticacao.CarregarChave(caminho)
+		if erro != nil {
+			return nil, erro
+		}
+		verificacao = append(verificacao, chave)
+	}
+
+	if configuracao.ArquivoChaveAssinatura == "" {
+		return autenticacao.NovoChaveiro(autenticacao.NovaChaveHMAC(configuracao.ChaveSecreta), verificacao...)
+	}
+
+	assinatura, erro := autenticacao.CarregarChave(configuracao.ArquivoChaveAssinatura)
+	if erro != nil {
+		return nil, erro
+	}
+
+	if len(configuracao.ChaveSecreta) > 0 {
+		verificacao = append(verificacao, autenticacao.NovaChaveHMAC(configuracao.ChaveSecreta))
+	}
+
+	return autenticacao.NovoChaveiro(assinatura, verificacao...)
+}
+
+// criarEnviadorDeEmail escolhe entre o envio por SMTP e o enviador local, que
+// grava as mensagens em um arquivo ou no log.
+func criarEnviadorDeEmail(configuracao config.Email) email.Enviador {
+	if configuracao.Enviador != "smtp" {
+		return email.NovoEnviadorLocal(configuracao.Arquivo, configuracao.Remetente)
+	}
+
+	return email.NovoEnviadorSMTP(email.SMTP{
+		Host:    configuracao.HostSMTP,
+		Porta:   configuracao.PortaSMTP,
+		Usuario: configuracao.UsuarioSMTP,
+		Senha:   configuracao.SenhaSMTP,
+	}, configuracao.Remetente)
+}
+
+// prepararBanco aplica as migrações e os dados iniciais conforme a
+// configuração. O migrador é retornado mesmo sem migrar, pois o /readyz o usa
+// para conferir a versão do schema.
+func prepararBanco(ctx context.Context, db *sql.DB, configuracao config.Banco) (*migrador.Migrador, error) {
+	m, erro := migrador.Novo(db, postgres.Migracoes())
+	if erro != nil {
+		return nil, erro
+	}
 
-	r := router.Gerar(usuarioController, publicacoesController)
+	if configuracao.Migrar {
+		if erro = m.Subir(ctx); erro != nil {
+			return nil, erro
+		}
+	}
+
+	if configuracao.Semear {
+		if erro = m.Semear(ctx, postgres.Sementes()); erro != nil {
+			return nil, erro
+		}
+	}
 
-	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", config.Porta), r))
+	return m, nil
 }
@@ -0,0 +1,45 @@
+package autenticacao
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// CriarTokenAtualizacao gera um refresh token aleatório e o hash que deve ser
+// persistido no lugar dele.
+func CriarTokenAtualizacao() (string, string, error) {
+	return criarTokenOpaco()
+}
+
+func HashTokenAtualizacao(token string) string {
+	return hashTokenOpaco(token)
+}
+
+// NovaFamiliaDeTokens gera o identificador compartilhado pelos refresh tokens de um mesmo login.
+func NovaFamiliaDeTokens() (string, error) {
+	bytes := make([]byte, 16)
+	if _, erro := rand.Read(bytes); erro != nil {
+		return "", erro
+	}
+
+	return hex.EncodeToString(bytes), nil
+}
+
+// criarTokenOpaco gera um token aleatório, sem significado fora do banco, e o
+// hash SHA-256 que é persistido no lugar dele.
+func criarTokenOpaco() (string, string, error) {
+	bytes := make([]byte, 32)
+	if _, erro := rand.Read(bytes); erro != nil {
+		return "", "", erro
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(bytes)
+	return token, hashTokenOpaco(token), nil
+}
+
+func hashTokenOpaco(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
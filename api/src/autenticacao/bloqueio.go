@@ -0,0 +1,49 @@
+package autenticacao
+
+import (
+	"api/src/modelos"
+	"time"
+)
+
+// PoliticaDeBloqueio define quanto tempo um cliente espera para tentar o
+// login de novo depois de errar a senha. Cada falha da conta dobra a espera a
+// partir de AtrasoInicial, até AtrasoMaximo; ao chegar a MaxFalhas dentro da
+// Janela, a conta fica bloqueada por TempoBloqueio. O IP é bloqueado apenas
+// ao chegar a MaxFalhasPorIP, pois vários usuários podem compartilhar o mesmo
+// endereço.
+type PoliticaDeBloqueio struct {
+	MaxFalhas      int
+	MaxFalhasPorIP int
+	Janela         time.Duration
+	TempoBloqueio  time.Duration
+	AtrasoInicial  time.Duration
+	AtrasoMaximo   time.Duration
+}
+
+// Espera retorna quanto tempo falta para a próxima tentativa ser aceita, ou
+// zero quando ela já pode ser feita.
+func (politica PoliticaDeBloqueio) Espera(conta, ip modelos.FalhasDeLogin) time.Duration {
+	var espera time.Duration
+
+	switch {
+	case politica.MaxFalhas > 0 && conta.Quantidade >= politica.MaxFalhas:
+		espera = politica.TempoBloqueio - conta.DesdeUltima
+	case conta.Quantidade > 0:
+		espera = politica.atraso(conta.Quantidade) - conta.DesdeUltima
+	}
+
+	if politica.MaxFalhasPorIP > 0 && ip.Quantidade >= politica.MaxFalhasPorIP {
+		espera = max(espera, politica.TempoBloqueio-ip.DesdeUltima)
+	}
+
+	return max(espera, 0)
+}
+
+func (politica PoliticaDeBloqueio) atraso(falhas int) time.Duration {
+	atraso := politica.AtrasoInicial
+	for i := 1; i < falhas && atraso < politica.AtrasoMaximo; i++ {
+		atraso *= 2
+	}
+
+	return min(atraso, politica.AtrasoMaximo)
+}
@@ -0,0 +1,43 @@
+package autenticacao
+
+import (
+	"api/src/modelos"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEspera_WhenFailuresAccumulate_ExpectedProgressiveDelayThenLockout(t *testing.T) {
+	politica := PoliticaDeBloqueio{
+		MaxFalhas:      5,
+		MaxFalhasPorIP: 20,
+		Janela:         15 * time.Minute,
+		TempoBloqueio:  15 * time.Minute,
+		AtrasoInicial:  time.Second,
+		AtrasoMaximo:   4 * time.Second,
+	}
+
+	casos := []struct {
+		nome     string
+		conta    modelos.FalhasDeLogin
+		ip       modelos.FalhasDeLogin
+		esperado time.Duration
+	}{
+		{"sem falhas", modelos.FalhasDeLogin{}, modelos.FalhasDeLogin{}, 0},
+		{"primeira falha", modelos.FalhasDeLogin{Quantidade: 1}, modelos.FalhasDeLogin{}, time.Second},
+		{"terceira falha", modelos.FalhasDeLogin{Quantidade: 3}, modelos.FalhasDeLogin{}, 4 * time.Second},
+		{"atraso limitado ao máximo", modelos.FalhasDeLogin{Quantidade: 4}, modelos.FalhasDeLogin{}, 4 * time.Second},
+		{"atraso já cumprido", modelos.FalhasDeLogin{Quantidade: 2, DesdeUltima: 3 * time.Second}, modelos.FalhasDeLogin{}, 0},
+		{"conta bloqueada", modelos.FalhasDeLogin{Quantidade: 5, DesdeUltima: time.Minute}, modelos.FalhasDeLogin{}, 14 * time.Minute},
+		{"bloqueio expirado", modelos.FalhasDeLogin{Quantidade: 5, DesdeUltima: 16 * time.Minute}, modelos.FalhasDeLogin{}, 0},
+		{"IP bloqueado", modelos.FalhasDeLogin{}, modelos.FalhasDeLogin{Quantidade: 20, DesdeUltima: 5 * time.Minute}, 10 * time.Minute},
+		{"IP abaixo do limite", modelos.FalhasDeLogin{}, modelos.FalhasDeLogin{Quantidade: 19}, 0},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			assert.Equal(t, caso.esperado, politica.Espera(caso.conta, caso.ip))
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package autenticacao
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+// TamanhoMinimoChaveRSA é o tamanho mínimo, em bits, das chaves RS256.
+const TamanhoMinimoChaveRSA = 2048
+
+var (
+	errChaveNaoSuportada  = errors.New("a chave deve ser RSA ou Ed25519")
+	errChaveSemAssinatura = errors.New("a chave de assinatura precisa ser uma chave privada")
+)
+
+// Chave assina ou verifica os tokens. As chaves lidas de um PEM público, e as
+// chaves de verificação do Chaveiro, não têm a parte privada e só verificam.
+type Chave struct {
+	// ID é o kid do cabeçalho dos tokens. Nas chaves RSA e Ed25519 é o
+	// thumbprint da chave pública (RFC 7638), o mesmo em todas as réplicas.
+	ID      string
+	metodo  jwt.SigningMethod
+	privada interface{}
+	publica interface{}
+}
+
+// NovaChaveHMAC cria a chave HS256 da SECRET_KEY. Ela não tem kid, assim como
+// os tokens emitidos antes das chaves assimétricas, e não é publicada no JWKS.
+func NovaChaveHMAC(segredo []byte) Chave {
+	return Chave{metodo: jwt.SigningMethodHS256, privada: segredo, publica: segredo}
+}
+
+// CarregarChave lê a chave do arquivo PEM informado com LerChavePEM.
+func CarregarChave(caminho string) (Chave, error) {
+	conteudo, erro := os.ReadFile(caminho)
+	if erro != nil {
+		return Chave{}, fmt.Errorf("ler a chave %s: %w", caminho, erro)
+	}
+
+	chave, erro := LerChavePEM(conteudo)
+	if erro != nil {
+		return Chave{}, fmt.Errorf("ler a chave %s: %w", caminho, erro)
+	}
+
+	return chave, nil
+}
+
+// LerChavePEM aceita chaves privadas PKCS #8, ou PKCS #1 para RSA, que
+// assinam e verificam, e chaves públicas PKIX, que apenas verificam.
+func LerChavePEM(conteudo []byte) (Chave, error) {
+	bloco, _ := pem.Decode(conteudo)
+	if bloco == nil {
+		return Chave{}, errors.New("nenhum bloco PEM encontrado")
+	}
+
+	switch bloco.Type {
+	case "PRIVATE KEY":
+		privada, erro := x509.ParsePKCS8PrivateKey(bloco.Bytes)
+		if erro != nil {
+			return Chave{}, erro
+		}
+
+		switch p := privada.(type) {
+		case *rsa.PrivateKey:
+			return novaChave(p, &p.PublicKey)
+		case ed25519.PrivateKey:
+			return novaChave(p, p.Public())
+		}
+		return Chave{}, errChaveNaoSuportada
+	case "RSA PRIVATE KEY":
+		privada, erro := x509.ParsePKCS1PrivateKey(bloco.Bytes)
+		if erro != nil {
+			return Chave{}, erro
+		}
+		return novaChave(privada, &privada.PublicKey)
+	case "PUBLIC KEY":
+		publica, erro := x509.ParsePKIXPublicKey(bloco.Bytes)
+		if erro != nil {
+			return Chave{}, erro
+		}
+		return novaChave(nil, publica)
+	}
+
+	return Chave{}, fmt.Errorf("tipo de bloco PEM não suportado: %s", bloco.Type)
+}
+
+func novaChave(privada, publica interface{}) (Chave, error) {
+	chave := Chave{privada: privada, publica: publica}
+
+	switch p := publica.(type) {
+	case *rsa.PublicKey:
+		if p.N.BitLen() < TamanhoMinimoChaveRSA {
+			return Chave{}, fmt.Errorf("a chave RSA deve ter pelo menos %d bits", TamanhoMinimoChaveRSA)
+		}
+		chave.metodo = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		chave.metodo = SigningMethodEdDSA
+	default:
+		return Chave{}, errChaveNaoSuportada
+	}
+
+	soma := sha256.Sum256([]byte(chave.membrosThumbprint()))
+	chave.ID = base64.RawURLEncoding.EncodeToString(soma[:])
+
+	return chave, nil
+}
+
+// PodeAssinar informa se a chave tem a parte privada.
+func (c Chave) PodeAssinar() bool {
+	return c.privada != nil
+}
+
+// JWK é uma chave pública no formato da RFC 7517.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS é o conjunto de chaves publicado em /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// jwk retorna a parte pública da chave. As chaves HMAC não têm parte pública.
+func (c Chave) jwk() (JWK, bool) {
+	jwk := JWK{Kid: c.ID, Use: "sig", Alg: c.metodo.Alg()}
+
+	switch p := c.publica.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(p.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(p)
+	default:
+		return JWK{}, false
+	}
+
+	return jwk, true
+}
+
+// membrosThumbprint monta o JSON dos membros obrigatórios da chave, em ordem
+// alfabética e sem espaços, como pede a RFC 7638.
+func (c Chave) membrosThumbprint() string {
+	jwk, _ := c.jwk()
+	if jwk.Kty == "RSA" {
+		return fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, jwk.E, jwk.N)
+	}
+
+	return fmt.Sprintf(`{"crv":%q,"kty":"OKP","x":%q}`, jwk.Crv, jwk.X)
+}
+
+// Chaveiro guarda a chave que assina os tokens e as chaves que ainda são
+// aceitas na verificação. Na rotação, a chave anterior continua no chaveiro
+// só para verificar, até que os tokens assinados com ela expirem, e a próxima
+// chave pode ser publicada no JWKS antes de começar a assinar.
+type Chaveiro struct {
+	assinatura  Chave
+	verificacao map[string]Chave
+	ordem       []string
+}
+
+// NovoChaveiro cria o chaveiro. As chaves de verificação nunca são usadas para
+// assinar, mesmo quando lidas de um PEM privado.
+func NovoChaveiro(assinatura Chave, verificacao ...Chave) (*Chaveiro, error) {
+	if !assinatura.PodeAssinar() {
+		return nil, errChaveSemAssinatura
+	}
+
+	chaveiro := &Chaveiro{assinatura: assinatura, verificacao: make(map[string]Chave)}
+	for _, chave := range append([]Chave{assinatura}, verificacao...) {
+		if _, existe := chaveiro.verificacao[chave.ID]; existe {
+			return nil, fmt.Errorf("chave repetida no chaveiro: kid %q", chave.ID)
+		}
+
+		chaveiro.verificacao[chave.ID] = chave
+		chaveiro.ordem = append(chaveiro.ordem, chave.ID)
+	}
+
+	return chaveiro, nil
+}
+
+// Buscar retorna a chave de verificação com o kid informado. Os tokens sem kid
+// só são aceitos pela chave HMAC.
+func (c *Chaveiro) Buscar(kid string) (Chave, bool) {
+	chave, ok := c.verificacao[kid]
+	return chave, ok
+}
+
+// JWKS retorna as chaves públicas do chaveiro, a de assinatura primeiro.
+func (c *Chaveiro) JWKS() JWKS {
+	conjunto := JWKS{Keys: []JWK{}}
+	for _, kid := range c.ordem {
+		if jwk, ok := c.verificacao[kid].jwk(); ok {
+			conjunto.Keys = append(conjunto.Keys, jwk)
+		}
+	}
+
+	return conjunto
+}
@@ -0,0 +1,295 @@
+package autenticacao
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	emissorDeTeste   = "social-network-api"
+	audienciaDeTeste = "social-network"
+)
+
+func pemPrivado(t *testing.T, privada interface{}) []byte {
+	bytes, err := x509.MarshalPKCS8PrivateKey(privada)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: bytes})
+}
+
+func pemPublico(t *testing.T, publica interface{}) []byte {
+	bytes, err := x509.MarshalPKIXPublicKey(publica)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: bytes})
+}
+
+func chaveEd25519(t *testing.T) Chave {
+	_, privada, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	chave, err := LerChavePEM(pemPrivado(t, privada))
+	require.NoError(t, err)
+	return chave
+}
+
+func emissorCom(t *testing.T, assinatura Chave, verificacao ...Chave) *Emissor {
+	chaveiro, err := NovoChaveiro(assinatura, verificacao...)
+	require.NoError(t, err)
+	return NovoEmissor(chaveiro, emissorDeTeste, audienciaDeTeste)
+}
+
+func requisicaoCom(token string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/publicacoes", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r
+}
+
+func TestCriarToken_WhenSignedWithRSAKey_ExpectedRS256WithKidAndStandardClaims(t *testing.T) {
+	privada, err := rsa.GenerateKey(rand.Reader, TamanhoMinimoChaveRSA)
+	require.NoError(t, err)
+	chave, err := LerChavePEM(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privada)}))
+	require.NoError(t, err)
+	emissor := emissorCom(t, chave)
+
+	tokenString, err := emissor.CriarToken(7, 2)
+	require.NoError(t, err)
+
+	var claims permissoes
+	token, _, err := new(jwt.Parser).ParseUnverified(tokenString, &claims)
+	require.NoError(t, err)
+	assert.Equal(t, "RS256", token.Header["alg"])
+	assert.Equal(t, chave.ID, token.Header["kid"])
+	assert.Equal(t, emissorDeTeste, claims.Issuer)
+	assert.Equal(t, audienciaDeTeste, claims.Audience)
+	assert.NotZero(t, claims.IssuedAt)
+	assert.NotEmpty(t, claims.Id)
+
+	principal, err := emissor.ValidarToken(requisicaoCom(tokenString))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), principal.UsuarioID)
+	assert.Equal(t, claims.Id, principal.TokenID)
+}
+
+func TestValidarToken_WhenSigningKeyRotated_ExpectedOldTokensStillAccepted(t *testing.T) {
+	anterior := chaveEd25519(t)
+	tokenAnterior, err := emissorCom(t, anterior).CriarToken(1, 0)
+	require.NoError(t, err)
+
+	atual := chaveEd25519(t)
+	emissor := emissorCom(t, atual, anterior)
+
+	_, err = emissor.ValidarToken(requisicaoCom(tokenAnterior))
+	assert.NoError(t, err)
+
+	tokenAtual, err := emissor.CriarToken(1, 0)
+	require.NoError(t, err)
+	token, _, err := new(jwt.Parser).ParseUnverified(tokenAtual, &permissoes{})
+	require.NoError(t, err)
+	assert.Equal(t, "EdDSA", token.Header["alg"])
+	assert.Equal(t, atual.ID, token.Header["kid"])
+
+	_, err = emissorCom(t, atual).ValidarToken(requisicaoCom(tokenAnterior))
+	assert.Error(t, err, "a chave removida do chaveiro não pode mais verificar")
+}
+
+// verificarComoOutroServico faz a verificação de um serviço que só conhece o
+// JWKS: assinatura, audiência e typ do token de acesso, sem olhar os escopos.
+func verificarComoOutroServico(chave Chave, tokenString string) error {
+	token, erro := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return chave.publica, nil
+	})
+	if erro != nil {
+		return erro
+	}
+
+	claims := token.Claims.(jwt.MapClaims)
+	if !claims.VerifyAudience(audienciaDeTeste, true) || token.Header["typ"] != TipoTokenAcesso {
+		return errTokenInvalido
+	}
+
+	return nil
+}
+
+func TestVerificacaoExterna_WhenTokenIsNotAccessToken_ExpectedRejectedByAudienceAndType(t *testing.T) {
+	chave := chaveEd25519(t)
+	emissor := emissorCom(t, chave)
+
+	tokenAcesso, err := emissor.CriarToken(1, 0)
+	require.NoError(t, err)
+	desafio, err := emissor.CriarDesafioDoisFatores(1, 0)
+	require.NoError(t, err)
+	verificacaoEmail, err := emissor.CriarTokenVerificacaoEmail(1, "usuario@teste.com")
+	require.NoError(t, err)
+
+	assert.NoError(t, verificarComoOutroServico(chave, tokenAcesso))
+	assert.Error(t, verificarComoOutroServico(chave, desafio))
+	assert.Error(t, verificarComoOutroServico(chave, verificacaoEmail))
+
+	var claims jwt.MapClaims
+	_, _, err = new(jwt.Parser).ParseUnverified(desafio, &claims)
+	require.NoError(t, err)
+	assert.NotContains(t, claims, "authorized")
+
+	_, err = emissor.ValidarToken(requisicaoCom(desafio))
+	assert.Error(t, err)
+}
+
+func TestValidarToken_WhenAccessTokenWithoutType_ExpectedRejected(t *testing.T) {
+	chave := chaveEd25519(t)
+	emissor := emissorCom(t, chave)
+
+	token := jwt.NewWithClaims(SigningMethodEdDSA, permissoes{
+		StandardClaims: jwt.StandardClaims{
+			Id:        "abc",
+			Issuer:    emissorDeTeste,
+			Audience:  audienciaDeTeste,
+			IssuedAt:  time.Now().Unix(),
+			ExpiresAt: time.Now().Add(time.Minute).Unix(),
+		},
+		Authorized: true,
+		UsuarioID:  1,
+		Escopos:    []string{EscopoUsuario},
+	})
+	token.Header["kid"] = chave.ID
+	tokenString, err := token.SignedString(chave.privada)
+	require.NoError(t, err)
+
+	_, err = emissor.ValidarToken(requisicaoCom(tokenString))
+	assert.Error(t, err)
+}
+
+func TestValidarToken_WhenIssuerOrAudienceDiffers_ExpectedRejected(t *testing.T) {
+	chave := chaveEd25519(t)
+	chaveiro, err := NovoChaveiro(chave)
+	require.NoError(t, err)
+
+	tokenDeOutroEmissor, err := NovoEmissor(chaveiro, "outra-api", audienciaDeTeste).CriarToken(1, 0)
+	require.NoError(t, err)
+	tokenDeOutraAudiencia, err := NovoEmissor(chaveiro, emissorDeTeste, "outro-servico").CriarToken(1, 0)
+	require.NoError(t, err)
+
+	emissor := NovoEmissor(chaveiro, emissorDeTeste, audienciaDeTeste)
+	_, err = emissor.ValidarToken(requisicaoCom(tokenDeOutroEmissor))
+	assert.Error(t, err)
+	_, err = emissor.ValidarToken(requisicaoCom(tokenDeOutraAudiencia))
+	assert.Error(t, err)
+}
+
+func TestValidarToken_WhenStandardClaimsMissing_ExpectedRejected(t *testing.T) {
+	segredo := []byte("chave-secreta-usada-apenas-nos-testes")
+	emissor := emissorCom(t, NovaChaveHMAC(segredo))
+
+	// Token no formato anterior, sem iss, aud e iat.
+	tokenAntigo, err := jwt.NewWithClaims(jwt.SigningMethodHS256, permissoes{
+		StandardClaims: jwt.StandardClaims{Id: "abc", ExpiresAt: time.Now().Add(time.Minute).Unix()},
+		Authorized:     true,
+		UsuarioID:      1,
+		Escopos:        []string{EscopoUsuario},
+	}).SignedString(segredo)
+	require.NoError(t, err)
+
+	_, err = emissor.ValidarToken(requisicaoCom(tokenAntigo))
+	assert.Error(t, err)
+}
+
+func TestValidarToken_WhenAlgorithmDoesNotMatchKey_ExpectedRejected(t *testing.T) {
+	chave := chaveEd25519(t)
+	emissor := emissorCom(t, chave)
+
+	// HS256 com a chave pública como segredo, usando o kid da chave Ed25519.
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, permissoes{
+		StandardClaims: jwt.StandardClaims{
+			Id:        "abc",
+			Issuer:    emissorDeTeste,
+			Audience:  audienciaDeTeste,
+			IssuedAt:  time.Now().Unix(),
+			ExpiresAt: time.Now().Add(time.Minute).Unix(),
+		},
+		Authorized: true,
+		UsuarioID:  1,
+		Escopos:    []string{EscopoUsuario},
+	})
+	token.Header["kid"] = chave.ID
+	tokenString, err := token.SignedString([]byte(chave.publica.(ed25519.PublicKey)))
+	require.NoError(t, err)
+
+	_, err = emissor.ValidarToken(requisicaoCom(tokenString))
+	assert.Error(t, err)
+}
+
+func TestLerChavePEM_WhenPublicKey_ExpectedVerificationOnly(t *testing.T) {
+	publica, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	chave, err := LerChavePEM(pemPublico(t, publica))
+
+	assert.NoError(t, err)
+	assert.False(t, chave.PodeAssinar())
+	_, err = NovoChaveiro(chave)
+	assert.Error(t, err)
+}
+
+func TestLerChavePEM_WhenRSAKeyTooSmall_ExpectedError(t *testing.T) {
+	privada, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	_, err = LerChavePEM(pemPrivado(t, privada))
+
+	assert.ErrorContains(t, err, "2048")
+}
+
+func TestNovoChaveiro_WhenSameKeyTwice_ExpectedError(t *testing.T) {
+	chave := chaveEd25519(t)
+
+	_, err := NovoChaveiro(chave, chave)
+
+	assert.Error(t, err)
+}
+
+func TestJWKS_WhenRotating_ExpectedPublicKeysOnlyWithSigningKeyFirst(t *testing.T) {
+	atual := chaveEd25519(t)
+	anterior := chaveEd25519(t)
+	chaveiro, err := NovoChaveiro(atual, anterior, NovaChaveHMAC([]byte("chave-secreta-usada-apenas-nos-testes")))
+	require.NoError(t, err)
+
+	conjunto := chaveiro.JWKS()
+
+	require.Len(t, conjunto.Keys, 2)
+	assert.Equal(t, atual.ID, conjunto.Keys[0].Kid)
+	assert.Equal(t, anterior.ID, conjunto.Keys[1].Kid)
+	assert.Equal(t, JWK{
+		Kty: "OKP",
+		Kid: atual.ID,
+		Use: "sig",
+		Alg: "EdDSA",
+		Crv: "Ed25519",
+		X:   conjunto.Keys[0].X,
+	}, conjunto.Keys[0])
+	assert.NotEmpty(t, conjunto.Keys[0].X)
+}
+
+func TestLerChavePEM_WhenRFC7638Example_ExpectedThumbprintAsKid(t *testing.T) {
+	// Chave e thumbprint do exemplo da seção 3.1 da RFC 7638.
+	n := "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw"
+	modulo, err := jwt.DecodeSegment(n)
+	require.NoError(t, err)
+
+	chave, err := LerChavePEM(pemPublico(t, &rsa.PublicKey{N: new(big.Int).SetBytes(modulo), E: 65537}))
+
+	require.NoError(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", chave.ID)
+	jwk, _ := chave.jwk()
+	assert.Equal(t, n, jwk.N)
+	assert.Equal(t, "AQAB", jwk.E)
+}
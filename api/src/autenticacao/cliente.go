@@ -0,0 +1,17 @@
+package autenticacao
+
+import (
+	"net"
+	"net/http"
+)
+
+// IPDoCliente retorna o IP da conexão. O X-Forwarded-For não é usado, pois o
+// cliente pode escolher o valor.
+func IPDoCliente(r *http.Request) string {
+	ip, _, erro := net.SplitHostPort(r.RemoteAddr)
+	if erro != nil {
+		return r.RemoteAddr
+	}
+
+	return ip
+}
@@ -0,0 +1,50 @@
+package autenticacao
+
+import (
+	"crypto/ed25519"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+// SigningMethodEdDSA assina os tokens com Ed25519 (alg EdDSA, RFC 8037), que
+// a versão do jwt-go usada pela API não implementa.
+var SigningMethodEdDSA = &metodoEdDSA{}
+
+type metodoEdDSA struct{}
+
+func init() {
+	jwt.RegisterSigningMethod(SigningMethodEdDSA.Alg(), func() jwt.SigningMethod {
+		return SigningMethodEdDSA
+	})
+}
+
+func (m *metodoEdDSA) Alg() string {
+	return "EdDSA"
+}
+
+func (m *metodoEdDSA) Sign(conteudo string, chave interface{}) (string, error) {
+	privada, ok := chave.(ed25519.PrivateKey)
+	if !ok || len(privada) != ed25519.PrivateKeySize {
+		return "", jwt.ErrInvalidKeyType
+	}
+
+	return jwt.EncodeSegment(ed25519.Sign(privada, []byte(conteudo))), nil
+}
+
+func (m *metodoEdDSA) Verify(conteudo, assinatura string, chave interface{}) error {
+	publica, ok := chave.(ed25519.PublicKey)
+	if !ok || len(publica) != ed25519.PublicKeySize {
+		return jwt.ErrInvalidKeyType
+	}
+
+	bytesAssinatura, erro := jwt.DecodeSegment(assinatura)
+	if erro != nil {
+		return erro
+	}
+
+	if !ed25519.Verify(publica, []byte(conteudo), bytesAssinatura) {
+		return jwt.ErrSignatureInvalid
+	}
+
+	return nil
+}
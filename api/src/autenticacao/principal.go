@@ -0,0 +1,65 @@
+package autenticacao
+
+import (
+	"api/src/erros"
+	"context"
+	"net/http"
+	"time"
+)
+
+// Principal é o usuário autenticado de uma requisição, extraído do token uma
+// única vez pelo middleware de autenticação.
+type Principal struct {
+	UsuarioID   uint64
+	TokenID     string
+	Escopos     []string
+	ExpiraEm    time.Time
+	VersaoToken uint64
+}
+
+type chavePrincipal struct{}
+
+var ErrNaoAutenticado = erros.Novo("autenticacao.nao_autenticado", "Usuário não autenticado")
+
+// PossuiEscopo informa se o principal recebeu o escopo informado.
+func (principal Principal) PossuiEscopo(escopo string) bool {
+	for _, e := range principal.Escopos {
+		if e == escopo {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ComPrincipal retorna um contexto que carrega o principal. Também é usado
+// pelos testes para simular um usuário autenticado sem gerar um JWT.
+func ComPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, chavePrincipal{}, principal)
+}
+
+// PrincipalDoContexto retorna o principal guardado no contexto, se houver.
+func PrincipalDoContexto(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(chavePrincipal{}).(Principal)
+	return principal, ok
+}
+
+// ExtrairPrincipal retorna o principal da requisição ou ErrNaoAutenticado.
+func ExtrairPrincipal(r *http.Request) (Principal, error) {
+	principal, ok := PrincipalDoContexto(r.Context())
+	if !ok {
+		return Principal{}, ErrNaoAutenticado
+	}
+
+	return principal, nil
+}
+
+// ExtrairUsuarioID retorna o ID do usuário autenticado da requisição.
+func ExtrairUsuarioID(r *http.Request) (uint64, error) {
+	principal, erro := ExtrairPrincipal(r)
+	if erro != nil {
+		return 0, erro
+	}
+
+	return principal.UsuarioID, nil
+}
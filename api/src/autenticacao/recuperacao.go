@@ -0,0 +1,43 @@
+package autenticacao
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+)
+
+// QuantidadeCodigosRecuperacao é o número de códigos gerados ao ativar a
+// autenticação em dois fatores. Cada código vale para um único login.
+const QuantidadeCodigosRecuperacao = 10
+
+var codificacaoCodigos = base32.NewEncoding("abcdefghijkmnpqrstuvwxyz23456789").WithPadding(base32.NoPadding)
+
+// GerarCodigosRecuperacao gera os códigos de recuperação, no formato
+// xxxx-xxxx-xxxx, e os hashes que devem ser persistidos no lugar deles.
+func GerarCodigosRecuperacao() ([]string, []string, error) {
+	codigos := make([]string, QuantidadeCodigosRecuperacao)
+	hashes := make([]string, QuantidadeCodigosRecuperacao)
+
+	for i := range codigos {
+		bytes := make([]byte, 8)
+		if _, erro := rand.Read(bytes); erro != nil {
+			return nil, nil, erro
+		}
+
+		texto := codificacaoCodigos.EncodeToString(bytes)[:12]
+		codigos[i] = texto[0:4] + "-" + texto[4:8] + "-" + texto[8:12]
+		hashes[i] = HashCodigoRecuperacao(codigos[i])
+	}
+
+	return codigos, hashes, nil
+}
+
+// HashCodigoRecuperacao ignora maiúsculas, espaços e hífens, para que o código
+// possa ser digitado de qualquer forma.
+func HashCodigoRecuperacao(codigo string) string {
+	normalizado := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(codigo))
+	hash := sha256.Sum256([]byte(normalizado))
+	return hex.EncodeToString(hash[:])
+}
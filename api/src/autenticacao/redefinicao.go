@@ -0,0 +1,11 @@
+package autenticacao
+
+// CriarTokenRedefinicaoSenha gera o token enviado no link de redefinição de
+// senha e o hash que deve ser persistido no lugar dele.
+func CriarTokenRedefinicaoSenha() (string, string, error) {
+	return criarTokenOpaco()
+}
+
+func HashTokenRedefinicaoSenha(token string) string {
+	return hashTokenOpaco(token)
+}
@@ -1,38 +1,194 @@
 package autenticacao
 
 import (
-	"api/src/config"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/http"
-	"strconv"
+	"slices"
 	"strings"
 	"time"
 
 	jwt "github.com/dgrijalva/jwt-go"
 )
 
-func CriarToken(usuarioID uint64) (string, error) {
-	permissoes := jwt.MapClaims{}
-	permissoes["authorized"] = true
-	permissoes["exp"] = time.Now().Add(time.Hour * 6).Unix()
-	permissoes["usuarioId"] = usuarioID
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, permissoes)
-	return token.SignedString([]byte(config.SecretKey))
+const (
+	DuracaoTokenAcesso      = 15 * time.Minute
+	DuracaoTokenAtualizacao = 30 * 24 * time.Hour
+
+	// DuracaoDesafioDoisFatores é o tempo que o usuário tem para informar o
+	// código do autenticador depois de acertar a senha.
+	DuracaoDesafioDoisFatores = 5 * time.Minute
+	// DuracaoVerificacaoEmail é a validade do link de confirmação de email.
+	DuracaoVerificacaoEmail = 48 * time.Hour
+
+	// EscopoUsuario é concedido aos tokens de acesso emitidos no login.
+	EscopoUsuario = "usuario"
+	// EscopoDoisFatoresPendente é o único escopo do token de desafio, que só
+	// pode ser trocado pelos tokens de acesso em POST /login/2fa.
+	EscopoDoisFatoresPendente = "2fa_pendente"
+	// EscopoVerificacaoEmail é o escopo do token enviado no link de
+	// confirmação de email, que não dá acesso a nenhuma outra rota.
+	EscopoVerificacaoEmail = "verificar_email"
+
+	// TipoTokenAcesso vai no cabeçalho typ apenas dos tokens de acesso (RFC
+	// 9068). Os demais tokens levam o typ padrão, JWT.
+	TipoTokenAcesso = "at+jwt"
+)
+
+var errTokenInvalido = errors.New("Token Inválido")
+
+type permissoes struct {
+	jwt.StandardClaims
+	Authorized  bool     `json:"authorized,omitempty"`
+	UsuarioID   uint64   `json:"usuarioId"`
+	VersaoToken uint64   `json:"versaoToken"`
+	Escopos     []string `json:"escopos,omitempty"`
+	Email       string   `json:"email,omitempty"`
+}
+
+// Emissor assina os tokens com a chave de assinatura do chaveiro e os valida
+// com qualquer chave de verificação. Os tokens levam o emissor (iss) e a
+// audiência (aud) da API, conferidos na validação. Apenas os tokens de acesso
+// têm a audiência configurada e o typ at+jwt, para que os serviços que
+// verificam os tokens pelo JWKS não aceitem o desafio do login em dois fatores
+// ou o link de confirmação de email como acesso.
+type Emissor struct {
+	chaves    *Chaveiro
+	emissor   string
+	audiencia string
+}
+
+func NovoEmissor(chaves *Chaveiro, emissor, audiencia string) *Emissor {
+	return &Emissor{chaves: chaves, emissor: emissor, audiencia: audiencia}
+}
+
+// ChavesPublicas retorna as chaves que outros serviços usam para verificar os
+// tokens da API.
+func (e *Emissor) ChavesPublicas() JWKS {
+	return e.chaves.JWKS()
+}
+
+func (e *Emissor) CriarToken(usuarioID, versaoToken uint64) (string, error) {
+	return e.assinar(permissoes{UsuarioID: usuarioID, VersaoToken: versaoToken}, EscopoUsuario, DuracaoTokenAcesso)
+}
+
+// CriarDesafioDoisFatores emite o token de curta duração entregue no login de
+// quem tem a autenticação em dois fatores ativa.
+func (e *Emissor) CriarDesafioDoisFatores(usuarioID, versaoToken uint64) (string, error) {
+	return e.assinar(permissoes{UsuarioID: usuarioID, VersaoToken: versaoToken}, EscopoDoisFatoresPendente, DuracaoDesafioDoisFatores)
+}
+
+// CriarTokenVerificacaoEmail assina o token do link de confirmação de email.
+// O email vai no token para que o link deixe de valer se o email for trocado.
+func (e *Emissor) CriarTokenVerificacaoEmail(usuarioID uint64, email string) (string, error) {
+	return e.assinar(permissoes{UsuarioID: usuarioID, Email: email}, EscopoVerificacaoEmail, DuracaoVerificacaoEmail)
+}
+
+// ValidarToken verifica o token de acesso enviado no cabeçalho Authorization
+// e retorna o principal que ele representa.
+func (e *Emissor) ValidarToken(r *http.Request) (Principal, error) {
+	return e.validar(extrairToken(r), EscopoUsuario)
+}
+
+// ValidarDesafioDoisFatores verifica o token de desafio do login em dois fatores.
+func (e *Emissor) ValidarDesafioDoisFatores(tokenString string) (Principal, error) {
+	return e.validar(tokenString, EscopoDoisFatoresPendente)
+}
+
+// ValidarTokenVerificacaoEmail verifica o token do link de confirmação e
+// retorna o usuário e o email que ele confirma.
+func (e *Emissor) ValidarTokenVerificacaoEmail(tokenString string) (uint64, string, error) {
+	claims, erro := e.lerClaims(tokenString, EscopoVerificacaoEmail)
+	if erro != nil {
+		return 0, "", erro
+	}
+	if claims.Email == "" {
+		return 0, "", errTokenInvalido
+	}
+
+	return claims.UsuarioID, claims.Email, nil
+}
+
+func (e *Emissor) assinar(claims permissoes, escopo string, duracao time.Duration) (string, error) {
+	tokenID, erro := gerarTokenID()
+	if erro != nil {
+		return "", erro
+	}
+
+	agora := time.Now()
+	claims.StandardClaims = jwt.StandardClaims{
+		Id:        tokenID,
+		Issuer:    e.emissor,
+		Audience:  e.audienciaDoEscopo(escopo),
+		IssuedAt:  agora.Unix(),
+		ExpiresAt: agora.Add(duracao).Unix(),
+	}
+	claims.Authorized = escopo == EscopoUsuario
+	claims.Escopos = []string{escopo}
+
+	chave := e.chaves.assinatura
+	token := jwt.NewWithClaims(chave.metodo, claims)
+	if escopo == EscopoUsuario {
+		token.Header["typ"] = TipoTokenAcesso
+	}
+	if chave.ID != "" {
+		token.Header["kid"] = chave.ID
+	}
+
+	return token.SignedString(chave.privada)
 }
 
-func ValidarToken(r *http.Request) error {
-	tokenString := extrairToken(r)
-	token, erro := jwt.Parse(tokenString, retornarChaveVerificacao)
+// validar confere o token com lerClaims e retorna o principal que ele representa.
+func (e *Emissor) validar(tokenString, escopo string) (Principal, error) {
+	claims, erro := e.lerClaims(tokenString, escopo)
 	if erro != nil {
-		return erro
+		return Principal{}, erro
 	}
 
-	if _, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		return nil
+	return Principal{
+		UsuarioID:   claims.UsuarioID,
+		TokenID:     claims.Id,
+		Escopos:     claims.Escopos,
+		ExpiraEm:    time.Unix(claims.ExpiresAt, 0),
+		VersaoToken: claims.VersaoToken,
+	}, nil
+}
+
+// lerClaims confere a assinatura, a validade, o emissor, a audiência e o
+// escopo do token. Um token emitido para um uso, como o desafio do login em
+// dois fatores, não é aceito em outro.
+func (e *Emissor) lerClaims(tokenString, escopo string) (permissoes, error) {
+	var claims permissoes
+	token, erro := jwt.ParseWithClaims(tokenString, &claims, e.retornarChaveVerificacao)
+	if erro != nil {
+		return permissoes{}, erro
 	}
 
-	return errors.New("Token Inválido")
+	if !token.Valid || claims.UsuarioID == 0 || !slices.Contains(claims.Escopos, escopo) {
+		return permissoes{}, errTokenInvalido
+	}
+
+	if !claims.VerifyIssuer(e.emissor, true) || !claims.VerifyAudience(e.audienciaDoEscopo(escopo), true) || claims.IssuedAt == 0 || claims.Id == "" {
+		return permissoes{}, errTokenInvalido
+	}
+
+	if tipo, _ := token.Header["typ"].(string); (tipo == TipoTokenAcesso) != (escopo == EscopoUsuario) {
+		return permissoes{}, errTokenInvalido
+	}
+
+	return claims, nil
+}
+
+// audienciaDoEscopo retorna a audiência configurada para os tokens de acesso.
+// Os tokens de uso interno são endereçados à própria API, com o escopo.
+func (e *Emissor) audienciaDoEscopo(escopo string) string {
+	if escopo == EscopoUsuario {
+		return e.audiencia
+	}
+
+	return e.emissor + "/" + escopo
 }
 
 func extrairToken(r *http.Request) string {
@@ -45,29 +201,29 @@ func extrairToken(r *http.Request) string {
 	return ""
 }
 
-func retornarChaveVerificacao(token *jwt.Token) (interface{}, error) {
-	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-		return nil, fmt.Errorf("Método de assinatura inesperado! %v", token.Header["alg"])
-	}
+// retornarChaveVerificacao escolhe a chave pelo kid do token. O algoritmo do
+// cabeçalho precisa ser o da chave, para que uma chave pública não seja usada
+// como segredo HMAC.
+func (e *Emissor) retornarChaveVerificacao(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
 
-	return config.SecretKey, nil
-}
+	chave, ok := e.chaves.Buscar(kid)
+	if !ok {
+		return nil, fmt.Errorf("Chave de verificação desconhecida! %q", kid)
+	}
 
-func ExtrairUsuarioID(r *http.Request) (uint64, error) {
-	tokenString := extrairToken(r)
-	token, erro := jwt.Parse(tokenString, retornarChaveVerificacao)
-	if erro != nil {
-		return 0, erro
+	if token.Method.Alg() != chave.metodo.Alg() {
+		return nil, fmt.Errorf("Método de assinatura inesperado! %v", token.Header["alg"])
 	}
 
-	if permissoes, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		usuarioID, erro := strconv.ParseUint(fmt.Sprintf("%.0f", permissoes["usuarioId"]), 10, 64)
-		if erro != nil {
-			return 0, erro
-		}
+	return chave.publica, nil
+}
 
-		return usuarioID, nil
+func gerarTokenID() (string, error) {
+	bytes := make([]byte, 16)
+	if _, erro := rand.Read(bytes); erro != nil {
+		return "", erro
 	}
 
-	return 0, errors.New("Token Inválido")
+	return hex.EncodeToString(bytes), nil
 }
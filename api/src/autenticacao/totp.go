@@ -0,0 +1,111 @@
+package autenticacao
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Parâmetros do TOTP (RFC 6238) aceitos por todos os aplicativos
+// autenticadores: HMAC-SHA1, códigos de 6 dígitos e passos de 30 segundos.
+const (
+	EmissorTOTP        = "Social Network"
+	digitosTOTP        = 6
+	passoTOTP          = 30 * time.Second
+	tamanhoSegredoTOTP = 20
+
+	// toleranciaTOTP aceita o código do passo anterior e do seguinte, para
+	// compensar a diferença entre o relógio do celular e o do servidor.
+	toleranciaTOTP = 1
+)
+
+var codificacaoBase32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GerarSegredoTOTP gera o segredo compartilhado com o aplicativo autenticador,
+// codificado em base32.
+func GerarSegredoTOTP() (string, error) {
+	bytes := make([]byte, tamanhoSegredoTOTP)
+	if _, erro := rand.Read(bytes); erro != nil {
+		return "", erro
+	}
+
+	return codificacaoBase32.EncodeToString(bytes), nil
+}
+
+// URIProvisionamentoTOTP monta a URI otpauth:// que os aplicativos
+// autenticadores leem a partir de um QR code.
+func URIProvisionamentoTOTP(segredo, conta string) string {
+	parametros := url.Values{}
+	parametros.Set("secret", segredo)
+	parametros.Set("issuer", EmissorTOTP)
+	parametros.Set("algorithm", "SHA1")
+	parametros.Set("digits", fmt.Sprint(digitosTOTP))
+	parametros.Set("period", fmt.Sprint(int(passoTOTP.Seconds())))
+
+	uri := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + EmissorTOTP + ":" + conta,
+		RawQuery: parametros.Encode(),
+	}
+
+	return uri.String()
+}
+
+// ValidarCodigoTOTP confere o código no momento informado e retorna o passo
+// em que ele foi gerado. O passo deve ser guardado para que o mesmo código não
+// seja aceito duas vezes.
+func ValidarCodigoTOTP(segredo, codigo string, agora time.Time) (int64, bool) {
+	chave, erro := codificacaoBase32.DecodeString(strings.ToUpper(segredo))
+	if erro != nil || len(codigo) != digitosTOTP {
+		return 0, false
+	}
+
+	passoAtual := agora.Unix() / int64(passoTOTP.Seconds())
+	for desvio := int64(-toleranciaTOTP); desvio <= toleranciaTOTP; desvio++ {
+		passo := passoAtual + desvio
+		if subtle.ConstantTimeCompare([]byte(gerarCodigoTOTP(chave, passo)), []byte(codigo)) == 1 {
+			return passo, true
+		}
+	}
+
+	return 0, false
+}
+
+// GerarCodigoTOTP calcula o código que um autenticador mostraria no momento
+// informado.
+func GerarCodigoTOTP(segredo string, agora time.Time) (string, error) {
+	chave, erro := codificacaoBase32.DecodeString(strings.ToUpper(segredo))
+	if erro != nil {
+		return "", erro
+	}
+
+	return gerarCodigoTOTP(chave, agora.Unix()/int64(passoTOTP.Seconds())), nil
+}
+
+// gerarCodigoTOTP aplica o HOTP (RFC 4226) ao passo de tempo.
+func gerarCodigoTOTP(chave []byte, passo int64) string {
+	contador := make([]byte, 8)
+	binary.BigEndian.PutUint64(contador, uint64(passo))
+
+	mac := hmac.New(sha1.New, chave)
+	mac.Write(contador)
+	soma := mac.Sum(nil)
+
+	deslocamento := soma[len(soma)-1] & 0x0f
+	valor := binary.BigEndian.Uint32(soma[deslocamento:deslocamento+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < digitosTOTP; i++ {
+		modulo *= 10
+	}
+
+	return fmt.Sprintf("%0*d", digitosTOTP, valor%modulo)
+}
@@ -0,0 +1,64 @@
+package autenticacao
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// segredoRFC é o segredo SHA1 dos vetores de teste da RFC 6238.
+var segredoRFC = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestValidarCodigoTOTP_WhenRFCTestVectors_ExpectedCodesAccepted(t *testing.T) {
+	// Os vetores da RFC têm 8 dígitos; os 6 últimos são o código de 6 dígitos.
+	vetores := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for instante, codigo := range vetores {
+		passo, ok := ValidarCodigoTOTP(segredoRFC, codigo, time.Unix(instante, 0))
+		assert.True(t, ok, "instante %d", instante)
+		assert.Equal(t, instante/30, passo)
+	}
+}
+
+func TestValidarCodigoTOTP_WhenCodeIsFromAdjacentOrDistantStep_ExpectedOnlyAdjacentAccepted(t *testing.T) {
+	_, anterior := ValidarCodigoTOTP(segredoRFC, "287082", time.Unix(59+30, 0))
+	_, distante := ValidarCodigoTOTP(segredoRFC, "287082", time.Unix(59+90, 0))
+	_, errado := ValidarCodigoTOTP(segredoRFC, "000000", time.Unix(59, 0))
+
+	assert.True(t, anterior)
+	assert.False(t, distante)
+	assert.False(t, errado)
+}
+
+func TestURIProvisionamentoTOTP_WhenBuilt_ExpectedOtpauthURIWithSecretAndIssuer(t *testing.T) {
+	segredo, err := GerarSegredoTOTP()
+	assert.NoError(t, err)
+
+	uri, err := url.Parse(URIProvisionamentoTOTP(segredo, "usuario@teste.com"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Social Network:usuario@teste.com", uri.Path)
+	assert.Equal(t, segredo, uri.Query().Get("secret"))
+	assert.Equal(t, EmissorTOTP, uri.Query().Get("issuer"))
+}
+
+func TestHashCodigoRecuperacao_WhenTypedDifferently_ExpectedSameHash(t *testing.T) {
+	codigos, hashes, err := GerarCodigosRecuperacao()
+	assert.NoError(t, err)
+	assert.Len(t, codigos, QuantidadeCodigosRecuperacao)
+
+	digitado := strings.ToUpper(strings.ReplaceAll(codigos[0], "-", " "))
+	assert.Equal(t, hashes[0], HashCodigoRecuperacao(digitado))
+	assert.NotEqual(t, hashes[0], hashes[1])
+}
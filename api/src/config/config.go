@@ -1,29 +1,616 @@
+// Package config carrega a configuração da API. Os valores vêm, em ordem de
+// prioridade, das flags de linha de comando, das variáveis de ambiente, do
+// arquivo .env (opcional) e dos padrões definidos em Padrao.
 package config
 
 import (
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/mail"
+	"net/url"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
-var (
-	Porta     = 0
-	SecretKey []byte
-)
+// TamanhoMinimoChaveSecreta é o tamanho mínimo, em bytes, da chave HS256 dos
+// tokens. Chaves menores que o hash facilitam força bruta.
+const TamanhoMinimoChaveSecreta = 32
+
+const valorOculto = "[oculto]"
+
+var modosSSL = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+type Config struct {
+	Servidor            Servidor
+	Banco               Banco
+	Autenticacao        Autenticacao
+	LimiteDeRequisicoes LimiteDeRequisicoes
+	Email               Email
+	Logs                Logs
+	Rastreamento        Rastreamento
+}
+
+type Servidor struct {
+	Porta              int
+	TempoLimiteLeitura time.Duration
+	TempoLimiteEscrita time.Duration
+	TempoLimiteOcioso  time.Duration
+	// TempoEncerramento é o tempo que as requisições em andamento têm para
+	// terminar depois do sinal de parada.
+	TempoEncerramento time.Duration
+	// EsperaAntesDeEncerrar é o tempo em que a API continua atendendo, mas já
+	// responde como não pronta, antes de parar de aceitar conexões.
+	EsperaAntesDeEncerrar time.Duration
+	// TempoLimiteVerificacaoSaude é o prazo das verificações do /readyz.
+	TempoLimiteVerificacaoSaude time.Duration
+}
+
+type Banco struct {
+	Schema  string
+	Usuario string
+	Senha   string
+	Host    string
+	Porta   int
+	Nome    string
+	ModoSSL string
+
+	// TempoLimiteConsulta é o prazo máximo que as consultas ao banco de uma
+	// requisição têm para terminar.
+	TempoLimiteConsulta time.Duration
+
+	MaxConexoesAbertas int
+	MaxConexoesOciosas int
+	TempoDeVidaConexao time.Duration
+	TempoOciosoConexao time.Duration
+
+	// Migrar indica se as migrações embutidas devem ser aplicadas ao iniciar a
+	// API, e Semear se os dados iniciais devem ser inseridos.
+	Migrar bool
+	Semear bool
+}
+
+type Autenticacao struct {
+	// ChaveSecreta assina os tokens com HS256 quando ArquivoChaveAssinatura
+	// não é definido. Com o arquivo, ela só verifica os tokens já emitidos.
+	ChaveSecreta []byte
+	// ArquivoChaveAssinatura é o PEM da chave privada, RSA ou Ed25519, que
+	// assina os tokens. ArquivosChavesVerificacao são as chaves da rotação,
+	// aceitas na verificação e publicadas no JWKS, mas nunca usadas para assinar.
+	ArquivoChaveAssinatura    string
+	ArquivosChavesVerificacao []string
+	// EmissorTokens e AudienciaTokens são os claims iss e aud dos tokens.
+	EmissorTokens   string
+	AudienciaTokens string
+
+	// Proteção do login contra força bruta: cada falha aumenta a espera a
+	// partir de AtrasoInicialLogin, e a conta é bloqueada por TempoBloqueioLogin
+	// depois de MaxFalhasLogin falhas dentro de JanelaFalhasLogin.
+	MaxFalhasLogin      int
+	MaxFalhasLoginPorIP int
+	JanelaFalhasLogin   time.Duration
+	TempoBloqueioLogin  time.Duration
+	AtrasoInicialLogin  time.Duration
+	AtrasoMaximoLogin   time.Duration
+
+	// DuracaoRedefinicaoSenha é a validade do link de redefinição de senha.
+	DuracaoRedefinicaoSenha time.Duration
+}
+
+// LimiteDeRequisicoes define onde ficam os baldes do limite de requisições.
+// Armazenamento aceita memory, que vale só para a réplica, ou redis, que
+// compartilha os limites entre réplicas.
+type LimiteDeRequisicoes struct {
+	Armazenamento string
+	EnderecoRedis string
+	SenhaRedis    string
+	BancoRedis    int
+}
+
+// Email define como os emails são enviados. Enviador aceita log, que grava as
+// mensagens em Arquivo ou, sem arquivo, no log, ou smtp. Não há padrão: as
+// mensagens levam tokens de redefinição de senha, e uma implantação que
+// esquecesse o MAILER os gravaria no log.
+type Email struct {
+	Enviador    string
+	Arquivo     string
+	Remetente   string
+	HostSMTP    string
+	PortaSMTP   int
+	UsuarioSMTP string
+	SenhaSMTP   string
+
+	// URLRedefinicaoSenha é a página do cliente para onde o link de
+	// redefinição de senha leva, com o token no parâmetro token.
+	URLRedefinicaoSenha string
+	// URLVerificacaoEmail é a página do cliente para onde o link de
+	// confirmação de email leva, também com o token no parâmetro token.
+	URLVerificacaoEmail string
+}
+
+type Logs struct {
+	// Nivel aceita debug, info, warn ou error e Formato aceita json ou text.
+	Nivel   string
+	Formato string
+}
+
+type Rastreamento struct {
+	// Exportador aceita none, otlp ou stdout. O endpoint do OTLP segue as
+	// variáveis padrão do OpenTelemetry (OTEL_EXPORTER_OTLP_ENDPOINT).
+	Exportador  string
+	NomeServico string
+	Amostragem  float64
+}
+
+// Padrao retorna a configuração usada quando nenhuma variável é definida.
+func Padrao() Config {
+	return Config{
+		Servidor: Servidor{
+			Porta:                       8000,
+			TempoLimiteLeitura:          10 * time.Second,
+			TempoLimiteEscrita:          15 * time.Second,
+			TempoLimiteOcioso:           60 * time.Second,
+			TempoEncerramento:           20 * time.Second,
+			TempoLimiteVerificacaoSaude: 2 * time.Second,
+		},
+		Banco: Banco{
+			Schema:              "postgres",
+			Porta:               5432,
+			ModoSSL:             "disable",
+			TempoLimiteConsulta: 5 * time.Second,
+			MaxConexoesAbertas:  25,
+			MaxConexoesOciosas:  25,
+			TempoDeVidaConexao:  30 * time.Minute,
+			TempoOciosoConexao:  5 * time.Minute,
+		},
+		Autenticacao: Autenticacao{
+			MaxFalhasLogin:      5,
+			MaxFalhasLoginPorIP: 50,
+			JanelaFalhasLogin:   15 * time.Minute,
+			TempoBloqueioLogin:  15 * time.Minute,
+			AtrasoInicialLogin:  time.Second,
+			AtrasoMaximoLogin:   30 * time.Second,
+
+			EmissorTokens:   "social-network-api",
+			AudienciaTokens: "social-network",
+
+			DuracaoRedefinicaoSenha: time.Hour,
+		},
+		LimiteDeRequisicoes: LimiteDeRequisicoes{
+			Armazenamento: "memory",
+		},
+		Email: Email{
+			Remetente:           "Social Network <nao-responda@localhost>",
+			PortaSMTP:           587,
+			URLRedefinicaoSenha: "http://localhost:3000/redefinir-senha",
+			URLVerificacaoEmail: "http://localhost:3000/verificar-email",
+		},
+		Logs: Logs{
+			Nivel:   "info",
+			Formato: "json",
+		},
+		Rastreamento: Rastreamento{
+			Exportador:  "none",
+			NomeServico: "social-network-api",
+			Amostragem:  1,
+		},
+	}
+}
+
+// Carregar lê o arquivo .env, quando existir, as variáveis de ambiente e os
+// argumentos de linha de comando, e valida o resultado. O caminho do .env
+// pode ser trocado pela variável ENV_FILE.
+func Carregar(argumentos []string) (Config, error) {
+	arquivoEnv := os.Getenv("ENV_FILE")
+	if arquivoEnv == "" {
+		arquivoEnv = ".env"
+	}
+
+	if erro := godotenv.Load(arquivoEnv); erro != nil && !errors.Is(erro, fs.ErrNotExist) {
+		return Config{}, fmt.Errorf("ler %s: %w", arquivoEnv, erro)
+	}
 
-func Carregar() {
-	var erro error
+	configuracao, erro := DoAmbiente(os.LookupEnv)
+	if erro != nil {
+		return Config{}, erro
+	}
+
+	if erro = configuracao.lerFlags(argumentos); erro != nil {
+		return Config{}, erro
+	}
+
+	return configuracao, configuracao.Validar()
+}
+
+// DoAmbiente monta a configuração a partir das variáveis de ambiente. Uma
+// variável definida com um valor que não pode ser convertido é um erro, e não
+// um motivo para voltar ao padrão.
+func DoAmbiente(buscar func(string) (string, bool)) (Config, error) {
+	c := Padrao()
+	l := leitor{buscar: buscar}
+
+	l.inteiro("SERVER_PORT", &c.Servidor.Porta)
+	l.duracao("SERVER_READ_TIMEOUT", &c.Servidor.TempoLimiteLeitura)
+	l.duracao("SERVER_WRITE_TIMEOUT", &c.Servidor.TempoLimiteEscrita)
+	l.duracao("SERVER_IDLE_TIMEOUT", &c.Servidor.TempoLimiteOcioso)
+	l.duracao("SERVER_SHUTDOWN_TIMEOUT", &c.Servidor.TempoEncerramento)
+	l.duracao("SERVER_SHUTDOWN_DELAY", &c.Servidor.EsperaAntesDeEncerrar)
+	l.duracao("HEALTHCHECK_TIMEOUT", &c.Servidor.TempoLimiteVerificacaoSaude)
+
+	l.texto("DATABASE_SCHEMA", &c.Banco.Schema)
+	l.texto("POSTGRES_USER", &c.Banco.Usuario)
+	l.texto("POSTGRES_PASSWORD", &c.Banco.Senha)
+	l.texto("DATABASE_HOST", &c.Banco.Host)
+	l.inteiro("DATABASE_PORT", &c.Banco.Porta)
+	l.texto("POSTGRES_NAME", &c.Banco.Nome)
+	l.texto("DATABASE_SSL_MODE", &c.Banco.ModoSSL)
+	l.duracao("DATABASE_QUERY_TIMEOUT", &c.Banco.TempoLimiteConsulta)
+	l.inteiro("DATABASE_MAX_OPEN_CONNS", &c.Banco.MaxConexoesAbertas)
+	l.inteiro("DATABASE_MAX_IDLE_CONNS", &c.Banco.MaxConexoesOciosas)
+	l.duracao("DATABASE_CONN_MAX_LIFETIME", &c.Banco.TempoDeVidaConexao)
+	l.duracao("DATABASE_CONN_MAX_IDLE_TIME", &c.Banco.TempoOciosoConexao)
+	l.booleano("DATABASE_MIGRATE", &c.Banco.Migrar)
+	l.booleano("DATABASE_SEED", &c.Banco.Semear)
+
+	var chaveSecreta string
+	l.texto("SECRET_KEY", &chaveSecreta)
+	c.Autenticacao.ChaveSecreta = []byte(chaveSecreta)
+	l.texto("JWT_SIGNING_KEY_FILE", &c.Autenticacao.ArquivoChaveAssinatura)
+	l.lista("JWT_VERIFICATION_KEY_FILES", &c.Autenticacao.ArquivosChavesVerificacao)
+	l.texto("JWT_ISSUER", &c.Autenticacao.EmissorTokens)
+	l.texto("JWT_AUDIENCE", &c.Autenticacao.AudienciaTokens)
+	l.inteiro("LOGIN_MAX_FAILURES", &c.Autenticacao.MaxFalhasLogin)
+	l.inteiro("LOGIN_MAX_FAILURES_PER_IP", &c.Autenticacao.MaxFalhasLoginPorIP)
+	l.duracao("LOGIN_FAILURE_WINDOW", &c.Autenticacao.JanelaFalhasLogin)
+	l.duracao("LOGIN_LOCKOUT_DURATION", &c.Autenticacao.TempoBloqueioLogin)
+	l.duracao("LOGIN_INITIAL_DELAY", &c.Autenticacao.AtrasoInicialLogin)
+	l.duracao("LOGIN_MAX_DELAY", &c.Autenticacao.AtrasoMaximoLogin)
+	l.duracao("PASSWORD_RESET_TTL", &c.Autenticacao.DuracaoRedefinicaoSenha)
+
+	l.texto("RATE_LIMIT_STORE", &c.LimiteDeRequisicoes.Armazenamento)
+	l.texto("REDIS_ADDR", &c.LimiteDeRequisicoes.EnderecoRedis)
+	l.texto("REDIS_PASSWORD", &c.LimiteDeRequisicoes.SenhaRedis)
+	l.inteiro("REDIS_DB", &c.LimiteDeRequisicoes.BancoRedis)
+
+	l.texto("MAILER", &c.Email.Enviador)
+	l.texto("MAILER_FILE", &c.Email.Arquivo)
+	l.texto("MAIL_FROM", &c.Email.Remetente)
+	l.texto("SMTP_HOST", &c.Email.HostSMTP)
+	l.inteiro("SMTP_PORT", &c.Email.PortaSMTP)
+	l.texto("SMTP_USER", &c.Email.UsuarioSMTP)
+	l.texto("SMTP_PASSWORD", &c.Email.SenhaSMTP)
+	l.texto("PASSWORD_RESET_URL", &c.Email.URLRedefinicaoSenha)
+	l.texto("EMAIL_VERIFICATION_URL", &c.Email.URLVerificacaoEmail)
+
+	l.texto("LOG_LEVEL", &c.Logs.Nivel)
+	l.texto("LOG_FORMAT", &c.Logs.Formato)
+
+	l.texto("OTEL_TRACES_EXPORTER", &c.Rastreamento.Exportador)
+	l.texto("OTEL_SERVICE_NAME", &c.Rastreamento.NomeServico)
+	l.decimal("OTEL_TRACES_SAMPLER_ARG", &c.Rastreamento.Amostragem)
+
+	return c, errors.Join(l.erros...)
+}
+
+func (c *Config) lerFlags(argumentos []string) error {
+	flags := flag.NewFlagSet("api", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	flags.IntVar(&c.Servidor.Porta, "porta", c.Servidor.Porta, "porta HTTP da API")
+	flags.BoolVar(&c.Banco.Migrar, "migrar", c.Banco.Migrar, "aplica as migrações pendentes antes de iniciar a API")
+	flags.BoolVar(&c.Banco.Semear, "semear", c.Banco.Semear, "insere os dados iniciais de desenvolvimento")
+	flags.StringVar(&c.Logs.Nivel, "log-nivel", c.Logs.Nivel, "nível dos logs: debug, info, warn ou error")
+	flags.StringVar(&c.Logs.Formato, "log-formato", c.Logs.Formato, "formato dos logs: json ou text")
+	flags.StringVar(&c.Rastreamento.Exportador, "traces", c.Rastreamento.Exportador, "exportador de traces: none, otlp ou stdout")
+
+	return flags.Parse(argumentos)
+}
+
+// Validar confere todos os campos e retorna todas as falhas de uma vez.
+func (c Config) Validar() error {
+	var erros []error
+	falha := func(formato string, argumentos ...any) {
+		erros = append(erros, fmt.Errorf(formato, argumentos...))
+	}
+
+	if c.Servidor.Porta < 1 || c.Servidor.Porta > 65535 {
+		falha("SERVER_PORT deve estar entre 1 e 65535")
+	}
+	if c.Servidor.TempoLimiteEscrita <= c.Banco.TempoLimiteConsulta {
+		falha("SERVER_WRITE_TIMEOUT deve ser maior que DATABASE_QUERY_TIMEOUT")
+	}
+
+	if !slices.Contains([]string{"postgres", "postgresql"}, c.Banco.Schema) {
+		falha("DATABASE_SCHEMA deve ser postgres ou postgresql")
+	}
+	if c.Banco.Host == "" {
+		falha("DATABASE_HOST é obrigatório")
+	}
+	if c.Banco.Usuario == "" {
+		falha("POSTGRES_USER é obrigatório")
+	}
+	if c.Banco.Nome == "" {
+		falha("POSTGRES_NAME é obrigatório")
+	}
+	if c.Banco.Porta < 1 || c.Banco.Porta > 65535 {
+		falha("DATABASE_PORT deve estar entre 1 e 65535")
+	}
+	if !slices.Contains(modosSSL, c.Banco.ModoSSL) {
+		falha("DATABASE_SSL_MODE deve ser um de: %s", strings.Join(modosSSL, ", "))
+	}
+	// Zero conexões abertas significa sem limite no database/sql.
+	if c.Banco.MaxConexoesAbertas > 0 && c.Banco.MaxConexoesOciosas > c.Banco.MaxConexoesAbertas {
+		falha("DATABASE_MAX_IDLE_CONNS não pode ser maior que DATABASE_MAX_OPEN_CONNS")
+	}
+
+	// Sem o arquivo da chave de assinatura, a SECRET_KEY é obrigatória. Com
+	// ele, é opcional, mas continua sujeita ao tamanho mínimo.
+	if (c.Autenticacao.ArquivoChaveAssinatura == "" || len(c.Autenticacao.ChaveSecreta) > 0) && len(c.Autenticacao.ChaveSecreta) < TamanhoMinimoChaveSecreta {
+		falha("SECRET_KEY deve ter pelo menos %d bytes", TamanhoMinimoChaveSecreta)
+	}
+	if c.Autenticacao.EmissorTokens == "" || c.Autenticacao.AudienciaTokens == "" {
+		falha("JWT_ISSUER e JWT_AUDIENCE não podem ser vazios")
+	}
+	if c.Autenticacao.MaxFalhasLogin < 1 || c.Autenticacao.MaxFalhasLoginPorIP < 1 {
+		falha("LOGIN_MAX_FAILURES e LOGIN_MAX_FAILURES_PER_IP devem ser maiores que zero")
+	}
+	if c.Autenticacao.JanelaFalhasLogin < c.Autenticacao.TempoBloqueioLogin {
+		falha("LOGIN_FAILURE_WINDOW não pode ser menor que LOGIN_LOCKOUT_DURATION")
+	}
+	if c.Autenticacao.AtrasoInicialLogin > c.Autenticacao.AtrasoMaximoLogin {
+		falha("LOGIN_INITIAL_DELAY não pode ser maior que LOGIN_MAX_DELAY")
+	}
+	if c.Autenticacao.DuracaoRedefinicaoSenha < time.Minute {
+		falha("PASSWORD_RESET_TTL deve ser de pelo menos 1m")
+	}
+
+	switch c.LimiteDeRequisicoes.Armazenamento {
+	case "memory":
+	case "redis":
+		if c.LimiteDeRequisicoes.EnderecoRedis == "" {
+			falha("REDIS_ADDR é obrigatório quando RATE_LIMIT_STORE=redis")
+		}
+	default:
+		falha("RATE_LIMIT_STORE deve ser memory ou redis")
+	}
+
+	switch c.Email.Enviador {
+	case "log":
+	case "smtp":
+		if c.Email.HostSMTP == "" {
+			falha("SMTP_HOST é obrigatório quando MAILER=smtp")
+		}
+		if c.Email.PortaSMTP < 1 || c.Email.PortaSMTP > 65535 {
+			falha("SMTP_PORT deve estar entre 1 e 65535")
+		}
+	case "":
+		falha("MAILER é obrigatório: log ou smtp")
+	default:
+		falha("MAILER deve ser log ou smtp")
+	}
+	if _, erro := mail.ParseAddress(c.Email.Remetente); erro != nil {
+		falha("MAIL_FROM deve ser um endereço de email válido")
+	}
+	if uri, erro := url.Parse(c.Email.URLRedefinicaoSenha); erro != nil || !uri.IsAbs() {
+		falha("PASSWORD_RESET_URL deve ser uma URL absoluta")
+	}
+	if uri, erro := url.Parse(c.Email.URLVerificacaoEmail); erro != nil || !uri.IsAbs() {
+		falha("EMAIL_VERIFICATION_URL deve ser uma URL absoluta")
+	}
+
+	var nivel slog.Level
+	if nivel.UnmarshalText([]byte(c.Logs.Nivel)) != nil {
+		falha("LOG_LEVEL deve ser debug, info, warn ou error")
+	}
+	if !slices.Contains([]string{"json", "text"}, c.Logs.Formato) {
+		falha("LOG_FORMAT deve ser json ou text")
+	}
+
+	if !slices.Contains([]string{"none", "otlp", "stdout"}, c.Rastreamento.Exportador) {
+		falha("OTEL_TRACES_EXPORTER deve ser none, otlp ou stdout")
+	}
+	if c.Rastreamento.Amostragem < 0 || c.Rastreamento.Amostragem > 1 {
+		falha("OTEL_TRACES_SAMPLER_ARG deve estar entre 0 e 1")
+	}
+
+	return errors.Join(erros...)
+}
+
+// URI monta a string de conexão com o banco.
+func (b Banco) URI() string {
+	uri := url.URL{
+		Scheme:   b.Schema,
+		User:     url.UserPassword(b.Usuario, b.Senha),
+		Host:     fmt.Sprintf("%s:%d", b.Host, b.Porta),
+		Path:     b.Nome,
+		RawQuery: url.Values{"sslmode": {b.ModoSSL}}.Encode(),
+	}
+
+	return uri.String()
+}
+
+// LogValue descreve a configuração efetiva nos logs, com as senhas e a chave
+// secreta ocultas.
+func (c Config) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Group("servidor",
+			"porta", c.Servidor.Porta,
+			"tempo_limite_leitura", c.Servidor.TempoLimiteLeitura,
+			"tempo_limite_escrita", c.Servidor.TempoLimiteEscrita,
+			"tempo_limite_ocioso", c.Servidor.TempoLimiteOcioso,
+			"tempo_encerramento", c.Servidor.TempoEncerramento,
+			"espera_antes_de_encerrar", c.Servidor.EsperaAntesDeEncerrar,
+			"tempo_limite_verificacao_saude", c.Servidor.TempoLimiteVerificacaoSaude,
+		),
+		slog.Group("banco",
+			"schema", c.Banco.Schema,
+			"usuario", c.Banco.Usuario,
+			"senha", ocultar(c.Banco.Senha),
+			"host", c.Banco.Host,
+			"porta", c.Banco.Porta,
+			"nome", c.Banco.Nome,
+			"modo_ssl", c.Banco.ModoSSL,
+			"tempo_limite_consulta", c.Banco.TempoLimiteConsulta,
+			"max_conexoes_abertas", c.Banco.MaxConexoesAbertas,
+			"max_conexoes_ociosas", c.Banco.MaxConexoesOciosas,
+			"tempo_de_vida_conexao", c.Banco.TempoDeVidaConexao,
+			"tempo_ocioso_conexao", c.Banco.TempoOciosoConexao,
+			"migrar", c.Banco.Migrar,
+			"semear", c.Banco.Semear,
+		),
+		slog.Group("autenticacao",
+			"chave_secreta", ocultar(string(c.Autenticacao.ChaveSecreta)),
+			"arquivo_chave_assinatura", c.Autenticacao.ArquivoChaveAssinatura,
+			"arquivos_chaves_verificacao", c.Autenticacao.ArquivosChavesVerificacao,
+			"emissor_tokens", c.Autenticacao.EmissorTokens,
+			"audiencia_tokens", c.Autenticacao.AudienciaTokens,
+			"max_falhas_login", c.Autenticacao.MaxFalhasLogin,
+			"max_falhas_login_por_ip", c.Autenticacao.MaxFalhasLoginPorIP,
+			"janela_falhas_login", c.Autenticacao.JanelaFalhasLogin,
+			"tempo_bloqueio_login", c.Autenticacao.TempoBloqueioLogin,
+			"atraso_inicial_login", c.Autenticacao.AtrasoInicialLogin,
+			"atraso_maximo_login", c.Autenticacao.AtrasoMaximoLogin,
+			"duracao_redefinicao_senha", c.Autenticacao.DuracaoRedefinicaoSenha,
+		),
+		slog.Group("limite_de_requisicoes",
+			"armazenamento", c.LimiteDeRequisicoes.Armazenamento,
+			"endereco_redis", c.LimiteDeRequisicoes.EnderecoRedis,
+			"senha_redis", ocultar(c.LimiteDeRequisicoes.SenhaRedis),
+			"banco_redis", c.LimiteDeRequisicoes.BancoRedis,
+		),
+		slog.Group("email",
+			"enviador", c.Email.Enviador,
+			"arquivo", c.Email.Arquivo,
+			"remetente", c.Email.Remetente,
+			"host_smtp", c.Email.HostSMTP,
+			"porta_smtp", c.Email.PortaSMTP,
+			"usuario_smtp", c.Email.UsuarioSMTP,
+			"senha_smtp", ocultar(c.Email.SenhaSMTP),
+			"url_redefinicao_senha", c.Email.URLRedefinicaoSenha,
+			"url_verificacao_email", c.Email.URLVerificacaoEmail,
+		),
+		slog.Group("logs",
+			"nivel", c.Logs.Nivel,
+			"formato", c.Logs.Formato,
+		),
+		slog.Group("rastreamento",
+			"exportador", c.Rastreamento.Exportador,
+			"nome_servico", c.Rastreamento.NomeServico,
+			"amostragem", c.Rastreamento.Amostragem,
+		),
+	)
+}
+
+func ocultar(valor string) string {
+	if valor == "" {
+		return ""
+	}
+
+	return valorOculto
+}
+
+// leitor converte as variáveis de ambiente e acumula os erros de conversão.
+type leitor struct {
+	buscar func(string) (string, bool)
+	erros  []error
+}
+
+func (l *leitor) valor(variavel string) (string, bool) {
+	valor, ok := l.buscar(variavel)
+	return valor, ok && valor != ""
+}
+
+func (l *leitor) falhar(variavel, valor, esperado string) {
+	l.erros = append(l.erros, fmt.Errorf("%s=%q não é %s", variavel, valor, esperado))
+}
+
+func (l *leitor) texto(variavel string, destino *string) {
+	if valor, ok := l.valor(variavel); ok {
+		*destino = valor
+	}
+}
+
+// lista separa os valores por vírgula e ignora os itens vazios.
+func (l *leitor) lista(variavel string, destino *[]string) {
+	valor, ok := l.valor(variavel)
+	if !ok {
+		return
+	}
+
+	var itens []string
+	for _, item := range strings.Split(valor, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			itens = append(itens, item)
+		}
+	}
+
+	*destino = itens
+}
+
+func (l *leitor) inteiro(variavel string, destino *int) {
+	valor, ok := l.valor(variavel)
+	if !ok {
+		return
+	}
+
+	convertido, erro := strconv.Atoi(valor)
+	if erro != nil || convertido < 0 {
+		l.falhar(variavel, valor, "um inteiro positivo")
+		return
+	}
+
+	*destino = convertido
+}
+
+func (l *leitor) decimal(variavel string, destino *float64) {
+	valor, ok := l.valor(variavel)
+	if !ok {
+		return
+	}
+
+	convertido, erro := strconv.ParseFloat(valor, 64)
+	if erro != nil {
+		l.falhar(variavel, valor, "um número")
+		return
+	}
+
+	*destino = convertido
+}
+
+func (l *leitor) duracao(variavel string, destino *time.Duration) {
+	valor, ok := l.valor(variavel)
+	if !ok {
+		return
+	}
+
+	convertido, erro := time.ParseDuration(valor)
+	if erro != nil || convertido < 0 {
+		l.falhar(variavel, valor, "uma duração válida, como 5s ou 1m")
+		return
+	}
+
+	*destino = convertido
+}
 
-	if erro = godotenv.Load(".env"); erro != nil {
-		log.Fatal(erro)
+func (l *leitor) booleano(variavel string, destino *bool) {
+	valor, ok := l.valor(variavel)
+	if !ok {
+		return
 	}
 
-	Porta, erro = strconv.Atoi(os.Getenv("SERVER_PORT"))
+	convertido, erro := strconv.ParseBool(valor)
 	if erro != nil {
-		Porta = 8000
+		l.falhar(variavel, valor, "true ou false")
+		return
 	}
 
-	SecretKey = []byte(os.Getenv("SECRET_KEY"))
+	*destino = convertido
 }
@@ -0,0 +1,17 @@
+package controllers
+
+import (
+	"api/src/respostas"
+	"net/http"
+)
+
+// tempoCacheChaves é por quanto tempo os clientes podem guardar o JWKS. Uma
+// nova chave deve ser publicada pelo menos esse tempo antes de assinar.
+const tempoCacheChaves = "public, max-age=300"
+
+// ChavesPublicas publica, no formato JWKS, as chaves públicas que verificam os
+// tokens da API, para que outros serviços validem os tokens sem a chave secreta.
+func (uc *UsuarioController) ChavesPublicas(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", tempoCacheChaves)
+	respostas.JSON(w, http.StatusOK, uc.Emissor.ChavesPublicas())
+}
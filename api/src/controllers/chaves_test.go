@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChavesPublicas_WhenSigningWithEd25519_ExpectedCacheableJWKS(t *testing.T) {
+	_, privada, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	bytes, err := x509.MarshalPKCS8PrivateKey(privada)
+	require.NoError(t, err)
+	chave, err := autenticacao.LerChavePEM(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: bytes}))
+	require.NoError(t, err)
+	chaveiro, err := autenticacao.NovoChaveiro(chave, autenticacao.NovaChaveHMAC([]byte(chaveDeTeste)))
+	require.NoError(t, err)
+
+	controller := &UsuarioController{Emissor: autenticacao.NovoEmissor(chaveiro, "social-network-api", "social-network")}
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	rr := httptest.NewRecorder()
+
+	controller.ChavesPublicas(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "public, max-age=300", rr.Header().Get("Cache-Control"))
+
+	var conjunto autenticacao.JWKS
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &conjunto))
+	require.Len(t, conjunto.Keys, 1, "a chave HMAC não é publicada")
+	assert.Equal(t, chave.ID, conjunto.Keys[0].Kid)
+	assert.Equal(t, "EdDSA", conjunto.Keys[0].Alg)
+	assert.NotContains(t, rr.Body.String(), chaveDeTeste)
+}
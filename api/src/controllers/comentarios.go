@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/erros"
+	"api/src/modelos"
+	"api/src/repositorios"
+	"api/src/respostas"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type ComentariosController struct {
+	Repositorio            repositorios.ComentariosRepositorio
+	RepositorioPublicacoes repositorios.PublicacoesRepositorio
+}
+
+func NovoComentariosController(repositorio repositorios.ComentariosRepositorio, repositorioPublicacoes repositorios.PublicacoesRepositorio) *ComentariosController {
+	return &ComentariosController{Repositorio: repositorio, RepositorioPublicacoes: repositorioPublicacoes}
+}
+
+func (cc *ComentariosController) CriarComentario(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	publicacaoID, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	if _, erro = cc.RepositorioPublicacoes.BuscarPorId(r.Context(), publicacaoID, usuarioID); erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	corpoRequisicao, erro := ioutil.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var comentario modelos.Comentario
+	if erro = json.Unmarshal(corpoRequisicao, &comentario); erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	comentario.PublicacaoID = publicacaoID
+	comentario.AutorID = usuarioID
+
+	if erro = comentario.Preparar(); erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	comentario.ID, erro = cc.Repositorio.Criar(r.Context(), comentario)
+	if erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusCreated, comentario)
+}
+
+func (cc *ComentariosController) BuscarComentarios(w http.ResponseWriter, r *http.Request) {
+	parametros := mux.Vars(r)
+	publicacaoID, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	comentarios, erro := cc.Repositorio.BuscarPorPublicacao(r.Context(), publicacaoID)
+	if erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, comentarios)
+}
+
+func (cc *ComentariosController) AtualizarComentario(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	comentarioID, erro := strconv.ParseUint(parametros["comentarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	comentarioSalvoNoBanco, erro := cc.Repositorio.BuscarPorID(r.Context(), comentarioID)
+	if erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	if comentarioSalvoNoBanco.AutorID != usuarioID {
+		respostas.Erro(w, r, http.StatusForbidden, erros.Novo("comentario.atualizacao_proibida", "Não é possível atualizar um comentário que não seja seu"))
+		return
+	}
+
+	corpoRequisicao, erro := ioutil.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var comentario modelos.Comentario
+	if erro = json.Unmarshal(corpoRequisicao, &comentario); erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	if erro = comentario.Preparar(); erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	if erro = cc.Repositorio.Atualizar(r.Context(), comentarioID, comentario); erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// DeletarComentario permite a exclusão pelo autor do comentário ou pelo autor da publicação comentada.
+func (cc *ComentariosController) DeletarComentario(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	comentarioID, erro := strconv.ParseUint(parametros["comentarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	comentarioSalvoNoBanco, erro := cc.Repositorio.BuscarPorID(r.Context(), comentarioID)
+	if erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	if comentarioSalvoNoBanco.AutorID != usuarioID {
+		publicacao, erro := cc.RepositorioPublicacoes.BuscarPorId(r.Context(), comentarioSalvoNoBanco.PublicacaoID, usuarioID)
+		if erro != nil {
+			respostas.ErroDeDominio(w, r, erro)
+			return
+		}
+
+		if publicacao.AutorID != usuarioID {
+			respostas.Erro(w, r, http.StatusForbidden, erros.Novo("comentario.exclusao_proibida", "Não é possível deletar um comentário que não seja seu"))
+			return
+		}
+	}
+
+	if erro = cc.Repositorio.Deletar(r.Context(), comentarioID); erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"api/src/modelos"
+	"api/src/repositorios"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockComentariosRepositorio struct {
+	mock.Mock
+}
+
+func (m *MockComentariosRepositorio) Criar(ctx context.Context, comentario modelos.Comentario) (uint64, error) {
+	args := m.Called(comentario)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (m *MockComentariosRepositorio) BuscarPorID(ctx context.Context, comentarioID uint64) (modelos.Comentario, error) {
+	args := m.Called(comentarioID)
+	return args.Get(0).(modelos.Comentario), args.Error(1)
+}
+
+func (m *MockComentariosRepositorio) BuscarPorPublicacao(ctx context.Context, publicacaoID uint64) ([]modelos.Comentario, error) {
+	args := m.Called(publicacaoID)
+	return args.Get(0).([]modelos.Comentario), args.Error(1)
+}
+
+func (m *MockComentariosRepositorio) Atualizar(ctx context.Context, comentarioID uint64, comentario modelos.Comentario) error {
+	args := m.Called(comentarioID, comentario)
+	return args.Error(0)
+}
+
+func (m *MockComentariosRepositorio) Deletar(ctx context.Context, comentarioID uint64) error {
+	args := m.Called(comentarioID)
+	return args.Error(0)
+}
+
+func setupComentarios(t *testing.T) (*ComentariosController, *MockComentariosRepositorio, *MockPublicacoesRepositorio, *httptest.ResponseRecorder) {
+	mockRepo := new(MockComentariosRepositorio)
+	mockPublicacoes := new(MockPublicacoesRepositorio)
+	controller := NovoComentariosController(mockRepo, mockPublicacoes)
+	recorder := httptest.NewRecorder()
+	return controller, mockRepo, mockPublicacoes, recorder
+}
+
+func createCommentRequest(t *testing.T, metodo, uri string, usuarioID uint64, corpo interface{}, vars map[string]string) *http.Request {
+	var body []byte
+	if corpo != nil {
+		body, _ = json.Marshal(corpo)
+	}
+	r := httptest.NewRequest(metodo, uri, bytes.NewReader(body))
+	r = autenticarRequisicao(r, usuarioID)
+	return mux.SetURLVars(r, vars)
+}
+
+func TestCreateComment_WhenPublicationExists_ExpectedNewComment(t *testing.T) {
+	controller, mockRepo, mockPublicacoes, recorder := setupComentarios(t)
+
+	mockPublicacoes.On("BuscarPorId", uint64(1), uint64(2)).Return(modelos.Publicacao{ID: 1, AutorID: 1}, nil)
+	comentarioEsperado := modelos.Comentario{PublicacaoID: 1, AutorID: 2, Conteudo: "Comentário"}
+	mockRepo.On("Criar", comentarioEsperado).Return(uint64(1), nil)
+
+	r := createCommentRequest(t, "POST", "/publicacoes/1/comentarios", 2,
+		modelos.Comentario{Conteudo: "  Comentário  "}, map[string]string{"publicacaoId": "1"})
+	controller.CriarComentario(recorder, r)
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	mockRepo.AssertExpectations(t)
+	mockPublicacoes.AssertExpectations(t)
+}
+
+func TestCreateComment_WhenPublicationDoesNotExist_ExpectedNotFoundError(t *testing.T) {
+	controller, mockRepo, mockPublicacoes, recorder := setupComentarios(t)
+
+	mockPublicacoes.On("BuscarPorId", uint64(1), uint64(2)).Return(modelos.Publicacao{}, repositorios.ErrNaoEncontrado)
+
+	r := createCommentRequest(t, "POST", "/publicacoes/1/comentarios", 2,
+		modelos.Comentario{Conteudo: "Comentário"}, map[string]string{"publicacaoId": "1"})
+	controller.CriarComentario(recorder, r)
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	mockRepo.AssertNotCalled(t, "Criar", mock.Anything)
+}
+
+func TestCreateComment_WhenContentIsBlank_ExpectedBadRequestError(t *testing.T) {
+	controller, mockRepo, mockPublicacoes, recorder := setupComentarios(t)
+
+	mockPublicacoes.On("BuscarPorId", uint64(1), uint64(2)).Return(modelos.Publicacao{ID: 1, AutorID: 1}, nil)
+
+	r := createCommentRequest(t, "POST", "/publicacoes/1/comentarios", 2,
+		modelos.Comentario{Conteudo: "   "}, map[string]string{"publicacaoId": "1"})
+	controller.CriarComentario(recorder, r)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	mockRepo.AssertNotCalled(t, "Criar", mock.Anything)
+}
+
+func TestCreateComment_WhenContentIsTooLong_ExpectedFieldValidationError(t *testing.T) {
+	controller, mockRepo, mockPublicacoes, recorder := setupComentarios(t)
+
+	mockPublicacoes.On("BuscarPorId", uint64(1), uint64(2)).Return(modelos.Publicacao{ID: 1, AutorID: 1}, nil).Maybe()
+
+	conteudo := strings.Repeat("é", modelos.TamanhoMaximoComentario+1)
+	r := createCommentRequest(t, "POST", "/publicacoes/1/comentarios", 2,
+		modelos.Comentario{Conteudo: conteudo}, map[string]string{"publicacaoId": "1"})
+	controller.CriarComentario(recorder, r)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"code":"comentario.conteudo_muito_longo"`)
+	mockRepo.AssertNotCalled(t, "Criar", mock.Anything)
+}
+
+func TestSearchComments_WhenPublicationHasComments_ExpectedCommentsReturned(t *testing.T) {
+	controller, mockRepo, _, recorder := setupComentarios(t)
+
+	comentarios := []modelos.Comentario{{ID: 1, PublicacaoID: 1, AutorID: 2, Conteudo: "Comentário"}}
+	mockRepo.On("BuscarPorPublicacao", uint64(1)).Return(comentarios, nil)
+
+	r := createCommentRequest(t, "GET", "/publicacoes/1/comentarios", 2, nil, map[string]string{"publicacaoId": "1"})
+	controller.BuscarComentarios(recorder, r)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Comentário")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateComment_WhenUserIsNotTheAuthor_ExpectedForbiddenError(t *testing.T) {
+	controller, mockRepo, _, recorder := setupComentarios(t)
+
+	mockRepo.On("BuscarPorID", uint64(1)).Return(modelos.Comentario{ID: 1, PublicacaoID: 1, AutorID: 2}, nil)
+
+	r := createCommentRequest(t, "PUT", "/comentarios/1", 3,
+		modelos.Comentario{Conteudo: "Editado"}, map[string]string{"comentarioId": "1"})
+	controller.AtualizarComentario(recorder, r)
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	mockRepo.AssertNotCalled(t, "Atualizar", mock.Anything, mock.Anything)
+}
+
+func TestUpdateComment_WhenCommentDoesNotExist_ExpectedNotFoundError(t *testing.T) {
+	controller, mockRepo, _, recorder := setupComentarios(t)
+
+	mockRepo.On("BuscarPorID", uint64(1)).Return(modelos.Comentario{}, repositorios.ErrNaoEncontrado)
+
+	r := createCommentRequest(t, "PUT", "/comentarios/1", 2,
+		modelos.Comentario{Conteudo: "Editado"}, map[string]string{"comentarioId": "1"})
+	controller.AtualizarComentario(recorder, r)
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteComment_WhenUserIsThePublicationAuthor_ExpectedDeletedComment(t *testing.T) {
+	controller, mockRepo, mockPublicacoes, recorder := setupComentarios(t)
+
+	mockRepo.On("BuscarPorID", uint64(1)).Return(modelos.Comentario{ID: 1, PublicacaoID: 5, AutorID: 2}, nil)
+	mockPublicacoes.On("BuscarPorId", uint64(5), uint64(1)).Return(modelos.Publicacao{ID: 5, AutorID: 1}, nil)
+	mockRepo.On("Deletar", uint64(1)).Return(nil)
+
+	r := createCommentRequest(t, "DELETE", "/comentarios/1", 1, nil, map[string]string{"comentarioId": "1"})
+	controller.DeletarComentario(recorder, r)
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	mockRepo.AssertExpectations(t)
+	mockPublicacoes.AssertExpectations(t)
+}
+
+func TestDeleteComment_WhenUserIsNeitherAuthor_ExpectedForbiddenError(t *testing.T) {
+	controller, mockRepo, mockPublicacoes, recorder := setupComentarios(t)
+
+	mockRepo.On("BuscarPorID", uint64(1)).Return(modelos.Comentario{ID: 1, PublicacaoID: 5, AutorID: 2}, nil)
+	mockPublicacoes.On("BuscarPorId", uint64(5), uint64(3)).Return(modelos.Publicacao{ID: 5, AutorID: 1}, nil)
+
+	r := createCommentRequest(t, "DELETE", "/comentarios/1", 3, nil, map[string]string{"comentarioId": "1"})
+	controller.DeletarComentario(recorder, r)
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	mockRepo.AssertNotCalled(t, "Deletar", mock.Anything)
+}
+
+func TestDeleteComment_WhenDatabaseFails_ExpectedInternalServerError(t *testing.T) {
+	controller, mockRepo, _, recorder := setupComentarios(t)
+
+	mockRepo.On("BuscarPorID", uint64(1)).Return(modelos.Comentario{ID: 1, PublicacaoID: 5, AutorID: 2}, nil)
+	mockRepo.On("Deletar", uint64(1)).Return(errors.New("erro ao deletar o comentário"))
+
+	r := createCommentRequest(t, "DELETE", "/comentarios/1", 2, nil, map[string]string{"comentarioId": "1"})
+	controller.DeletarComentario(recorder, r)
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,282 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/erros"
+	"api/src/modelos"
+	"api/src/respostas"
+	"api/src/seguranca"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	errDoisFatoresJaAtivo     = erros.Novo("dois_fatores.ja_ativo", "A autenticação em dois fatores já está ativa")
+	errDoisFatoresNaoIniciado = erros.Novo("dois_fatores.inscricao_nao_iniciada", "Inicie a inscrição na autenticação em dois fatores antes de confirmá-la")
+	errCodigoDoisFatores      = erros.Novo("dois_fatores.codigo_invalido", "Código de autenticação inválido")
+	errCodigoObrigatorio      = erros.Novo("dois_fatores.codigo_obrigatorio", "O código de autenticação é obrigatório")
+	errDesafioInvalido        = erros.Novo("dois_fatores.desafio_invalido", "Token de desafio inválido ou expirado")
+)
+
+// IniciarDoisFatores gera um novo segredo TOTP para o usuário autenticado. A
+// autenticação em dois fatores só passa a ser exigida depois da confirmação.
+func (uc *UsuarioController) IniciarDoisFatores(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusUnauthorized, erro)
+		return
+	}
+
+	doisFatores, erro := uc.RepositorioDoisFatores.Buscar(r.Context(), usuarioID)
+	if erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+	if doisFatores.Ativo {
+		respostas.Erro(w, r, http.StatusConflict, errDoisFatoresJaAtivo)
+		return
+	}
+
+	usuario, erro := uc.Repositorio.BuscarPorID(r.Context(), usuarioID)
+	if erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	segredo, erro := autenticacao.GerarSegredoTOTP()
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if erro = uc.RepositorioDoisFatores.IniciarInscricao(r.Context(), usuarioID, segredo); erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusCreated, modelos.InscricaoDoisFatores{
+		Segredo:            segredo,
+		URIProvisionamento: autenticacao.URIProvisionamentoTOTP(segredo, usuario.Email),
+	})
+}
+
+// ConfirmarDoisFatores ativa a autenticação em dois fatores com o primeiro
+// código do autenticador e retorna os códigos de recuperação. Os códigos não
+// podem ser consultados de novo.
+func (uc *UsuarioController) ConfirmarDoisFatores(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusUnauthorized, erro)
+		return
+	}
+
+	requisicao, erro := lerRequisicaoCodigoDoisFatores(r)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	doisFatores, erro := uc.RepositorioDoisFatores.Buscar(r.Context(), usuarioID)
+	if erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+	if doisFatores.Ativo {
+		respostas.Erro(w, r, http.StatusConflict, errDoisFatoresJaAtivo)
+		return
+	}
+	if doisFatores.Segredo == "" {
+		respostas.Erro(w, r, http.StatusConflict, errDoisFatoresNaoIniciado)
+		return
+	}
+
+	passo, ok := autenticacao.ValidarCodigoTOTP(doisFatores.Segredo, requisicao.Codigo, time.Now())
+	if !ok {
+		respostas.Erro(w, r, http.StatusUnprocessableEntity, errCodigoDoisFatores)
+		return
+	}
+
+	codigos, hashes, erro := autenticacao.GerarCodigosRecuperacao()
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if erro = uc.RepositorioDoisFatores.Ativar(r.Context(), usuarioID, passo, hashes); erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, modelos.CodigosRecuperacao{Codigos: codigos})
+}
+
+// DesativarDoisFatores desliga a autenticação em dois fatores depois de
+// conferir a senha do usuário.
+func (uc *UsuarioController) DesativarDoisFatores(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusUnauthorized, erro)
+		return
+	}
+
+	corpoRequest, erro := ioutil.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var requisicao modelos.RequisicaoDesativarDoisFatores
+	if erro = json.Unmarshal(corpoRequest, &requisicao); erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	senhaSalvaNoBanco, erro := uc.Repositorio.BuscarSenha(r.Context(), usuarioID)
+	if erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	if erro = seguranca.VerificarSenha(senhaSalvaNoBanco, requisicao.Senha); erro != nil {
+		respostas.Erro(w, r, http.StatusUnauthorized, erros.Novo("senha.atual_incorreta", "A senha atual não condiz com a que está salva no banco"))
+		return
+	}
+
+	if erro = uc.RepositorioDoisFatores.Desativar(r.Context(), usuarioID); erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// LoginDoisFatores troca o token de desafio e o código do autenticador, ou um
+// código de recuperação, pelo par de tokens. Os códigos errados contam como
+// falhas de login da conta, sujeitas ao mesmo bloqueio da senha.
+func (uc *UsuarioController) LoginDoisFatores(w http.ResponseWriter, r *http.Request) {
+	requisicao, erro := lerRequisicaoCodigoDoisFatores(r)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	principal, erro := uc.Emissor.ValidarDesafioDoisFatores(requisicao.TokenDesafio)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusUnauthorized, errDesafioInvalido)
+		return
+	}
+
+	usuario, erro := uc.Repositorio.BuscarPorID(r.Context(), principal.UsuarioID)
+	if erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	tentativa := modelos.TentativaLogin{
+		Email:     strings.ToLower(usuario.Email),
+		UsuarioID: usuario.ID,
+		IP:        autenticacao.IPDoCliente(r),
+	}
+
+	tentativa, ok := uc.iniciarTentativa(w, r, tentativa)
+	if !ok {
+		return
+	}
+
+	// A troca de senha entre o desafio e o código invalida o desafio. A
+	// conferência vem antes do código, para que um desafio vencido não gaste o
+	// passo TOTP nem um código de recuperação.
+	versaoToken, erro := uc.Repositorio.BuscarVersaoToken(r.Context(), principal.UsuarioID)
+	if erro != nil {
+		uc.descartarTentativa(r, tentativa)
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+	if versaoToken != principal.VersaoToken {
+		uc.descartarTentativa(r, tentativa)
+		respostas.Erro(w, r, http.StatusUnauthorized, errDesafioInvalido)
+		return
+	}
+
+	valido, erro := uc.verificarCodigoDoisFatores(r, principal.UsuarioID, requisicao.Codigo)
+	if erro != nil {
+		uc.descartarTentativa(r, tentativa)
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+	if !valido {
+		uc.concluirTentativa(r, tentativa, modelos.ResultadoLoginFalha)
+		respostas.Erro(w, r, http.StatusUnauthorized, errCodigoDoisFatores)
+		return
+	}
+
+	uc.concluirTentativa(r, tentativa, modelos.ResultadoLoginSucesso)
+
+	familia, erro := autenticacao.NovaFamiliaDeTokens()
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusInternalServerError, erro)
+		return
+	}
+
+	tokens, erro := uc.emitirTokens(r.Context(), principal.UsuarioID, versaoToken, familia)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, tokens)
+}
+
+// responderDesafioDoisFatores encerra a primeira etapa do login de quem tem a
+// autenticação em dois fatores ativa.
+func (uc *UsuarioController) responderDesafioDoisFatores(w http.ResponseWriter, r *http.Request, usuario modelos.Usuario) {
+	tokenDesafio, erro := uc.Emissor.CriarDesafioDoisFatores(usuario.ID, usuario.VersaoToken)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, modelos.DesafioDoisFatores{
+		DoisFatoresPendente: true,
+		TokenDesafio:        tokenDesafio,
+		ExpiraEm:            int64(autenticacao.DuracaoDesafioDoisFatores.Seconds()),
+	})
+}
+
+// verificarCodigoDoisFatores aceita um código TOTP ainda não usado ou um
+// código de recuperação, que deixa de valer depois do uso.
+func (uc *UsuarioController) verificarCodigoDoisFatores(r *http.Request, usuarioID uint64, codigo string) (bool, error) {
+	doisFatores, erro := uc.RepositorioDoisFatores.Buscar(r.Context(), usuarioID)
+	if erro != nil || !doisFatores.Ativo {
+		return false, erro
+	}
+
+	if passo, ok := autenticacao.ValidarCodigoTOTP(doisFatores.Segredo, codigo, time.Now()); ok {
+		return uc.RepositorioDoisFatores.RegistrarPasso(r.Context(), usuarioID, passo)
+	}
+
+	return uc.RepositorioDoisFatores.ConsumirCodigoRecuperacao(r.Context(), usuarioID, autenticacao.HashCodigoRecuperacao(codigo))
+}
+
+func lerRequisicaoCodigoDoisFatores(r *http.Request) (modelos.RequisicaoCodigoDoisFatores, error) {
+	var requisicao modelos.RequisicaoCodigoDoisFatores
+
+	corpoRequest, erro := ioutil.ReadAll(r.Body)
+	if erro != nil {
+		return requisicao, erro
+	}
+
+	if erro = json.Unmarshal(corpoRequest, &requisicao); erro != nil {
+		return requisicao, erro
+	}
+
+	requisicao.Codigo = strings.TrimSpace(requisicao.Codigo)
+	if requisicao.Codigo == "" {
+		return requisicao, errCodigoObrigatorio
+	}
+
+	return requisicao, nil
+}
@@ -0,0 +1,253 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/modelos"
+	"api/src/seguranca"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDoisFatoresRepositorio struct {
+	mock.Mock
+}
+
+func (m *MockDoisFatoresRepositorio) Buscar(ctx context.Context, usuarioID uint64) (modelos.DoisFatores, error) {
+	args := m.Called(usuarioID)
+	return args.Get(0).(modelos.DoisFatores), args.Error(1)
+}
+
+func (m *MockDoisFatoresRepositorio) IniciarInscricao(ctx context.Context, usuarioID uint64, segredo string) error {
+	args := m.Called(usuarioID, segredo)
+	return args.Error(0)
+}
+
+func (m *MockDoisFatoresRepositorio) Ativar(ctx context.Context, usuarioID uint64, passo int64, hashesCodigos []string) error {
+	args := m.Called(usuarioID, passo, hashesCodigos)
+	return args.Error(0)
+}
+
+func (m *MockDoisFatoresRepositorio) Desativar(ctx context.Context, usuarioID uint64) error {
+	args := m.Called(usuarioID)
+	return args.Error(0)
+}
+
+func (m *MockDoisFatoresRepositorio) RegistrarPasso(ctx context.Context, usuarioID uint64, passo int64) (bool, error) {
+	args := m.Called(usuarioID, passo)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDoisFatoresRepositorio) ConsumirCodigoRecuperacao(ctx context.Context, usuarioID uint64, hash string) (bool, error) {
+	args := m.Called(usuarioID, hash)
+	return args.Bool(0), args.Error(1)
+}
+
+const segredoDeTeste = "JBSWY3DPEHPK3PXP"
+
+func setupComDoisFatores(t *testing.T, repositorio *MockRepositorio, repositorioTokens *MockTokensRepositorio, doisFatores *MockDoisFatoresRepositorio) (*UsuarioController, *httptest.ResponseRecorder) {
+	controller, recorder := setupComTokens(t, repositorio, repositorioTokens)
+	controller.RepositorioDoisFatores = doisFatores
+	return controller, recorder
+}
+
+func createTwoFactorRequest(t *testing.T, uri string, corpo any) *http.Request {
+	body, _ := json.Marshal(corpo)
+	req, err := http.NewRequest(http.MethodPost, uri, bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	return req
+}
+
+func codigoAtual(t *testing.T) string {
+	codigo, err := autenticacao.GerarCodigoTOTP(segredoDeTeste, time.Now())
+	assert.NoError(t, err)
+	return codigo
+}
+
+func TestLogin_WhenTwoFactorIsActive_ExpectedChallengeInsteadOfTokens(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	mockTokens := new(MockTokensRepositorio)
+	controller, recorder := setupComTokens(t, mockRepo, mockTokens)
+
+	hashSenha, _ := seguranca.Hash("senhaCorreta")
+	mockRepo.On("BuscarPorEmail", "usuario@teste.com").Return(modelos.Usuario{
+		ID: 1, Email: "usuario@teste.com", Senha: string(hashSenha), VersaoToken: 2, DoisFatoresAtivo: true,
+	}, nil)
+
+	controller.Login(recorder, createLoginRequest(t, "usuario@teste.com", "senhaCorreta"))
+
+	var desafio modelos.DesafioDoisFatores
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &desafio))
+	assert.True(t, desafio.DoisFatoresPendente)
+	assert.Equal(t, int64(300), desafio.ExpiraEm)
+	assert.NotContains(t, recorder.Body.String(), "tokenAcesso")
+
+	principal, err := controller.Emissor.ValidarDesafioDoisFatores(desafio.TokenDesafio)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), principal.UsuarioID)
+	assert.Equal(t, uint64(2), principal.VersaoToken)
+	mockTokens.AssertNotCalled(t, "Criar", mock.Anything)
+	controller.RepositorioTentativas.(*MockTentativasRepositorio).AssertCalled(t, "Descartar", uint64(1))
+}
+
+func TestLoginDoisFatores_WhenCodeIsValid_ExpectedTokens(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	mockTokens := new(MockTokensRepositorio)
+	mockDoisFatores := new(MockDoisFatoresRepositorio)
+	controller, recorder := setupComDoisFatores(t, mockRepo, mockTokens, mockDoisFatores)
+
+	tokenDesafio, _ := controller.Emissor.CriarDesafioDoisFatores(1, 0)
+	mockRepo.On("BuscarPorID", uint64(1)).Return(modelos.Usuario{ID: 1, Email: "usuario@teste.com"}, nil)
+	mockRepo.On("BuscarVersaoToken", uint64(1)).Return(uint64(0), nil)
+	mockDoisFatores.On("Buscar", uint64(1)).Return(modelos.DoisFatores{Segredo: segredoDeTeste, Ativo: true}, nil)
+	mockDoisFatores.On("RegistrarPasso", uint64(1), mock.Anything).Return(true, nil)
+	mockTokens.On("Criar", mock.Anything).Return(nil)
+
+	req := createTwoFactorRequest(t, "/login/2fa", modelos.RequisicaoCodigoDoisFatores{TokenDesafio: tokenDesafio, Codigo: codigoAtual(t)})
+	controller.LoginDoisFatores(recorder, req)
+
+	var tokens modelos.ParDeTokens
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &tokens))
+	assert.NotEmpty(t, tokens.TokenAcesso)
+	mockDoisFatores.AssertNotCalled(t, "ConsumirCodigoRecuperacao", mock.Anything, mock.Anything)
+}
+
+func TestLoginDoisFatores_WhenCodeWasAlreadyUsed_ExpectedUnauthorizedAndFailureRecorded(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	mockTokens := new(MockTokensRepositorio)
+	mockDoisFatores := new(MockDoisFatoresRepositorio)
+	tentativas := new(MockTentativasRepositorio)
+	controller, recorder := setupComTentativas(t, mockRepo, mockTokens, tentativas)
+	controller.RepositorioDoisFatores = mockDoisFatores
+
+	tokenDesafio, _ := controller.Emissor.CriarDesafioDoisFatores(1, 0)
+	codigo := codigoAtual(t)
+	mockRepo.On("BuscarPorID", uint64(1)).Return(modelos.Usuario{ID: 1, Email: "usuario@teste.com"}, nil)
+	mockRepo.On("BuscarVersaoToken", uint64(1)).Return(uint64(0), nil)
+	tentativas.On("Iniciar", "usuario@teste.com", mock.Anything).Return(uint64(5), modelos.FalhasDeLogin{}, modelos.FalhasDeLogin{}, nil)
+	tentativas.On("Concluir", mock.MatchedBy(func(tentativa modelos.TentativaLogin) bool {
+		return tentativa.ID == 5 && tentativa.Resultado == modelos.ResultadoLoginFalha && tentativa.UsuarioID == 1
+	})).Return(nil).Once()
+	mockDoisFatores.On("Buscar", uint64(1)).Return(modelos.DoisFatores{Segredo: segredoDeTeste, Ativo: true}, nil)
+	mockDoisFatores.On("RegistrarPasso", uint64(1), mock.Anything).Return(false, nil)
+
+	req := createTwoFactorRequest(t, "/login/2fa", modelos.RequisicaoCodigoDoisFatores{TokenDesafio: tokenDesafio, Codigo: codigo})
+	controller.LoginDoisFatores(recorder, req)
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "dois_fatores.codigo_invalido")
+	tentativas.AssertExpectations(t)
+	mockTokens.AssertNotCalled(t, "Criar", mock.Anything)
+}
+
+func TestLoginDoisFatores_WhenRecoveryCodeIsValid_ExpectedCodeConsumedAndTokens(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	mockTokens := new(MockTokensRepositorio)
+	mockDoisFatores := new(MockDoisFatoresRepositorio)
+	controller, recorder := setupComDoisFatores(t, mockRepo, mockTokens, mockDoisFatores)
+
+	tokenDesafio, _ := controller.Emissor.CriarDesafioDoisFatores(1, 0)
+	mockRepo.On("BuscarPorID", uint64(1)).Return(modelos.Usuario{ID: 1, Email: "usuario@teste.com"}, nil)
+	mockRepo.On("BuscarVersaoToken", uint64(1)).Return(uint64(0), nil)
+	mockDoisFatores.On("Buscar", uint64(1)).Return(modelos.DoisFatores{Segredo: segredoDeTeste, Ativo: true}, nil)
+	mockDoisFatores.On("ConsumirCodigoRecuperacao", uint64(1), autenticacao.HashCodigoRecuperacao("abcd-efgh-jkmn")).Return(true, nil)
+	mockTokens.On("Criar", mock.Anything).Return(nil)
+
+	req := createTwoFactorRequest(t, "/login/2fa", modelos.RequisicaoCodigoDoisFatores{TokenDesafio: tokenDesafio, Codigo: "ABCD-EFGH-JKMN"})
+	controller.LoginDoisFatores(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	mockDoisFatores.AssertExpectations(t)
+}
+
+func TestLoginDoisFatores_WhenPasswordChangedAfterChallenge_ExpectedRejectedWithoutSpendingCode(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	mockTokens := new(MockTokensRepositorio)
+	mockDoisFatores := new(MockDoisFatoresRepositorio)
+	controller, recorder := setupComDoisFatores(t, mockRepo, mockTokens, mockDoisFatores)
+
+	tokenDesafio, _ := controller.Emissor.CriarDesafioDoisFatores(1, 0)
+	mockRepo.On("BuscarPorID", uint64(1)).Return(modelos.Usuario{ID: 1, Email: "usuario@teste.com"}, nil)
+	mockRepo.On("BuscarVersaoToken", uint64(1)).Return(uint64(1), nil)
+
+	req := createTwoFactorRequest(t, "/login/2fa", modelos.RequisicaoCodigoDoisFatores{TokenDesafio: tokenDesafio, Codigo: "ABCD-EFGH-JKMN"})
+	controller.LoginDoisFatores(recorder, req)
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "dois_fatores.desafio_invalido")
+	mockDoisFatores.AssertNotCalled(t, "RegistrarPasso", mock.Anything, mock.Anything)
+	mockDoisFatores.AssertNotCalled(t, "ConsumirCodigoRecuperacao", mock.Anything, mock.Anything)
+	mockTokens.AssertNotCalled(t, "Criar", mock.Anything)
+}
+
+func TestLoginDoisFatores_WhenTokenIsAnAccessToken_ExpectedUnauthorizedError(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	controller, recorder := setup(t, mockRepo)
+
+	tokenAcesso, _ := controller.Emissor.CriarToken(1, 0)
+	req := createTwoFactorRequest(t, "/login/2fa", modelos.RequisicaoCodigoDoisFatores{TokenDesafio: tokenAcesso, Codigo: "123456"})
+	controller.LoginDoisFatores(recorder, req)
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "dois_fatores.desafio_invalido")
+	mockRepo.AssertNotCalled(t, "BuscarPorID", mock.Anything)
+}
+
+func TestConfirmarDoisFatores_WhenCodeIsValid_ExpectedRecoveryCodesReturnedAndHashesStored(t *testing.T) {
+	mockDoisFatores := new(MockDoisFatoresRepositorio)
+	controller, recorder := setupComDoisFatores(t, new(MockRepositorio), new(MockTokensRepositorio), mockDoisFatores)
+
+	var hashes []string
+	mockDoisFatores.On("Buscar", uint64(1)).Return(modelos.DoisFatores{Segredo: segredoDeTeste}, nil)
+	mockDoisFatores.On("Ativar", uint64(1), mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		hashes = args.Get(2).([]string)
+	}).Return(nil)
+
+	req := createTwoFactorRequest(t, "/2fa/confirmar", modelos.RequisicaoCodigoDoisFatores{Codigo: codigoAtual(t)})
+	controller.ConfirmarDoisFatores(recorder, autenticarRequisicao(req, 1))
+
+	var resposta modelos.CodigosRecuperacao
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resposta))
+	assert.Len(t, resposta.Codigos, autenticacao.QuantidadeCodigosRecuperacao)
+	assert.Len(t, hashes, autenticacao.QuantidadeCodigosRecuperacao)
+	assert.Equal(t, autenticacao.HashCodigoRecuperacao(resposta.Codigos[0]), hashes[0])
+	assert.NotContains(t, hashes, resposta.Codigos[0])
+}
+
+func TestConfirmarDoisFatores_WhenCodeIsWrong_ExpectedUnprocessableEntity(t *testing.T) {
+	mockDoisFatores := new(MockDoisFatoresRepositorio)
+	controller, recorder := setupComDoisFatores(t, new(MockRepositorio), new(MockTokensRepositorio), mockDoisFatores)
+
+	mockDoisFatores.On("Buscar", uint64(1)).Return(modelos.DoisFatores{Segredo: segredoDeTeste}, nil)
+
+	req := createTwoFactorRequest(t, "/2fa/confirmar", modelos.RequisicaoCodigoDoisFatores{Codigo: "000000"})
+	controller.ConfirmarDoisFatores(recorder, autenticarRequisicao(req, 1))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	mockDoisFatores.AssertNotCalled(t, "Ativar", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDesativarDoisFatores_WhenPasswordIsWrong_ExpectedUnauthorizedError(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	mockDoisFatores := new(MockDoisFatoresRepositorio)
+	controller, recorder := setupComDoisFatores(t, mockRepo, new(MockTokensRepositorio), mockDoisFatores)
+
+	hashSenha, _ := seguranca.Hash("senhaCorreta")
+	mockRepo.On("BuscarSenha", uint64(1)).Return(string(hashSenha), nil)
+
+	req := createTwoFactorRequest(t, "/2fa/desativar", modelos.RequisicaoDesativarDoisFatores{Senha: "senhaErrada"})
+	controller.DesativarDoisFatores(recorder, autenticarRequisicao(req, 1))
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	mockDoisFatores.AssertNotCalled(t, "Desativar", mock.Anything)
+}
@@ -2,55 +2,324 @@ package controllers
 
 import (
 	"api/src/autenticacao"
+	"api/src/email"
+	"api/src/erros"
+	"api/src/logs"
+	"api/src/metrics"
 	"api/src/modelos"
 	"api/src/repositorios"
 	"api/src/respostas"
 	"api/src/seguranca"
+	"context"
 	"encoding/json"
+	"errors"
 	"io/ioutil"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+var (
+	errCredenciaisInvalidas  = erros.Novo("autenticacao.credenciais_invalidas", "Email ou senha inválidos")
+	errLoginBloqueado        = erros.Novo("autenticacao.login_bloqueado", "Muitas tentativas de login sem sucesso, tente novamente mais tarde")
+	errApenasAdministradores = erros.Novo("acesso.apenas_administradores", "Apenas administradores podem executar esta ação")
 )
 
+// senhaFalsa é comparada quando o email não existe, para que o login leve o
+// mesmo tempo com ou sem usuário.
+var senhaFalsa = func() string {
+	hash, _ := seguranca.Hash("senha-de-um-usuario-que-nao-existe")
+	return string(hash)
+}()
+
 type UsuarioController struct {
-	Repositorio repositorios.UsuarioRepositorio
+	Repositorio       repositorios.UsuarioRepositorio
+	RepositorioTokens repositorios.TokensRepositorio
+	Emissor           *autenticacao.Emissor
+
+	RepositorioTentativas repositorios.TentativasLoginRepositorio
+	PoliticaDeBloqueio    autenticacao.PoliticaDeBloqueio
+
+	RepositorioDoisFatores repositorios.DoisFatoresRepositorio
+
+	// Enviador entrega o link de confirmação de email, que leva à página
+	// URLVerificacaoEmail do cliente com o token no parâmetro token.
+	Enviador            email.Enviador
+	URLVerificacaoEmail string
 }
 
-func NovoUsuarioController(repositorio repositorios.UsuarioRepositorio) *UsuarioController {
-	return &UsuarioController{Repositorio: repositorio}
+func NovoUsuarioController(repositorio repositorios.UsuarioRepositorio, repositorioTokens repositorios.TokensRepositorio, emissor *autenticacao.Emissor, repositorioTentativas repositorios.TentativasLoginRepositorio, politicaDeBloqueio autenticacao.PoliticaDeBloqueio, repositorioDoisFatores repositorios.DoisFatoresRepositorio, enviador email.Enviador, urlVerificacaoEmail string) *UsuarioController {
+	return &UsuarioController{
+		Repositorio:            repositorio,
+		RepositorioTokens:      repositorioTokens,
+		Emissor:                emissor,
+		RepositorioTentativas:  repositorioTentativas,
+		PoliticaDeBloqueio:     politicaDeBloqueio,
+		RepositorioDoisFatores: repositorioDoisFatores,
+		Enviador:               enviador,
+		URLVerificacaoEmail:    urlVerificacaoEmail,
+	}
 }
 
 func (uc *UsuarioController) Login(w http.ResponseWriter, r *http.Request) {
 	corpoRequest, erro := ioutil.ReadAll(r.Body)
 	if erro != nil {
-		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		respostas.Erro(w, r, http.StatusUnprocessableEntity, erro)
 		return
 	}
 
 	var usuario modelos.Usuario
 
 	if erro = json.Unmarshal(corpoRequest, &usuario); erro != nil {
-		respostas.Erro(w, http.StatusBadRequest, erro)
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	tentativa := modelos.TentativaLogin{
+		Email: strings.ToLower(strings.TrimSpace(usuario.Email)),
+		IP:    autenticacao.IPDoCliente(r),
+	}
+
+	tentativa, ok := uc.iniciarTentativa(w, r, tentativa)
+	if !ok {
+		return
+	}
+
+	usuarioSalvoNoBanco, erro := uc.Repositorio.BuscarPorEmail(r.Context(), usuario.Email)
+	if erro != nil && !errors.Is(erro, repositorios.ErrNaoEncontrado) {
+		uc.descartarTentativa(r, tentativa)
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	// Sem usuário, a senha é comparada com um hash qualquer para que o tempo
+	// de resposta não revele se o email existe.
+	senhaComHash := usuarioSalvoNoBanco.Senha
+	if erro != nil {
+		senhaComHash = senhaFalsa
+	}
+
+	if erroSenha := seguranca.VerificarSenha(senhaComHash, usuario.Senha); erro != nil || erroSenha != nil {
+		tentativa.UsuarioID = usuarioSalvoNoBanco.ID
+		uc.concluirTentativa(r, tentativa, modelos.ResultadoLoginFalha)
+		respostas.Erro(w, r, http.StatusUnauthorized, errCredenciaisInvalidas)
+		return
+	}
+
+	tentativa.UsuarioID = usuarioSalvoNoBanco.ID
+
+	// Com dois fatores, o sucesso só é registrado depois do código em /login/2fa.
+	if usuarioSalvoNoBanco.DoisFatoresAtivo {
+		uc.descartarTentativa(r, tentativa)
+		uc.responderDesafioDoisFatores(w, r, usuarioSalvoNoBanco)
+		return
+	}
+
+	uc.concluirTentativa(r, tentativa, modelos.ResultadoLoginSucesso)
+
+	familia, erro := autenticacao.NovaFamiliaDeTokens()
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusInternalServerError, erro)
+		return
+	}
+
+	tokens, erro := uc.emitirTokens(r.Context(), usuarioSalvoNoBanco.ID, usuarioSalvoNoBanco.VersaoToken, familia)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, tokens)
+}
+
+// RenovarToken troca um refresh token válido por um novo par de tokens. O
+// refresh token usado deixa de valer e o novo permanece na mesma família.
+func (uc *UsuarioController) RenovarToken(w http.ResponseWriter, r *http.Request) {
+	requisicao, erro := lerRequisicaoTokenAtualizacao(r)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	var tokens modelos.ParDeTokens
+
+	erro = uc.RepositorioTokens.Rotacionar(r.Context(), autenticacao.HashTokenAtualizacao(requisicao.TokenAtualizacao), func(consumido modelos.TokenAtualizacao, versaoToken uint64) (modelos.TokenAtualizacao, error) {
+		var novo modelos.TokenAtualizacao
+		tokens, novo, erro = uc.gerarTokens(consumido.UsuarioID, versaoToken, consumido.Familia)
+		return novo, erro
+	})
+	if erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
 		return
 	}
 
-	usuarioSalvoNoBanco, erro := uc.Repositorio.BuscarPorEmail(usuario.Email)
+	respostas.JSON(w, http.StatusOK, tokens)
+}
+
+// Logout revoga o refresh token informado e todos os que foram rotacionados a partir dele.
+func (uc *UsuarioController) Logout(w http.ResponseWriter, r *http.Request) {
+	requisicao, erro := lerRequisicaoTokenAtualizacao(r)
 	if erro != nil {
-		respostas.Erro(w, http.StatusNotFound, erro)
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	if erro = uc.RepositorioTokens.RevogarFamilia(r.Context(), autenticacao.HashTokenAtualizacao(requisicao.TokenAtualizacao)); erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
 		return
 	}
 
-	if erro = seguranca.VerificarSenha(usuarioSalvoNoBanco.Senha, usuario.Senha); erro != nil {
-		if erro != nil {
-			respostas.Erro(w, http.StatusUnauthorized, erro)
-			return
-		}
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// DesbloquearLogin zera as falhas de login da conta antes do fim do bloqueio.
+// Apenas administradores podem desbloquear contas.
+func (uc *UsuarioController) DesbloquearLogin(w http.ResponseWriter, r *http.Request) {
+	administradorID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusUnauthorized, erro)
+		return
 	}
 
-	token, erro := autenticacao.CriarToken(usuarioSalvoNoBanco.ID)
+	administrador, erro := uc.Repositorio.EhAdministrador(r.Context(), administradorID)
 	if erro != nil {
-		respostas.Erro(w, http.StatusInternalServerError, erro)
+		respostas.ErroDeDominio(w, r, erro)
 		return
 	}
+	if !administrador {
+		respostas.Erro(w, r, http.StatusForbidden, errApenasAdministradores)
+		return
+	}
+
+	usuarioID, erro := strconv.ParseUint(mux.Vars(r)["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	usuario, erro := uc.Repositorio.BuscarPorID(r.Context(), usuarioID)
+	if erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	if erro = uc.RepositorioTentativas.Registrar(r.Context(), modelos.TentativaLogin{
+		Email:         strings.ToLower(usuario.Email),
+		UsuarioID:     usuarioID,
+		IP:            autenticacao.IPDoCliente(r),
+		Resultado:     modelos.ResultadoLoginDesbloqueio,
+		ResponsavelID: administradorID,
+	}); erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// iniciarTentativa grava a tentativa como falha antes de a senha ou o código
+// serem conferidos e responde 429 quando a conta ou o IP estão bloqueados.
+// Gravada de antemão, a tentativa já conta para as requisições simultâneas da
+// mesma conta, que assim não passam juntas do limite de falhas.
+func (uc *UsuarioController) iniciarTentativa(w http.ResponseWriter, r *http.Request, tentativa modelos.TentativaLogin) (modelos.TentativaLogin, bool) {
+	tentativaID, falhasConta, falhasIP, erro := uc.RepositorioTentativas.Iniciar(r.Context(), tentativa, uc.PoliticaDeBloqueio.Janela)
+	if erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return tentativa, false
+	}
+	tentativa.ID = tentativaID
+
+	if espera := uc.PoliticaDeBloqueio.Espera(falhasConta, falhasIP); espera > 0 {
+		uc.concluirTentativa(r, tentativa, modelos.ResultadoLoginBloqueado)
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(espera.Seconds()))))
+		respostas.Erro(w, r, http.StatusTooManyRequests, errLoginBloqueado)
+		return tentativa, false
+	}
+
+	return tentativa, true
+}
+
+// concluirTentativa grava o resultado da tentativa na trilha de auditoria e
+// conta as falhas na métrica. Uma falha ao gravar não impede a resposta ao
+// cliente; a tentativa continua contada como falha.
+func (uc *UsuarioController) concluirTentativa(r *http.Request, tentativa modelos.TentativaLogin, resultado string) {
+	tentativa.Resultado = resultado
+	if resultado != modelos.ResultadoLoginSucesso {
+		metrics.FalhasLogin.WithLabelValues(resultado).Inc()
+	}
+
+	if erro := uc.RepositorioTentativas.Concluir(r.Context(), tentativa); erro != nil {
+		logs.DoContexto(r.Context()).Error("Erro ao registrar tentativa de login", "erro", erro)
+	}
+}
+
+// descartarTentativa apaga a tentativa que terminou sem julgamento, como a
+// senha correta de quem ainda precisa informar o código de dois fatores.
+func (uc *UsuarioController) descartarTentativa(r *http.Request, tentativa modelos.TentativaLogin) {
+	if erro := uc.RepositorioTentativas.Descartar(r.Context(), tentativa.ID); erro != nil {
+		logs.DoContexto(r.Context()).Error("Erro ao descartar tentativa de login", "erro", erro)
+	}
+}
+
+func (uc *UsuarioController) emitirTokens(ctx context.Context, usuarioID, versaoToken uint64, familia string) (modelos.ParDeTokens, error) {
+	tokens, tokenAtualizacao, erro := uc.gerarTokens(usuarioID, versaoToken, familia)
+	if erro != nil {
+		return modelos.ParDeTokens{}, erro
+	}
+
+	if erro = uc.RepositorioTokens.Criar(ctx, tokenAtualizacao); erro != nil {
+		return modelos.ParDeTokens{}, erro
+	}
+
+	return tokens, nil
+}
+
+// gerarTokens cria o par de tokens e o registro do refresh token, que cabe a
+// quem chama gravar.
+func (uc *UsuarioController) gerarTokens(usuarioID, versaoToken uint64, familia string) (modelos.ParDeTokens, modelos.TokenAtualizacao, error) {
+	tokenAcesso, erro := uc.Emissor.CriarToken(usuarioID, versaoToken)
+	if erro != nil {
+		return modelos.ParDeTokens{}, modelos.TokenAtualizacao{}, erro
+	}
+
+	tokenAtualizacao, hash, erro := autenticacao.CriarTokenAtualizacao()
+	if erro != nil {
+		return modelos.ParDeTokens{}, modelos.TokenAtualizacao{}, erro
+	}
+
+	tokens := modelos.ParDeTokens{
+		TokenAcesso:      tokenAcesso,
+		TokenAtualizacao: tokenAtualizacao,
+		TipoToken:        "Bearer",
+		ExpiraEm:         int64(autenticacao.DuracaoTokenAcesso.Seconds()),
+	}
+
+	return tokens, modelos.TokenAtualizacao{
+		UsuarioID: usuarioID,
+		Familia:   familia,
+		Hash:      hash,
+		Validade:  autenticacao.DuracaoTokenAtualizacao,
+	}, nil
+}
+
+func lerRequisicaoTokenAtualizacao(r *http.Request) (modelos.RequisicaoTokenAtualizacao, error) {
+	var requisicao modelos.RequisicaoTokenAtualizacao
+
+	corpoRequest, erro := ioutil.ReadAll(r.Body)
+	if erro != nil {
+		return requisicao, erro
+	}
+
+	if erro = json.Unmarshal(corpoRequest, &requisicao); erro != nil {
+		return requisicao, erro
+	}
+
+	if requisicao.TokenAtualizacao == "" {
+		return requisicao, erros.Novo("autenticacao.token_atualizacao_obrigatorio", "O token de atualização é obrigatório")
+	}
 
-	w.Write([]byte(token))
+	return requisicao, nil
 }
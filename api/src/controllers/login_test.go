@@ -1,16 +1,24 @@
 package controllers
 
 import (
+	"api/src/autenticacao"
 	"api/src/modelos"
+	"api/src/repositorios"
 	"api/src/seguranca"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -19,17 +27,212 @@ type MockRepositorio struct {
 	mock.Mock
 }
 
-func (m *MockRepositorio) BuscarPorEmail(email string) (modelos.Usuario, error) {
+func (m *MockRepositorio) Criar(ctx context.Context, usuario modelos.Usuario) (uint64, error) {
+	args := m.Called(usuario)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (m *MockRepositorio) BuscarPorID(ctx context.Context, usuarioID uint64) (modelos.Usuario, error) {
+	args := m.Called(usuarioID)
+	return args.Get(0).(modelos.Usuario), args.Error(1)
+}
+
+func (m *MockRepositorio) BuscarPorEmail(ctx context.Context, email string) (modelos.Usuario, error) {
 	args := m.Called(email)
 	return args.Get(0).(modelos.Usuario), args.Error(1)
 }
 
+func (m *MockRepositorio) Atualizar(ctx context.Context, usuarioID uint64, usuario modelos.Usuario) error {
+	args := m.Called(usuarioID, usuario)
+	return args.Error(0)
+}
+
+func (m *MockRepositorio) Deletar(ctx context.Context, usuarioID uint64) error {
+	args := m.Called(usuarioID)
+	return args.Error(0)
+}
+
+func (m *MockRepositorio) BuscarSenha(ctx context.Context, usuarioID uint64) (string, error) {
+	args := m.Called(usuarioID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockRepositorio) AtualizarSenha(ctx context.Context, usuarioID uint64, senha string) error {
+	args := m.Called(usuarioID, senha)
+	return args.Error(0)
+}
+
+func (m *MockRepositorio) BuscarVersaoToken(ctx context.Context, usuarioID uint64) (uint64, error) {
+	args := m.Called(usuarioID)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (m *MockRepositorio) EhAdministrador(ctx context.Context, usuarioID uint64) (bool, error) {
+	args := m.Called(usuarioID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepositorio) EmailVerificado(ctx context.Context, usuarioID uint64) (bool, error) {
+	args := m.Called(usuarioID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepositorio) VerificarEmail(ctx context.Context, usuarioID uint64, email string) error {
+	args := m.Called(usuarioID, email)
+	return args.Error(0)
+}
+
+type MockTokensRepositorio struct {
+	mock.Mock
+}
+
+func (m *MockTokensRepositorio) Criar(ctx context.Context, token modelos.TokenAtualizacao) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+// Rotacionar entrega o token consumido e a versão configurados no mock a
+// novoToken e grava o resultado em Criar, como a transação do repositório faria.
+func (m *MockTokensRepositorio) Rotacionar(ctx context.Context, hash string, novoToken func(consumido modelos.TokenAtualizacao, versaoToken uint64) (modelos.TokenAtualizacao, error)) error {
+	args := m.Called(hash)
+	if erro := args.Error(2); erro != nil {
+		return erro
+	}
+
+	novo, erro := novoToken(args.Get(0).(modelos.TokenAtualizacao), args.Get(1).(uint64))
+	if erro != nil {
+		return erro
+	}
+
+	return m.Criar(ctx, novo)
+}
+
+func (m *MockTokensRepositorio) RevogarFamilia(ctx context.Context, hash string) error {
+	args := m.Called(hash)
+	return args.Error(0)
+}
+
+type MockTentativasRepositorio struct {
+	mock.Mock
+}
+
+func (m *MockTentativasRepositorio) Iniciar(ctx context.Context, tentativa modelos.TentativaLogin, janela time.Duration) (uint64, modelos.FalhasDeLogin, modelos.FalhasDeLogin, error) {
+	args := m.Called(tentativa.Email, tentativa.IP)
+	return args.Get(0).(uint64), args.Get(1).(modelos.FalhasDeLogin), args.Get(2).(modelos.FalhasDeLogin), args.Error(3)
+}
+
+func (m *MockTentativasRepositorio) Concluir(ctx context.Context, tentativa modelos.TentativaLogin) error {
+	args := m.Called(tentativa)
+	return args.Error(0)
+}
+
+func (m *MockTentativasRepositorio) Descartar(ctx context.Context, tentativaID uint64) error {
+	args := m.Called(tentativaID)
+	return args.Error(0)
+}
+
+func (m *MockTentativasRepositorio) Registrar(ctx context.Context, tentativa modelos.TentativaLogin) error {
+	args := m.Called(tentativa)
+	return args.Error(0)
+}
+
+// tentativasEmMemoria é uma trilha de auditoria que serializa Iniciar como a
+// trava do banco, para os testes de concorrência que o mock não simula.
+type tentativasEmMemoria struct {
+	mu         sync.Mutex
+	tentativas []modelos.TentativaLogin
+}
+
+func (t *tentativasEmMemoria) Iniciar(ctx context.Context, tentativa modelos.TentativaLogin, janela time.Duration) (uint64, modelos.FalhasDeLogin, modelos.FalhasDeLogin, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	conta := modelos.FalhasDeLogin{Quantidade: t.contarFalhas(tentativa.Email)}
+
+	tentativa.ID = uint64(len(t.tentativas) + 1)
+	tentativa.Resultado = modelos.ResultadoLoginFalha
+	t.tentativas = append(t.tentativas, tentativa)
+
+	return tentativa.ID, conta, modelos.FalhasDeLogin{}, nil
+}
+
+func (t *tentativasEmMemoria) Concluir(ctx context.Context, tentativa modelos.TentativaLogin) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.tentativas[tentativa.ID-1].Resultado = tentativa.Resultado
+	return nil
+}
+
+func (t *tentativasEmMemoria) Descartar(ctx context.Context, tentativaID uint64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.tentativas[tentativaID-1].Resultado = ""
+	return nil
+}
+
+func (t *tentativasEmMemoria) Registrar(ctx context.Context, tentativa modelos.TentativaLogin) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.tentativas = append(t.tentativas, tentativa)
+	return nil
+}
+
+func (t *tentativasEmMemoria) contarFalhas(email string) int {
+	falhas := 0
+	for _, tentativa := range t.tentativas {
+		if tentativa.Email == email && tentativa.Resultado == modelos.ResultadoLoginFalha {
+			falhas++
+		}
+	}
+	return falhas
+}
+
+const chaveDeTeste = "chave-secreta-usada-apenas-nos-testes"
+
+func novoEmissorDeTeste() *autenticacao.Emissor {
+	chaveiro, _ := autenticacao.NovoChaveiro(autenticacao.NovaChaveHMAC([]byte(chaveDeTeste)))
+	return autenticacao.NovoEmissor(chaveiro, "social-network-api", "social-network")
+}
+
+var politicaDeTeste = autenticacao.PoliticaDeBloqueio{
+	MaxFalhas:      3,
+	MaxFalhasPorIP: 10,
+	Janela:         15 * time.Minute,
+	TempoBloqueio:  15 * time.Minute,
+	AtrasoInicial:  time.Second,
+	AtrasoMaximo:   10 * time.Second,
+}
+
 func setup(t *testing.T, repositorio *MockRepositorio) (*UsuarioController, *httptest.ResponseRecorder) {
-	controller := NovoUsuarioController(repositorio)
+	return setupComTokens(t, repositorio, new(MockTokensRepositorio))
+}
+
+// setupComTokens usa uma trilha de auditoria sem falhas anteriores.
+func setupComTokens(t *testing.T, repositorio *MockRepositorio, repositorioTokens *MockTokensRepositorio) (*UsuarioController, *httptest.ResponseRecorder) {
+	tentativas := new(MockTentativasRepositorio)
+	tentativas.On("Iniciar", mock.Anything, mock.Anything).Return(uint64(1), modelos.FalhasDeLogin{}, modelos.FalhasDeLogin{}, nil).Maybe()
+	tentativas.On("Concluir", mock.Anything).Return(nil).Maybe()
+	tentativas.On("Descartar", mock.Anything).Return(nil).Maybe()
+	tentativas.On("Registrar", mock.Anything).Return(nil).Maybe()
+
+	return setupComTentativas(t, repositorio, repositorioTokens, tentativas)
+}
+
+func setupComTentativas(t *testing.T, repositorio *MockRepositorio, repositorioTokens *MockTokensRepositorio, tentativas *MockTentativasRepositorio) (*UsuarioController, *httptest.ResponseRecorder) {
+	controller := NovoUsuarioController(repositorio, repositorioTokens, novoEmissorDeTeste(), tentativas, politicaDeTeste, new(MockDoisFatoresRepositorio), novoEnviadorDeTeste(), "https://social.teste/verificar-email")
 	recorder := httptest.NewRecorder()
 	return controller, recorder
 }
 
+// autenticarRequisicao simula a passagem pelo middleware de autenticação.
+func autenticarRequisicao(r *http.Request, usuarioID uint64) *http.Request {
+	principal := autenticacao.Principal{UsuarioID: usuarioID, Escopos: []string{autenticacao.EscopoUsuario}}
+	return r.WithContext(autenticacao.ComPrincipal(r.Context(), principal))
+}
+
 type errorReader struct{}
 
 func (e *errorReader) Read(p []byte) (int, error) {
@@ -50,7 +253,8 @@ func createLoginRequest(t *testing.T, email, senha string) *http.Request {
 
 func TestLogin_WhenUserWithSpecifiedCredentialsExists_ExpectedToken(t *testing.T) {
 	mockRepo := new(MockRepositorio)
-	controller, recorder := setup(t, mockRepo)
+	mockTokens := new(MockTokensRepositorio)
+	controller, recorder := setupComTokens(t, mockRepo, mockTokens)
 
 	email := "usuario@teste.com"
 	senha := "senhaCorreta"
@@ -59,27 +263,34 @@ func TestLogin_WhenUserWithSpecifiedCredentialsExists_ExpectedToken(t *testing.T
 	usuarioMock := modelos.Usuario{ID: 1, Email: email, Senha: string(hashSenha)}
 
 	mockRepo.On("BuscarPorEmail", email).Return(usuarioMock, nil)
+	mockTokens.On("Criar", mock.MatchedBy(func(token modelos.TokenAtualizacao) bool {
+		return token.UsuarioID == 1 && token.Hash != "" && token.Familia != ""
+	})).Return(nil)
 
 	req := createLoginRequest(t, email, senha)
 	controller.Login(recorder, req)
 
+	var tokens modelos.ParDeTokens
 	assert.Equal(t, http.StatusOK, recorder.Code)
-	assert.NotEmpty(t, recorder.Body.String())
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &tokens))
+	assert.NotEmpty(t, tokens.TokenAcesso)
+	assert.NotEmpty(t, tokens.TokenAtualizacao)
 	mockRepo.AssertExpectations(t)
+	mockTokens.AssertExpectations(t)
 }
 
-func TestLogin_WhenEmailWasNotFound_ExpectedNotFoundError(t *testing.T) {
+func TestLogin_WhenEmailWasNotFound_ExpectedUnauthorizedError(t *testing.T) {
 	mockRepo := new(MockRepositorio)
 	controller, recorder := setup(t, mockRepo)
 
 	email := "naoexiste@teste.com"
-	mockRepo.On("BuscarPorEmail", email).Return(modelos.Usuario{}, errors.New("usuário não encontrado"))
+	mockRepo.On("BuscarPorEmail", email).Return(modelos.Usuario{}, repositorios.ErrNaoEncontrado)
 
 	req := createLoginRequest(t, email, "qualquerSenha")
 	controller.Login(recorder, req)
 
-	assert.Equal(t, http.StatusNotFound, recorder.Code)
-	assert.Contains(t, recorder.Body.String(), "usuário não encontrado")
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Email ou senha inválidos")
 	mockRepo.AssertExpectations(t)
 }
 
@@ -98,7 +309,8 @@ func TestLogin_WhenPasswordIsIncorrect_ExpectedUnauthorizedError(t *testing.T) {
 	controller.Login(recorder, req)
 
 	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
-	assert.Contains(t, recorder.Body.String(), "hashedPassword is not the hash of the given password")
+	assert.Contains(t, recorder.Body.String(), "Email ou senha inválidos")
+	assert.NotContains(t, recorder.Body.String(), "hashedPassword")
 	mockRepo.AssertExpectations(t)
 }
 
@@ -114,3 +326,248 @@ func TestLogin_WhenThereIsAnErrorReadingRequestBody_ExpectedUnprocessableEntityE
 	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
 	assert.Contains(t, recorder.Body.String(), "Erro simulado na leitura dos dados")
 }
+
+func createRefreshRequest(t *testing.T, uri, tokenAtualizacao string) *http.Request {
+	body, _ := json.Marshal(modelos.RequisicaoTokenAtualizacao{TokenAtualizacao: tokenAtualizacao})
+	req, err := http.NewRequest(http.MethodPost, uri, bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	return req
+}
+
+func TestRefreshToken_WhenTokenIsValid_ExpectedRotatedTokens(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	mockTokens := new(MockTokensRepositorio)
+	controller, recorder := setupComTokens(t, mockRepo, mockTokens)
+
+	hash := autenticacao.HashTokenAtualizacao("token-atual")
+	mockTokens.On("Rotacionar", hash).Return(modelos.TokenAtualizacao{ID: 1, UsuarioID: 1, Familia: "familia", Hash: hash}, uint64(3), nil)
+	mockTokens.On("Criar", mock.MatchedBy(func(token modelos.TokenAtualizacao) bool {
+		return token.Familia == "familia" && token.Hash != hash && token.Validade == autenticacao.DuracaoTokenAtualizacao
+	})).Return(nil)
+
+	controller.RenovarToken(recorder, createRefreshRequest(t, "/login/refresh", "token-atual"))
+
+	var tokens modelos.ParDeTokens
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &tokens))
+	assert.NotEqual(t, "token-atual", tokens.TokenAtualizacao)
+
+	// A versão do token de acesso é a lida na transação da rotação.
+	req := httptest.NewRequest(http.MethodGet, "/publicacoes", nil)
+	req.Header.Set("Authorization", "Bearer "+tokens.TokenAcesso)
+	principal, err := controller.Emissor.ValidarToken(req)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), principal.VersaoToken)
+	mockRepo.AssertNotCalled(t, "BuscarVersaoToken", mock.Anything)
+	mockTokens.AssertExpectations(t)
+}
+
+func TestRefreshToken_WhenTokenWasAlreadyUsed_ExpectedUnauthorizedError(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	mockTokens := new(MockTokensRepositorio)
+	controller, recorder := setupComTokens(t, mockRepo, mockTokens)
+
+	hash := autenticacao.HashTokenAtualizacao("token-roubado")
+	mockTokens.On("Rotacionar", hash).Return(modelos.TokenAtualizacao{}, uint64(0), repositorios.ErrTokenAtualizacaoReutilizado)
+
+	controller.RenovarToken(recorder, createRefreshRequest(t, "/login/refresh", "token-roubado"))
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	mockTokens.AssertNotCalled(t, "Criar", mock.Anything)
+}
+
+func TestRefreshToken_WhenTokenIsMissing_ExpectedBadRequestError(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	controller, recorder := setup(t, mockRepo)
+
+	controller.RenovarToken(recorder, createRefreshRequest(t, "/login/refresh", ""))
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestLogout_WhenTokenIsPassed_ExpectedFamilyRevoked(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	mockTokens := new(MockTokensRepositorio)
+	controller, recorder := setupComTokens(t, mockRepo, mockTokens)
+
+	mockTokens.On("RevogarFamilia", autenticacao.HashTokenAtualizacao("token-atual")).Return(nil)
+
+	controller.Logout(recorder, createRefreshRequest(t, "/logout", "token-atual"))
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	mockTokens.AssertExpectations(t)
+}
+
+func TestLogin_WhenPasswordIsIncorrect_ExpectedFailureRecordedInAuditTrail(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	tentativas := new(MockTentativasRepositorio)
+	controller, recorder := setupComTentativas(t, mockRepo, new(MockTokensRepositorio), tentativas)
+
+	hashSenha, _ := seguranca.Hash("senhaCorreta")
+	mockRepo.On("BuscarPorEmail", "Usuario@Teste.com").Return(modelos.Usuario{ID: 7, Senha: string(hashSenha)}, nil)
+	tentativas.On("Iniciar", "usuario@teste.com", "192.0.2.1").Return(uint64(5), modelos.FalhasDeLogin{}, modelos.FalhasDeLogin{}, nil)
+	tentativas.On("Concluir", modelos.TentativaLogin{
+		ID:        5,
+		Email:     "usuario@teste.com",
+		UsuarioID: 7,
+		IP:        "192.0.2.1",
+		Resultado: modelos.ResultadoLoginFalha,
+	}).Return(nil)
+
+	req := createLoginRequest(t, "Usuario@Teste.com", "senhaErrada")
+	req.RemoteAddr = "192.0.2.1:41000"
+	controller.Login(recorder, req)
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	tentativas.AssertExpectations(t)
+}
+
+func TestLogin_WhenAccountIsLocked_ExpectedTooManyRequestsWithoutCheckingPassword(t *testing.T) {
+	for _, email := range []string{"usuario@teste.com", "naoexiste@teste.com"} {
+		t.Run(email, func(t *testing.T) {
+			mockRepo := new(MockRepositorio)
+			tentativas := new(MockTentativasRepositorio)
+			controller, recorder := setupComTentativas(t, mockRepo, new(MockTokensRepositorio), tentativas)
+
+			falhas := modelos.FalhasDeLogin{Quantidade: 3, DesdeUltima: 5 * time.Minute}
+			tentativas.On("Iniciar", email, mock.Anything).Return(uint64(5), falhas, modelos.FalhasDeLogin{}, nil)
+			tentativas.On("Concluir", mock.MatchedBy(func(tentativa modelos.TentativaLogin) bool {
+				return tentativa.ID == 5 && tentativa.Resultado == modelos.ResultadoLoginBloqueado
+			})).Return(nil)
+
+			controller.Login(recorder, createLoginRequest(t, email, "senhaCorreta"))
+
+			assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			assert.Equal(t, "600", recorder.Header().Get("Retry-After"))
+			assert.Contains(t, recorder.Body.String(), `"code":"autenticacao.login_bloqueado"`)
+			mockRepo.AssertNotCalled(t, "BuscarPorEmail", mock.Anything)
+			tentativas.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLogin_WhenWrongPasswordsArriveConcurrently_ExpectedNoMoreFailuresThanPolicyAllows(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	tentativas := new(tentativasEmMemoria)
+
+	politica := politicaDeTeste
+	politica.AtrasoInicial = 0
+	controller := NovoUsuarioController(mockRepo, new(MockTokensRepositorio), novoEmissorDeTeste(), tentativas, politica, new(MockDoisFatoresRepositorio), novoEnviadorDeTeste(), "https://social.teste/verificar-email")
+
+	hashSenha, _ := seguranca.Hash("senhaCorreta")
+	mockRepo.On("BuscarPorEmail", "usuario@teste.com").Return(modelos.Usuario{ID: 7, Senha: string(hashSenha)}, nil)
+
+	const requisicoes = 10
+	status := make([]int, requisicoes)
+
+	var grupo sync.WaitGroup
+	for i := range status {
+		grupo.Add(1)
+		go func() {
+			defer grupo.Done()
+			recorder := httptest.NewRecorder()
+			controller.Login(recorder, createLoginRequest(t, "usuario@teste.com", "senhaErrada"))
+			status[i] = recorder.Code
+		}()
+	}
+	grupo.Wait()
+
+	rejeitadas := 0
+	for _, codigo := range status {
+		if codigo == http.StatusUnauthorized {
+			rejeitadas++
+		}
+	}
+
+	assert.Equal(t, politica.MaxFalhas, tentativas.contarFalhas("usuario@teste.com"))
+	assert.Equal(t, politica.MaxFalhas, rejeitadas)
+}
+
+func TestLogin_WhenPoolHasASingleConnection_ExpectedLoginCompletes(t *testing.T) {
+	db, banco, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	hashSenha, _ := seguranca.Hash("senhaCorreta")
+	banco.ExpectBegin()
+	banco.ExpectExec("SELECT pg_advisory_xact_lock").WillReturnResult(sqlmock.NewResult(0, 0))
+	banco.ExpectQuery("WITH reinicio AS").
+		WillReturnRows(sqlmock.NewRows([]string{"falhas_conta", "desde_conta", "falhas_ip", "desde_ip"}).AddRow(0, 0, 0, 0))
+	banco.ExpectQuery("INSERT INTO tentativas_login").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	banco.ExpectCommit()
+	banco.ExpectQuery("SELECT id, email, senha").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "senha", "versao_token", "totp_ativo"}).AddRow(7, "usuario@teste.com", string(hashSenha), 0, false))
+	banco.ExpectExec("UPDATE tentativas_login").WillReturnResult(sqlmock.NewResult(0, 1))
+	banco.ExpectPrepare("INSERT INTO tokens_atualizacao").ExpectExec().WillReturnResult(sqlmock.NewResult(1, 1))
+
+	controller := NovoUsuarioController(repositorios.NovoRepositorioDeUsuarios(db), repositorios.NovoRepositorioDeTokens(db), novoEmissorDeTeste(), repositorios.NovoRepositorioDeTentativasLogin(db), politicaDeTeste, new(MockDoisFatoresRepositorio), novoEnviadorDeTeste(), "https://social.teste/verificar-email")
+	recorder := httptest.NewRecorder()
+
+	ctx, cancelar := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancelar()
+	controller.Login(recorder, createLoginRequest(t, "usuario@teste.com", "senhaCorreta").WithContext(ctx))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NoError(t, banco.ExpectationsWereMet())
+}
+
+func TestLogin_WhenPasswordIsCorrect_ExpectedSuccessRecordedInAuditTrail(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	mockTokens := new(MockTokensRepositorio)
+	tentativas := new(MockTentativasRepositorio)
+	controller, recorder := setupComTentativas(t, mockRepo, mockTokens, tentativas)
+
+	hashSenha, _ := seguranca.Hash("senhaCorreta")
+	mockRepo.On("BuscarPorEmail", "usuario@teste.com").Return(modelos.Usuario{ID: 7, Senha: string(hashSenha)}, nil)
+	mockTokens.On("Criar", mock.Anything).Return(nil)
+	tentativas.On("Iniciar", "usuario@teste.com", mock.Anything).Return(uint64(5), modelos.FalhasDeLogin{Quantidade: 1, DesdeUltima: time.Minute}, modelos.FalhasDeLogin{}, nil)
+	tentativas.On("Concluir", mock.MatchedBy(func(tentativa modelos.TentativaLogin) bool {
+		return tentativa.ID == 5 && tentativa.Resultado == modelos.ResultadoLoginSucesso && tentativa.UsuarioID == 7
+	})).Return(nil)
+
+	controller.Login(recorder, createLoginRequest(t, "usuario@teste.com", "senhaCorreta"))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	tentativas.AssertExpectations(t)
+}
+
+func createUnlockRequest(administradorID, usuarioID uint64) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/usuarios/"+strconv.FormatUint(usuarioID, 10)+"/desbloquear-login", nil)
+	req = mux.SetURLVars(req, map[string]string{"usuarioId": strconv.FormatUint(usuarioID, 10)})
+	return autenticarRequisicao(req, administradorID)
+}
+
+func TestDesbloquearLogin_WhenCallerIsNotAdministrator_ExpectedForbidden(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	tentativas := new(MockTentativasRepositorio)
+	controller, recorder := setupComTentativas(t, mockRepo, new(MockTokensRepositorio), tentativas)
+
+	mockRepo.On("EhAdministrador", uint64(2)).Return(false, nil)
+
+	controller.DesbloquearLogin(recorder, createUnlockRequest(2, 7))
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"code":"acesso.apenas_administradores"`)
+	tentativas.AssertNotCalled(t, "Registrar", mock.Anything)
+}
+
+func TestDesbloquearLogin_WhenCallerIsAdministrator_ExpectedUnlockRecorded(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	tentativas := new(MockTentativasRepositorio)
+	controller, recorder := setupComTentativas(t, mockRepo, new(MockTokensRepositorio), tentativas)
+
+	mockRepo.On("EhAdministrador", uint64(1)).Return(true, nil)
+	mockRepo.On("BuscarPorID", uint64(7)).Return(modelos.Usuario{ID: 7, Email: "Usuario@Teste.com"}, nil)
+	tentativas.On("Registrar", mock.MatchedBy(func(tentativa modelos.TentativaLogin) bool {
+		return tentativa.Resultado == modelos.ResultadoLoginDesbloqueio &&
+			tentativa.Email == "usuario@teste.com" &&
+			tentativa.UsuarioID == 7 &&
+			tentativa.ResponsavelID == 1
+	})).Return(nil)
+
+	controller.DesbloquearLogin(recorder, createUnlockRequest(1, 7))
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	tentativas.AssertExpectations(t)
+}
@@ -2,12 +2,12 @@ package controllers
 
 import (
 	"api/src/autenticacao"
+	"api/src/erros"
+	"api/src/logs"
 	"api/src/modelos"
 	"api/src/repositorios"
 	"api/src/respostas"
 	"encoding/json"
-	"errors"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strconv"
@@ -26,33 +26,33 @@ func NovoPublicacoesController(repositorio repositorios.PublicacoesRepositorio)
 func (pc *PublicacoesController) CriarPublicacao(w http.ResponseWriter, r *http.Request) {
 	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
 	if erro != nil {
-		respostas.Erro(w, http.StatusUnauthorized, erro)
+		respostas.Erro(w, r, http.StatusUnauthorized, erro)
 		return
 	}
 
 	corpoRequisicao, erro := ioutil.ReadAll(r.Body)
 	if erro != nil {
-		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		respostas.Erro(w, r, http.StatusUnprocessableEntity, erro)
 		return
 	}
 
 	var publicacao modelos.Publicacao
 	if erro := json.Unmarshal(corpoRequisicao, &publicacao); erro != nil {
-		respostas.Erro(w, http.StatusBadRequest, erro)
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
 		return
 	}
 
 	publicacao.AutorID = usuarioID
 
 	if erro = publicacao.Preparar(); erro != nil {
-		fmt.Println("Preparar: ", erro)
-		respostas.Erro(w, http.StatusBadRequest, erro)
+		logs.DoContexto(r.Context()).Debug("Publicação inválida", "erro", erro)
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
 		return
 	}
 
-	publicacao.ID, erro = pc.Repositorio.Criar(publicacao)
+	publicacao.ID, erro = pc.Repositorio.Criar(r.Context(), publicacao)
 	if erro != nil {
-		respostas.Erro(w, http.StatusInternalServerError, erro)
+		respostas.ErroDeDominio(w, r, erro)
 		return
 	}
 
@@ -62,13 +62,19 @@ func (pc *PublicacoesController) CriarPublicacao(w http.ResponseWriter, r *http.
 func (pc *PublicacoesController) BuscarPublicacoes(w http.ResponseWriter, r *http.Request) {
 	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
 	if erro != nil {
-		respostas.Erro(w, http.StatusUnauthorized, erro)
+		respostas.Erro(w, r, http.StatusUnauthorized, erro)
 		return
 	}
 
-	publicacoes, erro := pc.Repositorio.BuscarPublicacoes(usuarioID)
+	paginacao, erro := extrairPaginacao(r)
 	if erro != nil {
-		respostas.Erro(w, http.StatusInternalServerError, erro)
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	publicacoes, erro := pc.Repositorio.BuscarPublicacoes(r.Context(), usuarioID, paginacao)
+	if erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
 		return
 	}
 
@@ -76,16 +82,22 @@ func (pc *PublicacoesController) BuscarPublicacoes(w http.ResponseWriter, r *htt
 }
 
 func (pc *PublicacoesController) BuscarPublicacao(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusUnauthorized, erro)
+		return
+	}
+
 	parametros := mux.Vars(r)
 	publicacaoID, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
 	if erro != nil {
-		respostas.Erro(w, http.StatusBadRequest, erro)
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
 		return
 	}
 
-	publicacao, erro := pc.Repositorio.BuscarPorId(publicacaoID)
+	publicacao, erro := pc.Repositorio.BuscarPorId(r.Context(), publicacaoID, usuarioID)
 	if erro != nil {
-		respostas.Erro(w, http.StatusNotFound, erro)
+		respostas.ErroDeDominio(w, r, erro)
 		return
 	}
 
@@ -95,48 +107,48 @@ func (pc *PublicacoesController) BuscarPublicacao(w http.ResponseWriter, r *http
 func (pc *PublicacoesController) AtualizarPublicacao(w http.ResponseWriter, r *http.Request) {
 	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
 	if erro != nil {
-		respostas.Erro(w, http.StatusUnauthorized, erro)
+		respostas.Erro(w, r, http.StatusUnauthorized, erro)
 		return
 	}
 
 	parametros := mux.Vars(r)
 	publicacaoID, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
 	if erro != nil {
-		respostas.Erro(w, http.StatusBadRequest, erro)
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
 		return
 	}
 
-	publicacaoSalvaNoBanco, erro := pc.Repositorio.BuscarPorId(publicacaoID)
+	publicacaoSalvaNoBanco, erro := pc.Repositorio.BuscarPorId(r.Context(), publicacaoID, usuarioID)
 	if erro != nil {
-		fmt.Println("Buscar por id: ", erro)
-		respostas.Erro(w, http.StatusNotFound, erro)
+		logs.DoContexto(r.Context()).Debug("Falha ao buscar a publicação", "publicacao_id", publicacaoID, "erro", erro)
+		respostas.ErroDeDominio(w, r, erro)
 		return
 	}
 
 	if publicacaoSalvaNoBanco.AutorID != usuarioID {
-		respostas.Erro(w, http.StatusForbidden, errors.New("Não é possível atualizar uma publicação que não seja sua"))
+		respostas.Erro(w, r, http.StatusForbidden, erros.Novo("publicacao.atualizacao_proibida", "Não é possível atualizar uma publicação que não seja sua"))
 		return
 	}
 
 	corpoRequisicao, erro := ioutil.ReadAll(r.Body)
 	if erro != nil {
-		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		respostas.Erro(w, r, http.StatusUnprocessableEntity, erro)
 		return
 	}
 
 	var publicacao modelos.Publicacao
 	if erro := json.Unmarshal(corpoRequisicao, &publicacao); erro != nil {
-		respostas.Erro(w, http.StatusBadRequest, erro)
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
 		return
 	}
 
 	if erro = publicacao.Preparar(); erro != nil {
-		respostas.Erro(w, http.StatusBadRequest, erro)
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
 		return
 	}
-	erro = pc.Repositorio.Atualizar(publicacaoID, publicacao)
+	erro = pc.Repositorio.Atualizar(r.Context(), publicacaoID, publicacao)
 	if erro != nil {
-		respostas.Erro(w, http.StatusInternalServerError, erro)
+		respostas.ErroDeDominio(w, r, erro)
 		return
 	}
 
@@ -146,31 +158,31 @@ func (pc *PublicacoesController) AtualizarPublicacao(w http.ResponseWriter, r *h
 func (pc *PublicacoesController) DeletarPublicacao(w http.ResponseWriter, r *http.Request) {
 	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
 	if erro != nil {
-		respostas.Erro(w, http.StatusUnauthorized, erro)
+		respostas.Erro(w, r, http.StatusUnauthorized, erro)
 		return
 	}
 
 	parametros := mux.Vars(r)
 	publicacaoID, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
 	if erro != nil {
-		respostas.Erro(w, http.StatusBadRequest, erro)
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
 		return
 	}
 
-	publicacaoSalvaNoBanco, erro := pc.Repositorio.BuscarPorId(publicacaoID)
+	publicacaoSalvaNoBanco, erro := pc.Repositorio.BuscarPorId(r.Context(), publicacaoID, usuarioID)
 	if erro != nil {
-		respostas.Erro(w, http.StatusNotFound, erro)
+		respostas.ErroDeDominio(w, r, erro)
 		return
 	}
 
 	if publicacaoSalvaNoBanco.AutorID != usuarioID {
-		respostas.Erro(w, http.StatusForbidden, errors.New("Não é possível deletar uma publicação que não seja sua"))
+		respostas.Erro(w, r, http.StatusForbidden, erros.Novo("publicacao.exclusao_proibida", "Não é possível deletar uma publicação que não seja sua"))
 		return
 	}
 
-	erro = pc.Repositorio.DeletarPublicacao(publicacaoID)
+	erro = pc.Repositorio.DeletarPublicacao(r.Context(), publicacaoID)
 	if erro != nil {
-		respostas.Erro(w, http.StatusInternalServerError, erro)
+		respostas.ErroDeDominio(w, r, erro)
 		return
 	}
 
@@ -178,16 +190,28 @@ func (pc *PublicacoesController) DeletarPublicacao(w http.ResponseWriter, r *htt
 }
 
 func (pc *PublicacoesController) BuscarPublicacoesPorUsuario(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusUnauthorized, erro)
+		return
+	}
+
 	parametros := mux.Vars(r)
-	publicacaoID, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	autorID, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	paginacao, erro := extrairPaginacao(r)
 	if erro != nil {
-		respostas.Erro(w, http.StatusBadRequest, erro)
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
 		return
 	}
 
-	publicacoes, erro := pc.Repositorio.BuscarPorUsuario(publicacaoID)
+	publicacoes, erro := pc.Repositorio.BuscarPorUsuario(r.Context(), autorID, usuarioID, paginacao)
 	if erro != nil {
-		respostas.Erro(w, http.StatusInternalServerError, erro)
+		respostas.ErroDeDominio(w, r, erro)
 		return
 	}
 
@@ -195,16 +219,22 @@ func (pc *PublicacoesController) BuscarPublicacoesPorUsuario(w http.ResponseWrit
 }
 
 func (pc *PublicacoesController) CurtirPublicacao(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusUnauthorized, erro)
+		return
+	}
+
 	parametros := mux.Vars(r)
 	publicacaoID, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
 	if erro != nil {
-		respostas.Erro(w, http.StatusBadRequest, erro)
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
 		return
 	}
 
-	erro = pc.Repositorio.Curtir(publicacaoID)
+	erro = pc.Repositorio.Curtir(r.Context(), publicacaoID, usuarioID)
 	if erro != nil {
-		respostas.Erro(w, http.StatusInternalServerError, erro)
+		respostas.ErroDeDominio(w, r, erro)
 		return
 	}
 
@@ -212,18 +242,46 @@ func (pc *PublicacoesController) CurtirPublicacao(w http.ResponseWriter, r *http
 }
 
 func (pc *PublicacoesController) DescurtirPublicacao(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusUnauthorized, erro)
+		return
+	}
+
 	parametros := mux.Vars(r)
 	publicacaoID, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
 	if erro != nil {
-		respostas.Erro(w, http.StatusBadRequest, erro)
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
 		return
 	}
 
-	erro = pc.Repositorio.Descurtir(publicacaoID)
+	erro = pc.Repositorio.Descurtir(r.Context(), publicacaoID, usuarioID)
 	if erro != nil {
-		respostas.Erro(w, http.StatusInternalServerError, erro)
+		respostas.ErroDeDominio(w, r, erro)
 		return
 	}
 
 	respostas.JSON(w, http.StatusNoContent, nil)
 }
+
+func (pc *PublicacoesController) BuscarCurtidas(w http.ResponseWriter, r *http.Request) {
+	parametros := mux.Vars(r)
+	publicacaoID, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	usuarios, erro := pc.Repositorio.BuscarCurtidas(r.Context(), publicacaoID)
+	if erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, usuarios)
+}
+
+func extrairPaginacao(r *http.Request) (modelos.Paginacao, error) {
+	query := r.URL.Query()
+	return modelos.NovaPaginacao(query.Get("limit"), query.Get("cursor"))
+}
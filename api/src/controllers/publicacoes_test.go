@@ -1,9 +1,11 @@
 package controllers
 
 import (
-	"api/src/autenticacao"
 	"api/src/modelos"
+	"api/src/repositorios"
+	"api/src/respostas"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +13,7 @@ import (
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
@@ -21,46 +24,51 @@ type MockPublicacoesRepositorio struct {
 	mock.Mock
 }
 
-func (m *MockPublicacoesRepositorio) Criar(publicacao modelos.Publicacao) (uint64, error) {
+func (m *MockPublicacoesRepositorio) Criar(ctx context.Context, publicacao modelos.Publicacao) (uint64, error) {
 	args := m.Called(publicacao)
 	return args.Get(0).(uint64), args.Error(1)
 }
 
-func (m *MockPublicacoesRepositorio) BuscarPorId(publicacaoID uint64) (modelos.Publicacao, error) {
-	args := m.Called(publicacaoID)
+func (m *MockPublicacoesRepositorio) BuscarPorId(ctx context.Context, publicacaoID, usuarioID uint64) (modelos.Publicacao, error) {
+	args := m.Called(publicacaoID, usuarioID)
 	return args.Get(0).(modelos.Publicacao), args.Error(1)
 }
 
-func (m *MockPublicacoesRepositorio) BuscarPublicacoes(usuarioID uint64) ([]modelos.Publicacao, error) {
-	args := m.Called(usuarioID)
-	return args.Get(0).([]modelos.Publicacao), args.Error(1)
+func (m *MockPublicacoesRepositorio) BuscarPublicacoes(ctx context.Context, usuarioID uint64, paginacao modelos.Paginacao) (modelos.PaginaDePublicacoes, error) {
+	args := m.Called(usuarioID, paginacao)
+	return args.Get(0).(modelos.PaginaDePublicacoes), args.Error(1)
 }
 
-func (m *MockPublicacoesRepositorio) Atualizar(id uint64, publicacao modelos.Publicacao) error {
+func (m *MockPublicacoesRepositorio) Atualizar(ctx context.Context, id uint64, publicacao modelos.Publicacao) error {
 	args := m.Called(id, publicacao)
 	return args.Error(0)
 }
 
-func (m *MockPublicacoesRepositorio) DeletarPublicacao(id uint64) error {
+func (m *MockPublicacoesRepositorio) DeletarPublicacao(ctx context.Context, id uint64) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockPublicacoesRepositorio) BuscarPorUsuario(usuarioID uint64) ([]modelos.Publicacao, error) {
-	args := m.Called(usuarioID)
-	return args.Get(0).([]modelos.Publicacao), args.Error(1)
+func (m *MockPublicacoesRepositorio) BuscarPorUsuario(ctx context.Context, autorID, usuarioID uint64, paginacao modelos.Paginacao) (modelos.PaginaDePublicacoes, error) {
+	args := m.Called(autorID, usuarioID, paginacao)
+	return args.Get(0).(modelos.PaginaDePublicacoes), args.Error(1)
 }
 
-func (m *MockPublicacoesRepositorio) Curtir(publicacaoID uint64) error {
-	args := m.Called(publicacaoID)
+func (m *MockPublicacoesRepositorio) Curtir(ctx context.Context, publicacaoID, usuarioID uint64) error {
+	args := m.Called(publicacaoID, usuarioID)
 	return args.Error(0)
 }
 
-func (m *MockPublicacoesRepositorio) Descurtir(publicacaoID uint64) error {
-	args := m.Called(publicacaoID)
+func (m *MockPublicacoesRepositorio) Descurtir(ctx context.Context, publicacaoID, usuarioID uint64) error {
+	args := m.Called(publicacaoID, usuarioID)
 	return args.Error(0)
 }
 
+func (m *MockPublicacoesRepositorio) BuscarCurtidas(ctx context.Context, publicacaoID uint64) ([]modelos.Usuario, error) {
+	args := m.Called(publicacaoID)
+	return args.Get(0).([]modelos.Usuario), args.Error(1)
+}
+
 func setupConfig(t *testing.T, repositorio *MockPublicacoesRepositorio) (*PublicacoesController, *httptest.ResponseRecorder) {
 	controller := NovoPublicacoesController(repositorio)
 	recorder := httptest.NewRecorder()
@@ -75,13 +83,9 @@ func TestCreatePublication_WhenAllFieldsArePassed_ExpectedNewPublication(t *test
 	publicacao := modelos.Publicacao{Titulo: "Teste", Conteudo: "Conteudo", AutorID: usuarioID}
 	mockRepo.On("Criar", publicacao).Return(uint64(1), nil)
 
-	tokenString, err := autenticacao.CriarToken(usuarioID)
-	if err != nil {
-		t.Fatalf("Erro ao gerar token JWT: %v", err)
-	}
 	body, _ := json.Marshal(publicacao)
 	r := httptest.NewRequest("POST", "/publicacoes", bytes.NewReader(body))
-	r.Header.Set("Authorization", "Bearer "+tokenString)
+	r = autenticarRequisicao(r, usuarioID)
 
 	controller.CriarPublicacao(recorder, r)
 
@@ -96,13 +100,9 @@ func TestCreatePublication_WhenNoTitleIsPassed_ExpectedBadRequestError(t *testin
 	usuarioID := uint64(1)
 	publicacao := modelos.Publicacao{}
 
-	tokenString, err := autenticacao.CriarToken(usuarioID)
-	if err != nil {
-		t.Fatalf("Erro ao gerar token JWT: %v", err)
-	}
 	body, _ := json.Marshal(publicacao)
 	r := httptest.NewRequest("POST", "/publicacoes", bytes.NewReader(body))
-	r.Header.Set("Authorization", "Bearer "+tokenString)
+	r = autenticarRequisicao(r, usuarioID)
 
 	controller.CriarPublicacao(recorder, r)
 
@@ -118,13 +118,9 @@ func TestCreatePublication_WhenCommandFailsInDatabase_ExpectedInternalServerErro
 	publicacao := modelos.Publicacao{Titulo: "Teste", Conteudo: "Conteudo", AutorID: usuarioID}
 	mockRepo.On("Criar", publicacao).Return(uint64(0), errors.New("erro ao criar publicação"))
 
-	tokenString, err := autenticacao.CriarToken(usuarioID)
-	if err != nil {
-		t.Fatalf("Erro ao gerar token JWT: %v", err)
-	}
 	body, _ := json.Marshal(publicacao)
 	r := httptest.NewRequest("POST", "/publicacoes", bytes.NewReader(body))
-	r.Header.Set("Authorization", "Bearer "+tokenString)
+	r = autenticarRequisicao(r, usuarioID)
 
 	controller.CriarPublicacao(recorder, r)
 
@@ -145,16 +141,12 @@ func TestUpdatePublication_WhenAllFieldsArePassed_ExpectedUpdatedPublication(t *
 		Conteudo: "Conteúdo da publicação",
 		AutorID:  1,
 	}
-	mockRepo.On("BuscarPorId", uint64(1)).Return(publicacaoExistente, nil)
+	mockRepo.On("BuscarPorId", uint64(1), usuarioID).Return(publicacaoExistente, nil)
 	mockRepo.On("Atualizar", uint64(1), publicacao).Return(nil)
 
-	tokenString, err := autenticacao.CriarToken(usuarioID)
-	if err != nil {
-		t.Fatalf("Erro ao gerar token JWT: %v", err)
-	}
 	body, _ := json.Marshal(publicacao)
 	r := httptest.NewRequest("PUT", "/publicacoes/"+publicacaoID, bytes.NewReader(body))
-	r.Header.Set("Authorization", "Bearer "+tokenString)
+	r = autenticarRequisicao(r, usuarioID)
 	vars := map[string]string{"publicacaoId": publicacaoID}
 	r = mux.SetURLVars(r, vars)
 
@@ -172,15 +164,11 @@ func TestUpdatePublication_WhenPublicationDoesNotExist_ExpectedNotFoundError(t *
 	usuarioID := uint64(1)
 	publicacao := modelos.Publicacao{Titulo: "Atualizado", Conteudo: "Novo Conteudo"}
 	publicacaoVazia := modelos.Publicacao{Titulo: "Atualizado", Conteudo: "Novo Conteudo"}
-	mockRepo.On("BuscarPorId", uint64(1)).Return(publicacaoVazia, errors.New("publicação não encontrada"))
+	mockRepo.On("BuscarPorId", uint64(1), usuarioID).Return(publicacaoVazia, repositorios.ErrNaoEncontrado)
 
-	tokenString, err := autenticacao.CriarToken(usuarioID)
-	if err != nil {
-		t.Fatalf("Erro ao gerar token JWT: %v", err)
-	}
 	body, _ := json.Marshal(publicacao)
 	r := httptest.NewRequest("PUT", "/publicacoes/"+publicacaoID, bytes.NewReader(body))
-	r.Header.Set("Authorization", "Bearer "+tokenString)
+	r = autenticarRequisicao(r, usuarioID)
 	vars := map[string]string{"publicacaoId": publicacaoID}
 	r = mux.SetURLVars(r, vars)
 
@@ -203,16 +191,12 @@ func TestUpdatePublication_WhenCommandFailsInDatabase_ExpectedInternalServerErro
 		Conteudo: "Conteúdo da publicação",
 		AutorID:  1,
 	}
-	mockRepo.On("BuscarPorId", uint64(1)).Return(publicacaoExistente, nil)
+	mockRepo.On("BuscarPorId", uint64(1), usuarioID).Return(publicacaoExistente, nil)
 	mockRepo.On("Atualizar", uint64(1), publicacao).Return(errors.New("erro atualizando a publicação"))
 
-	tokenString, err := autenticacao.CriarToken(usuarioID)
-	if err != nil {
-		t.Fatalf("Erro ao gerar token JWT: %v", err)
-	}
 	body, _ := json.Marshal(publicacao)
 	r := httptest.NewRequest("PUT", "/publicacoes/"+publicacaoID, bytes.NewReader(body))
-	r.Header.Set("Authorization", "Bearer "+tokenString)
+	r = autenticarRequisicao(r, usuarioID)
 	vars := map[string]string{"publicacaoId": publicacaoID}
 	r = mux.SetURLVars(r, vars)
 
@@ -235,14 +219,10 @@ func TestDeletePublication_WhenPublicationExists_ExpectedDeletedPublication(t *t
 	}
 	usuarioID := uint64(1)
 	mockRepo.On("DeletarPublicacao", uint64(1)).Return(nil)
-	mockRepo.On("BuscarPorId", uint64(1)).Return(publicacaoExistente, nil)
+	mockRepo.On("BuscarPorId", uint64(1), usuarioID).Return(publicacaoExistente, nil)
 
-	tokenString, err := autenticacao.CriarToken(usuarioID)
-	if err != nil {
-		t.Fatalf("Erro ao gerar token JWT: %v", err)
-	}
 	r := httptest.NewRequest("DELETE", "/publicacoes/"+publicacaoID, nil)
-	r.Header.Set("Authorization", "Bearer "+tokenString)
+	r = autenticarRequisicao(r, usuarioID)
 	vars := map[string]string{"publicacaoId": publicacaoID}
 	r = mux.SetURLVars(r, vars)
 
@@ -259,14 +239,10 @@ func TestDeletePublication_WhenPublicationDoesNotExist_ExpectedNotFoundError(t *
 	usuarioID := uint64(1)
 	publicacaoID := "1"
 	publicacaoVazia := modelos.Publicacao{Titulo: "Atualizado", Conteudo: "Novo Conteudo"}
-	mockRepo.On("BuscarPorId", uint64(1)).Return(publicacaoVazia, errors.New("publicação não encontrada"))
+	mockRepo.On("BuscarPorId", uint64(1), usuarioID).Return(publicacaoVazia, repositorios.ErrNaoEncontrado)
 
-	tokenString, err := autenticacao.CriarToken(usuarioID)
-	if err != nil {
-		t.Fatalf("Erro ao gerar token JWT: %v", err)
-	}
 	r := httptest.NewRequest("DELETE", "/publicacoes/"+publicacaoID, nil)
-	r.Header.Set("Authorization", "Bearer "+tokenString)
+	r = autenticarRequisicao(r, usuarioID)
 	vars := map[string]string{"publicacaoId": publicacaoID}
 	r = mux.SetURLVars(r, vars)
 
@@ -289,14 +265,10 @@ func TestDeletePublication_WhenCommandFailsInDatabase_ExpectedInternalServerErro
 		AutorID:  1,
 	}
 	mockRepo.On("DeletarPublicacao", uint64(1)).Return(errors.New("erro ao deletar a publicacao"))
-	mockRepo.On("BuscarPorId", uint64(1)).Return(publicacaoExistente, nil)
+	mockRepo.On("BuscarPorId", uint64(1), usuarioID).Return(publicacaoExistente, nil)
 
-	tokenString, err := autenticacao.CriarToken(usuarioID)
-	if err != nil {
-		t.Fatalf("Erro ao gerar token JWT: %v", err)
-	}
 	r := httptest.NewRequest("DELETE", "/publicacoes/"+publicacaoID, nil)
-	r.Header.Set("Authorization", "Bearer "+tokenString)
+	r = autenticarRequisicao(r, usuarioID)
 	vars := map[string]string{"publicacaoId": publicacaoID}
 	r = mux.SetURLVars(r, vars)
 
@@ -312,9 +284,10 @@ func TestFindPublicationById_WhenPublicationExists_ExpectedPublicationReturned(t
 
 	publicacaoID := uint64(1)
 	publicacao := modelos.Publicacao{ID: publicacaoID, Titulo: "Teste", Conteudo: "Conteudo", AutorID: 1}
-	mockRepo.On("BuscarPorId", publicacaoID).Return(publicacao, nil)
+	mockRepo.On("BuscarPorId", publicacaoID, uint64(1)).Return(publicacao, nil)
 
 	r := httptest.NewRequest("GET", fmt.Sprintf("/publicacoes/%d", publicacaoID), nil)
+	r = autenticarRequisicao(r, 1)
 	vars := map[string]string{"publicacaoId": strconv.FormatUint(publicacaoID, 10)}
 	r = mux.SetURLVars(r, vars)
 
@@ -329,9 +302,10 @@ func TestFindPublicationById_WhenPublicationDoesNotExist_ExpectedNotFoundError(t
 	controller, recorder := setupConfig(t, mockRepo)
 
 	publicacaoID := uint64(1)
-	mockRepo.On("BuscarPorId", publicacaoID).Return(modelos.Publicacao{}, errors.New("publicação não encontrada"))
+	mockRepo.On("BuscarPorId", publicacaoID, uint64(1)).Return(modelos.Publicacao{}, repositorios.ErrNaoEncontrado)
 
 	r := httptest.NewRequest("GET", fmt.Sprintf("/publicacoes/%d", publicacaoID), nil)
+	r = autenticarRequisicao(r, 1)
 	vars := map[string]string{"publicacaoId": strconv.FormatUint(publicacaoID, 10)}
 	r = mux.SetURLVars(r, vars)
 
@@ -346,10 +320,11 @@ func TestSearchPublicationByUser_WhenUserHasPublications_ExpectedPublicationsRet
 	controller, recorder := setupConfig(t, mockRepo)
 
 	usuarioID := uint64(1)
-	publicacoes := []modelos.Publicacao{{ID: 1, Titulo: "Teste", Conteudo: "Conteudo", AutorID: usuarioID}}
-	mockRepo.On("BuscarPorUsuario", usuarioID).Return(publicacoes, nil)
+	pagina := modelos.PaginaDePublicacoes{Publicacoes: []modelos.Publicacao{{ID: 1, Titulo: "Teste", Conteudo: "Conteudo", AutorID: usuarioID}}}
+	mockRepo.On("BuscarPorUsuario", usuarioID, uint64(2), modelos.Paginacao{Limite: modelos.LimitePadrao}).Return(pagina, nil)
 
 	r := httptest.NewRequest("GET", fmt.Sprintf("/usuarios/%d/publicacoes", usuarioID), nil)
+	r = autenticarRequisicao(r, 2)
 	vars := map[string]string{"usuarioId": strconv.FormatUint(usuarioID, 10)}
 	r = mux.SetURLVars(r, vars)
 
@@ -364,9 +339,10 @@ func TestSearchPublicationByUser_WhenUserHasNoPublications_ExpectedEmptyList(t *
 	controller, recorder := setupConfig(t, mockRepo)
 
 	usuarioID := uint64(1)
-	mockRepo.On("BuscarPorUsuario", usuarioID).Return([]modelos.Publicacao{}, nil)
+	mockRepo.On("BuscarPorUsuario", usuarioID, uint64(2), modelos.Paginacao{Limite: modelos.LimitePadrao}).Return(modelos.PaginaDePublicacoes{}, nil)
 
 	r := httptest.NewRequest("GET", fmt.Sprintf("/usuarios/%d/publicacoes", usuarioID), nil)
+	r = autenticarRequisicao(r, 2)
 	vars := map[string]string{"usuarioId": strconv.FormatUint(usuarioID, 10)}
 	r = mux.SetURLVars(r, vars)
 
@@ -381,15 +357,11 @@ func TestSearchPublications_WhenHasPublications_ExpectedPublicationsReturned(t *
 	controller, recorder := setupConfig(t, mockRepo)
 
 	usuarioID := uint64(1)
-	publicacoes := []modelos.Publicacao{{ID: 1, Titulo: "Teste", Conteudo: "Conteudo", AutorID: usuarioID}}
-	mockRepo.On("BuscarPublicacoes", usuarioID).Return(publicacoes, nil)
+	pagina := modelos.PaginaDePublicacoes{Publicacoes: []modelos.Publicacao{{ID: 1, Titulo: "Teste", Conteudo: "Conteudo", AutorID: usuarioID}}}
+	mockRepo.On("BuscarPublicacoes", usuarioID, modelos.Paginacao{Limite: modelos.LimitePadrao}).Return(pagina, nil)
 
-	tokenString, err := autenticacao.CriarToken(usuarioID)
-	if err != nil {
-		t.Fatalf("Erro ao gerar token JWT: %v", err)
-	}
 	r := httptest.NewRequest("GET", fmt.Sprintf("/publicacoes"), nil)
-	r.Header.Set("Authorization", "Bearer "+tokenString)
+	r = autenticarRequisicao(r, usuarioID)
 	vars := map[string]string{"usuarioId": strconv.FormatUint(usuarioID, 10)}
 	r = mux.SetURLVars(r, vars)
 
@@ -404,14 +376,10 @@ func TestSearchPublications_WhenUserHasNoPublications_ExpectedEmptyList(t *testi
 	controller, recorder := setupConfig(t, mockRepo)
 
 	usuarioID := uint64(1)
-	mockRepo.On("BuscarPublicacoes", usuarioID).Return([]modelos.Publicacao{}, nil)
+	mockRepo.On("BuscarPublicacoes", usuarioID, modelos.Paginacao{Limite: modelos.LimitePadrao}).Return(modelos.PaginaDePublicacoes{}, nil)
 
-	tokenString, err := autenticacao.CriarToken(usuarioID)
-	if err != nil {
-		t.Fatalf("Erro ao gerar token JWT: %v", err)
-	}
 	r := httptest.NewRequest("GET", fmt.Sprintf("/publicacoes"), nil)
-	r.Header.Set("Authorization", "Bearer "+tokenString)
+	r = autenticarRequisicao(r, usuarioID)
 	vars := map[string]string{"usuarioId": strconv.FormatUint(usuarioID, 10)}
 	r = mux.SetURLVars(r, vars)
 
@@ -426,9 +394,10 @@ func TestLikePublication_WhenPublicationExists_ExpectedSuccess(t *testing.T) {
 	controller, recorder := setupConfig(t, mockRepo)
 
 	publicacaoID := uint64(1)
-	mockRepo.On("Curtir", publicacaoID).Return(nil)
+	mockRepo.On("Curtir", publicacaoID, uint64(1)).Return(nil)
 
 	r := httptest.NewRequest("POST", fmt.Sprintf("/publicacoes/%d/curtir", publicacaoID), nil)
+	r = autenticarRequisicao(r, 1)
 	vars := map[string]string{"publicacaoId": strconv.FormatUint(publicacaoID, 10)}
 	r = mux.SetURLVars(r, vars)
 
@@ -443,9 +412,10 @@ func TestLikePublication_WhenDatabaseFails_ExpectedInternalServerError(t *testin
 	controller, recorder := setupConfig(t, mockRepo)
 
 	publicacaoID := uint64(1)
-	mockRepo.On("Curtir", publicacaoID).Return(errors.New("erro ao curtir a publicação"))
+	mockRepo.On("Curtir", publicacaoID, uint64(1)).Return(errors.New("erro ao curtir a publicação"))
 
 	r := httptest.NewRequest("POST", fmt.Sprintf("/publicacoes/%d/curtir", publicacaoID), nil)
+	r = autenticarRequisicao(r, 1)
 	vars := map[string]string{"publicacaoId": strconv.FormatUint(publicacaoID, 10)}
 	r = mux.SetURLVars(r, vars)
 
@@ -460,9 +430,10 @@ func TestUnlikePublication_WhenPublicationExists_ExpectedSuccess(t *testing.T) {
 	controller, recorder := setupConfig(t, mockRepo)
 
 	publicacaoID := uint64(1)
-	mockRepo.On("Descurtir", publicacaoID).Return(nil)
+	mockRepo.On("Descurtir", publicacaoID, uint64(1)).Return(nil)
 
 	r := httptest.NewRequest("POST", fmt.Sprintf("/publicacoes/%d/descurtir", publicacaoID), nil)
+	r = autenticarRequisicao(r, 1)
 	vars := map[string]string{"publicacaoId": strconv.FormatUint(publicacaoID, 10)}
 	r = mux.SetURLVars(r, vars)
 
@@ -477,9 +448,10 @@ func TestUnlikePublication_WhenDatabaseFails_ExpectedInternalServerError(t *test
 	controller, recorder := setupConfig(t, mockRepo)
 
 	publicacaoID := uint64(1)
-	mockRepo.On("Descurtir", publicacaoID).Return(errors.New("erro ao descurtir a publicação"))
+	mockRepo.On("Descurtir", publicacaoID, uint64(1)).Return(errors.New("erro ao descurtir a publicação"))
 
 	r := httptest.NewRequest("POST", fmt.Sprintf("/publicacoes/%d/descurtir", publicacaoID), nil)
+	r = autenticarRequisicao(r, 1)
 	vars := map[string]string{"publicacaoId": strconv.FormatUint(publicacaoID, 10)}
 	r = mux.SetURLVars(r, vars)
 
@@ -488,3 +460,176 @@ func TestUnlikePublication_WhenDatabaseFails_ExpectedInternalServerError(t *test
 	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestSearchLikes_WhenPublicationHasLikes_ExpectedUsersReturned(t *testing.T) {
+	mockRepo := new(MockPublicacoesRepositorio)
+	controller, recorder := setupConfig(t, mockRepo)
+
+	publicacaoID := uint64(1)
+	usuarios := []modelos.Usuario{{ID: 2, Nome: "Usuário 2", Nick: "usuario_2"}}
+	mockRepo.On("BuscarCurtidas", publicacaoID).Return(usuarios, nil)
+
+	r := httptest.NewRequest("GET", fmt.Sprintf("/publicacoes/%d/curtidas", publicacaoID), nil)
+	vars := map[string]string{"publicacaoId": strconv.FormatUint(publicacaoID, 10)}
+	r = mux.SetURLVars(r, vars)
+
+	controller.BuscarCurtidas(recorder, r)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "usuario_2")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSearchLikes_WhenDatabaseFails_ExpectedInternalServerError(t *testing.T) {
+	mockRepo := new(MockPublicacoesRepositorio)
+	controller, recorder := setupConfig(t, mockRepo)
+
+	publicacaoID := uint64(1)
+	mockRepo.On("BuscarCurtidas", publicacaoID).Return([]modelos.Usuario{}, errors.New("erro ao buscar curtidas"))
+
+	r := httptest.NewRequest("GET", fmt.Sprintf("/publicacoes/%d/curtidas", publicacaoID), nil)
+	vars := map[string]string{"publicacaoId": strconv.FormatUint(publicacaoID, 10)}
+	r = mux.SetURLVars(r, vars)
+
+	controller.BuscarCurtidas(recorder, r)
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSearchPublications_WhenCursorIsPassed_ExpectedNextPageRequested(t *testing.T) {
+	mockRepo := new(MockPublicacoesRepositorio)
+	controller, recorder := setupConfig(t, mockRepo)
+
+	usuarioID := uint64(1)
+	ultimaPublicacao := modelos.Publicacao{ID: 7, CriadoEm: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}
+	paginacaoEsperada := modelos.Paginacao{Limite: 5, CriadaEm: ultimaPublicacao.CriadoEm, ID: ultimaPublicacao.ID}
+	mockRepo.On("BuscarPublicacoes", usuarioID, paginacaoEsperada).Return(modelos.PaginaDePublicacoes{}, nil)
+
+	r := httptest.NewRequest("GET", "/publicacoes?limit=5&cursor="+modelos.CodificarCursor(ultimaPublicacao), nil)
+	r = autenticarRequisicao(r, usuarioID)
+
+	controller.BuscarPublicacoes(recorder, r)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSearchPublications_WhenCursorIsInvalid_ExpectedBadRequestError(t *testing.T) {
+	mockRepo := new(MockPublicacoesRepositorio)
+	controller, recorder := setupConfig(t, mockRepo)
+
+	r := httptest.NewRequest("GET", "/publicacoes?cursor=invalido", nil)
+	r = autenticarRequisicao(r, 1)
+
+	controller.BuscarPublicacoes(recorder, r)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSearchPublicationByUser_WhenLimitIsInvalid_ExpectedBadRequestError(t *testing.T) {
+	mockRepo := new(MockPublicacoesRepositorio)
+	controller, recorder := setupConfig(t, mockRepo)
+
+	r := httptest.NewRequest("GET", "/usuarios/1/publicacoes?limit=-1", nil)
+	r = autenticarRequisicao(r, 2)
+	r = mux.SetURLVars(r, map[string]string{"usuarioId": "1"})
+
+	controller.BuscarPublicacoesPorUsuario(recorder, r)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreatePublication_WhenRequestIsNotAuthenticated_ExpectedUnauthorizedError(t *testing.T) {
+	mockRepo := new(MockPublicacoesRepositorio)
+	controller, recorder := setupConfig(t, mockRepo)
+
+	body, _ := json.Marshal(modelos.Publicacao{Titulo: "Teste", Conteudo: "Conteudo"})
+	r := httptest.NewRequest("POST", "/publicacoes", bytes.NewReader(body))
+
+	controller.CriarPublicacao(recorder, r)
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreatePublication_WhenCommandFailsInDatabase_ExpectedDriverErrorHidden(t *testing.T) {
+	mockRepo := new(MockPublicacoesRepositorio)
+	controller, recorder := setupConfig(t, mockRepo)
+
+	publicacao := modelos.Publicacao{Titulo: "Teste", Conteudo: "Conteudo", AutorID: 1}
+	mockRepo.On("Criar", publicacao).Return(uint64(0), errors.New("pq: relation \"publicacoes\" does not exist"))
+
+	body, _ := json.Marshal(publicacao)
+	r := httptest.NewRequest("POST", "/publicacoes", bytes.NewReader(body))
+	r = autenticarRequisicao(r, 1)
+
+	controller.CriarPublicacao(recorder, r)
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "relation")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLikePublication_WhenPublicationDoesNotExist_ExpectedNotFoundError(t *testing.T) {
+	mockRepo := new(MockPublicacoesRepositorio)
+	controller, recorder := setupConfig(t, mockRepo)
+
+	mockRepo.On("Curtir", uint64(1), uint64(1)).Return(repositorios.ErrNaoEncontrado)
+
+	r := httptest.NewRequest("POST", "/publicacoes/1/curtir", nil)
+	r = autenticarRequisicao(r, 1)
+	r = mux.SetURLVars(r, map[string]string{"publicacaoId": "1"})
+
+	controller.CurtirPublicacao(recorder, r)
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreatePublication_WhenTitleAndContentAreMissing_ExpectedProblemWithEveryField(t *testing.T) {
+	mockRepo := new(MockPublicacoesRepositorio)
+	controller, recorder := setupConfig(t, mockRepo)
+
+	body, _ := json.Marshal(modelos.Publicacao{})
+	r := httptest.NewRequest("POST", "/publicacoes", bytes.NewReader(body))
+	r = autenticarRequisicao(r, 1)
+
+	controller.CriarPublicacao(recorder, r)
+
+	var problema respostas.Problema
+	json.NewDecoder(recorder.Body).Decode(&problema)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "validacao.campos_invalidos", problema.Codigo)
+	assert.Equal(t, "/publicacoes", problema.Instancia)
+	if assert.Len(t, problema.Campos, 2) {
+		assert.Equal(t, "publicacao.titulo_obrigatorio", problema.Campos[0].Codigo)
+		assert.Equal(t, "publicacao.conteudo_obrigatorio", problema.Campos[1].Codigo)
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLikePublication_WhenPublicationDoesNotExist_ExpectedStableErrorCode(t *testing.T) {
+	mockRepo := new(MockPublicacoesRepositorio)
+	controller, recorder := setupConfig(t, mockRepo)
+
+	mockRepo.On("Curtir", uint64(1), uint64(1)).Return(repositorios.ErrNaoEncontrado)
+
+	r := httptest.NewRequest("POST", "/publicacoes/1/curtir", nil)
+	r = autenticarRequisicao(r, 1)
+	r = mux.SetURLVars(r, map[string]string{"publicacaoId": "1"})
+
+	controller.CurtirPublicacao(recorder, r)
+
+	var problema respostas.Problema
+	json.NewDecoder(recorder.Body).Decode(&problema)
+
+	assert.Equal(t, "recurso.nao_encontrado", problema.Codigo)
+	assert.Equal(t, "urn:social-network:erro:recurso.nao_encontrado", problema.Tipo)
+	assert.Equal(t, problema.Detalhe, problema.Erro)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"api/src/logs"
+	"api/src/modelos"
+	"api/src/respostas"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Banco é a parte do sql.DB usada na verificação de readiness.
+type Banco interface {
+	PingContext(ctx context.Context) error
+}
+
+// VersaoDasMigracoes informa a versão do schema aplicada no banco e a versão
+// esperada pelo binário.
+type VersaoDasMigracoes interface {
+	Versao(ctx context.Context) (uint64, bool, error)
+	UltimaVersao() uint64
+}
+
+type SaudeController struct {
+	Banco       Banco
+	Migracoes   VersaoDasMigracoes
+	Encerrando  func() bool
+	TempoLimite time.Duration
+}
+
+func NovoSaudeController(banco Banco, migracoes VersaoDasMigracoes, encerrando func() bool, tempoLimite time.Duration) *SaudeController {
+	return &SaudeController{Banco: banco, Migracoes: migracoes, Encerrando: encerrando, TempoLimite: tempoLimite}
+}
+
+// Vivo responde à verificação de liveness. Ela não depende do banco, para
+// que uma falha no Postgres não faça o orquestrador reiniciar a API.
+func (sc *SaudeController) Vivo(w http.ResponseWriter, r *http.Request) {
+	respostas.JSON(w, http.StatusOK, modelos.NovaSaude(map[string]modelos.Verificacao{
+		"processo": {Status: modelos.StatusSaudavel},
+	}))
+}
+
+// Pronto responde à verificação de readiness: a API só recebe tráfego quando
+// alcança o banco, o schema está na versão esperada e não está encerrando.
+func (sc *SaudeController) Pronto(w http.ResponseWriter, r *http.Request) {
+	ctx, cancelar := context.WithTimeout(r.Context(), sc.TempoLimite)
+	defer cancelar()
+
+	saude := modelos.NovaSaude(map[string]modelos.Verificacao{
+		"encerramento": sc.verificarEncerramento(),
+		"banco":        sc.verificarBanco(ctx),
+		"migracoes":    sc.verificarMigracoes(ctx),
+	})
+
+	status := http.StatusOK
+	if saude.Status != modelos.StatusSaudavel {
+		status = http.StatusServiceUnavailable
+	}
+
+	respostas.JSON(w, status, saude)
+}
+
+func (sc *SaudeController) verificarEncerramento() modelos.Verificacao {
+	if sc.Encerrando() {
+		return modelos.Verificacao{Status: modelos.StatusIndisponivel, Detalhe: "A API está sendo encerrada"}
+	}
+
+	return modelos.Verificacao{Status: modelos.StatusSaudavel}
+}
+
+func (sc *SaudeController) verificarBanco(ctx context.Context) modelos.Verificacao {
+	inicio := time.Now()
+	erro := sc.Banco.PingContext(ctx)
+	verificacao := modelos.Verificacao{Status: modelos.StatusSaudavel, Duracao: time.Since(inicio).String()}
+
+	// O /readyz não exige autenticação, então a mensagem do driver, que pode
+	// citar host e usuário do banco, vai apenas para o log.
+	if erro != nil {
+		logs.DoContexto(ctx).Error("Banco indisponível na verificação de readiness", "erro", erro)
+		verificacao.Status = modelos.StatusIndisponivel
+		verificacao.Detalhe = "Não foi possível conectar ao banco"
+	}
+
+	return verificacao
+}
+
+func (sc *SaudeController) verificarMigracoes(ctx context.Context) modelos.Verificacao {
+	versao, sujo, erro := sc.Migracoes.Versao(ctx)
+	if erro != nil {
+		logs.DoContexto(ctx).Error("Erro ao ler a versão das migrações na verificação de readiness", "erro", erro)
+		return modelos.Verificacao{Status: modelos.StatusIndisponivel, Detalhe: "Não foi possível ler a versão das migrações"}
+	}
+
+	esperada := sc.Migracoes.UltimaVersao()
+	detalhe := fmt.Sprintf("versão %d, esperada %d", versao, esperada)
+
+	if sujo {
+		return modelos.Verificacao{Status: modelos.StatusIndisponivel, Detalhe: detalhe + ", marcada como suja"}
+	}
+
+	// Um schema mais novo que o binário é esperado durante um deploy gradual, quando
+	// a nova versão já migrou o banco e as réplicas antigas ainda atendem.
+	if versao < esperada {
+		return modelos.Verificacao{Status: modelos.StatusIndisponivel, Detalhe: detalhe}
+	}
+
+	return modelos.Verificacao{Status: modelos.StatusSaudavel, Detalhe: detalhe}
+}
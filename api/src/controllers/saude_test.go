@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"api/src/modelos"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type bancoDeTeste struct {
+	erro error
+}
+
+func (b bancoDeTeste) PingContext(ctx context.Context) error {
+	return b.erro
+}
+
+type migracoesDeTeste struct {
+	versao   uint64
+	sujo     bool
+	esperada uint64
+}
+
+func (m migracoesDeTeste) Versao(ctx context.Context) (uint64, bool, error) {
+	return m.versao, m.sujo, nil
+}
+
+func (m migracoesDeTeste) UltimaVersao() uint64 {
+	return m.esperada
+}
+
+func setupSaude(banco Banco, migracoes VersaoDasMigracoes, encerrando bool) (*SaudeController, *httptest.ResponseRecorder) {
+	controller := NovoSaudeController(banco, migracoes, func() bool { return encerrando }, time.Second)
+	return controller, httptest.NewRecorder()
+}
+
+func lerSaude(t *testing.T, recorder *httptest.ResponseRecorder) modelos.Saude {
+	var saude modelos.Saude
+	assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&saude))
+	return saude
+}
+
+func TestReady_WhenEveryCheckPasses_ExpectedOk(t *testing.T) {
+	controller, recorder := setupSaude(bancoDeTeste{}, migracoesDeTeste{versao: 5, esperada: 5}, false)
+
+	controller.Pronto(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	saude := lerSaude(t, recorder)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, modelos.StatusSaudavel, saude.Status)
+	assert.Equal(t, modelos.StatusSaudavel, saude.Verificacoes["banco"].Status)
+	assert.Equal(t, modelos.StatusSaudavel, saude.Verificacoes["migracoes"].Status)
+	assert.Equal(t, modelos.StatusSaudavel, saude.Verificacoes["encerramento"].Status)
+}
+
+func TestReady_WhenDatabaseIsUnreachable_ExpectedServiceUnavailable(t *testing.T) {
+	controller, recorder := setupSaude(bancoDeTeste{erro: errors.New("dial tcp db.interno:5432: user social_network: connection refused")}, migracoesDeTeste{versao: 5, esperada: 5}, false)
+
+	controller.Pronto(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	corpo := recorder.Body.String()
+
+	saude := lerSaude(t, recorder)
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, modelos.StatusIndisponivel, saude.Status)
+	assert.Equal(t, modelos.StatusIndisponivel, saude.Verificacoes["banco"].Status)
+	assert.Equal(t, "Não foi possível conectar ao banco", saude.Verificacoes["banco"].Detalhe)
+	assert.NotContains(t, corpo, "db.interno")
+	assert.NotContains(t, corpo, "social_network")
+}
+
+func TestReady_WhenMigrationsAreBehind_ExpectedServiceUnavailable(t *testing.T) {
+	controller, recorder := setupSaude(bancoDeTeste{}, migracoesDeTeste{versao: 4, esperada: 5}, false)
+
+	controller.Pronto(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	saude := lerSaude(t, recorder)
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, modelos.StatusIndisponivel, saude.Verificacoes["migracoes"].Status)
+}
+
+func TestReady_WhenMigrationsAreAhead_ExpectedOk(t *testing.T) {
+	controller, recorder := setupSaude(bancoDeTeste{}, migracoesDeTeste{versao: 6, esperada: 5}, false)
+
+	controller.Pronto(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	saude := lerSaude(t, recorder)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, modelos.StatusSaudavel, saude.Verificacoes["migracoes"].Status)
+	assert.Equal(t, "versão 6, esperada 5", saude.Verificacoes["migracoes"].Detalhe)
+}
+
+func TestReady_WhenDatabaseIsDirty_ExpectedServiceUnavailable(t *testing.T) {
+	controller, recorder := setupSaude(bancoDeTeste{}, migracoesDeTeste{versao: 5, sujo: true, esperada: 5}, false)
+
+	controller.Pronto(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+}
+
+func TestReady_WhenShuttingDown_ExpectedServiceUnavailable(t *testing.T) {
+	controller, recorder := setupSaude(bancoDeTeste{}, migracoesDeTeste{versao: 5, esperada: 5}, true)
+
+	controller.Pronto(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	saude := lerSaude(t, recorder)
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, modelos.StatusIndisponivel, saude.Verificacoes["encerramento"].Status)
+}
+
+func TestLive_WhenDatabaseIsUnreachable_ExpectedOk(t *testing.T) {
+	controller, recorder := setupSaude(bancoDeTeste{erro: errors.New("dial tcp db.interno:5432: user social_network: connection refused")}, migracoesDeTeste{}, false)
+
+	controller.Vivo(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, modelos.StatusSaudavel, lerSaude(t, recorder).Status)
+}
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/erros"
+	"api/src/repositorios"
+	"api/src/respostas"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type SeguidoresController struct {
+	Repositorio repositorios.SeguidoresRepositorio
+}
+
+func NovoSeguidoresController(repositorio repositorios.SeguidoresRepositorio) *SeguidoresController {
+	return &SeguidoresController{Repositorio: repositorio}
+}
+
+func (sc *SeguidoresController) SeguirUsuario(w http.ResponseWriter, r *http.Request) {
+	seguidorID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	usuarioID, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	if usuarioID == seguidorID {
+		respostas.Erro(w, r, http.StatusForbidden, erros.Novo("seguidor.seguir_a_si_mesmo", "Não é possível seguir você mesmo"))
+		return
+	}
+
+	if erro = sc.Repositorio.Seguir(r.Context(), usuarioID, seguidorID); erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+func (sc *SeguidoresController) PararDeSeguirUsuario(w http.ResponseWriter, r *http.Request) {
+	seguidorID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	usuarioID, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	if usuarioID == seguidorID {
+		respostas.Erro(w, r, http.StatusForbidden, erros.Novo("seguidor.parar_de_seguir_a_si_mesmo", "Não é possível parar de seguir você mesmo"))
+		return
+	}
+
+	if erro = sc.Repositorio.PararDeSeguir(r.Context(), usuarioID, seguidorID); erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+func (sc *SeguidoresController) BuscarSeguidores(w http.ResponseWriter, r *http.Request) {
+	parametros := mux.Vars(r)
+	usuarioID, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	seguidores, erro := sc.Repositorio.BuscarSeguidores(r.Context(), usuarioID)
+	if erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, seguidores)
+}
+
+func (sc *SeguidoresController) BuscarSeguindo(w http.ResponseWriter, r *http.Request) {
+	parametros := mux.Vars(r)
+	usuarioID, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	seguindo, erro := sc.Repositorio.BuscarSeguindo(r.Context(), usuarioID)
+	if erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, seguindo)
+}
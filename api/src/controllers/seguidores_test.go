@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"api/src/modelos"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSeguidoresRepositorio struct {
+	mock.Mock
+}
+
+func (m *MockSeguidoresRepositorio) Seguir(ctx context.Context, usuarioID, seguidorID uint64) error {
+	args := m.Called(usuarioID, seguidorID)
+	return args.Error(0)
+}
+
+func (m *MockSeguidoresRepositorio) PararDeSeguir(ctx context.Context, usuarioID, seguidorID uint64) error {
+	args := m.Called(usuarioID, seguidorID)
+	return args.Error(0)
+}
+
+func (m *MockSeguidoresRepositorio) BuscarSeguidores(ctx context.Context, usuarioID uint64) ([]modelos.Usuario, error) {
+	args := m.Called(usuarioID)
+	return args.Get(0).([]modelos.Usuario), args.Error(1)
+}
+
+func (m *MockSeguidoresRepositorio) BuscarSeguindo(ctx context.Context, usuarioID uint64) ([]modelos.Usuario, error) {
+	args := m.Called(usuarioID)
+	return args.Get(0).([]modelos.Usuario), args.Error(1)
+}
+
+func setupSeguidores(t *testing.T, repositorio *MockSeguidoresRepositorio) (*SeguidoresController, *httptest.ResponseRecorder) {
+	controller := NovoSeguidoresController(repositorio)
+	recorder := httptest.NewRecorder()
+	return controller, recorder
+}
+
+func TestFollowUser_WhenUserExists_ExpectedSuccess(t *testing.T) {
+	mockRepo := new(MockSeguidoresRepositorio)
+	controller, recorder := setupSeguidores(t, mockRepo)
+
+	mockRepo.On("Seguir", uint64(2), uint64(1)).Return(nil)
+
+	r := httptest.NewRequest("POST", "/usuarios/2/seguir", nil)
+	r = autenticarRequisicao(r, 1)
+	r = mux.SetURLVars(r, map[string]string{"usuarioId": "2"})
+
+	controller.SeguirUsuario(recorder, r)
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFollowUser_WhenUserFollowsThemself_ExpectedForbiddenError(t *testing.T) {
+	mockRepo := new(MockSeguidoresRepositorio)
+	controller, recorder := setupSeguidores(t, mockRepo)
+
+	r := httptest.NewRequest("POST", "/usuarios/1/seguir", nil)
+	r = autenticarRequisicao(r, 1)
+	r = mux.SetURLVars(r, map[string]string{"usuarioId": "1"})
+
+	controller.SeguirUsuario(recorder, r)
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	mockRepo.AssertNotCalled(t, "Seguir", mock.Anything, mock.Anything)
+}
+
+func TestFollowUser_WhenDatabaseFails_ExpectedInternalServerError(t *testing.T) {
+	mockRepo := new(MockSeguidoresRepositorio)
+	controller, recorder := setupSeguidores(t, mockRepo)
+
+	mockRepo.On("Seguir", uint64(2), uint64(1)).Return(errors.New("erro ao seguir usuário"))
+
+	r := httptest.NewRequest("POST", "/usuarios/2/seguir", nil)
+	r = autenticarRequisicao(r, 1)
+	r = mux.SetURLVars(r, map[string]string{"usuarioId": "2"})
+
+	controller.SeguirUsuario(recorder, r)
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUnfollowUser_WhenUserExists_ExpectedSuccess(t *testing.T) {
+	mockRepo := new(MockSeguidoresRepositorio)
+	controller, recorder := setupSeguidores(t, mockRepo)
+
+	mockRepo.On("PararDeSeguir", uint64(2), uint64(1)).Return(nil)
+
+	r := httptest.NewRequest("POST", "/usuarios/2/parar-de-seguir", nil)
+	r = autenticarRequisicao(r, 1)
+	r = mux.SetURLVars(r, map[string]string{"usuarioId": "2"})
+
+	controller.PararDeSeguirUsuario(recorder, r)
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSearchFollowers_WhenUserHasFollowers_ExpectedFollowersReturned(t *testing.T) {
+	mockRepo := new(MockSeguidoresRepositorio)
+	controller, recorder := setupSeguidores(t, mockRepo)
+
+	seguidores := []modelos.Usuario{{ID: 2, Nome: "Usuário 2", Nick: "usuario_2"}}
+	mockRepo.On("BuscarSeguidores", uint64(1)).Return(seguidores, nil)
+
+	r := httptest.NewRequest("GET", "/usuarios/1/seguidores", nil)
+	r = mux.SetURLVars(r, map[string]string{"usuarioId": "1"})
+
+	controller.BuscarSeguidores(recorder, r)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "usuario_2")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSearchFollowing_WhenUserFollowsNobody_ExpectedEmptyList(t *testing.T) {
+	mockRepo := new(MockSeguidoresRepositorio)
+	controller, recorder := setupSeguidores(t, mockRepo)
+
+	mockRepo.On("BuscarSeguindo", uint64(1)).Return([]modelos.Usuario{}, nil)
+
+	r := httptest.NewRequest("GET", "/usuarios/1/seguindo", nil)
+	r = mux.SetURLVars(r, map[string]string{"usuarioId": "1"})
+
+	controller.BuscarSeguindo(recorder, r)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/email"
+	"api/src/erros"
+	"api/src/logs"
+	"api/src/modelos"
+	"api/src/repositorios"
+	"api/src/respostas"
+	"api/src/seguranca"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var errEmailObrigatorio = erros.Novo("senha.email_obrigatorio", "O email é obrigatório")
+
+// tempoLimiteRedefinicao é o prazo da busca do usuário, da criação do token e
+// do envio do link, feitos em segundo plano.
+const tempoLimiteRedefinicao = 30 * time.Second
+
+// respostaEsqueciSenha é a mesma para emails cadastrados ou não, para que a
+// rota não revele quais emails existem.
+var respostaEsqueciSenha = map[string]string{
+	"mensagem": "Se o email estiver cadastrado, as instruções para redefinir a senha serão enviadas para ele",
+}
+
+type SenhaController struct {
+	Repositorio             repositorios.UsuarioRepositorio
+	RepositorioRedefinicoes repositorios.RedefinicoesSenhaRepositorio
+	Enviador                email.Enviador
+	// URLRedefinicao é a página do cliente que recebe o token, acrescentado
+	// ao link como o parâmetro token.
+	URLRedefinicao string
+	DuracaoDoToken time.Duration
+
+	redefinicoes sync.WaitGroup
+}
+
+func NovoSenhaController(repositorio repositorios.UsuarioRepositorio, repositorioRedefinicoes repositorios.RedefinicoesSenhaRepositorio, enviador email.Enviador, urlRedefinicao string, duracaoDoToken time.Duration) *SenhaController {
+	return &SenhaController{
+		Repositorio:             repositorio,
+		RepositorioRedefinicoes: repositorioRedefinicoes,
+		Enviador:                enviador,
+		URLRedefinicao:          urlRedefinicao,
+		DuracaoDoToken:          duracaoDoToken,
+	}
+}
+
+// EsqueciSenha envia ao email informado um link de redefinição de senha. A
+// busca do usuário, o token e o envio ficam em segundo plano, para que nem a
+// resposta nem o tempo dela revelem se o email está cadastrado.
+func (sc *SenhaController) EsqueciSenha(w http.ResponseWriter, r *http.Request) {
+	corpoRequest, erro := ioutil.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var requisicao modelos.RequisicaoEsqueciSenha
+	if erro = json.Unmarshal(corpoRequest, &requisicao); erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	requisicao.Email = strings.TrimSpace(requisicao.Email)
+	if requisicao.Email == "" {
+		respostas.Erro(w, r, http.StatusBadRequest, errEmailObrigatorio)
+		return
+	}
+
+	// O contexto da requisição é cancelado quando ela termina; apenas os
+	// valores, como o ID da requisição nos logs, seguem para o segundo plano.
+	ctx := context.WithoutCancel(r.Context())
+
+	sc.redefinicoes.Add(1)
+	go func() {
+		defer sc.redefinicoes.Done()
+
+		ctx, cancelar := context.WithTimeout(ctx, tempoLimiteRedefinicao)
+		defer cancelar()
+
+		if erro := sc.enviarLinkDeRedefinicao(ctx, requisicao.Email); erro != nil {
+			logs.DoContexto(ctx).Error("Erro ao enviar o link de redefinição de senha", "erro", erro)
+		}
+	}()
+
+	respostas.JSON(w, http.StatusAccepted, respostaEsqueciSenha)
+}
+
+// Aguardar espera as redefinições em andamento até o fim do contexto. É usado
+// no encerramento da API, antes de aguardar o envio dos emails.
+func (sc *SenhaController) Aguardar(ctx context.Context) error {
+	concluido := make(chan struct{})
+	go func() {
+		sc.redefinicoes.Wait()
+		close(concluido)
+	}()
+
+	select {
+	case <-concluido:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RedefinirSenha troca a senha do dono do token. O token só vale uma vez, e a
+// troca invalida os tokens de acesso e de atualização emitidos antes dela.
+func (sc *SenhaController) RedefinirSenha(w http.ResponseWriter, r *http.Request) {
+	corpoRequest, erro := ioutil.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var requisicao modelos.RequisicaoRedefinirSenha
+	if erro = json.Unmarshal(corpoRequest, &requisicao); erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	if erro = requisicao.Validar(); erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	senhaComHash, erro := seguranca.Hash(requisicao.Nova)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	if erro = sc.RepositorioRedefinicoes.Redefinir(r.Context(), autenticacao.HashTokenRedefinicaoSenha(requisicao.Token), string(senhaComHash)); erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// enviarLinkDeRedefinicao não faz nada quando o email não está cadastrado.
+func (sc *SenhaController) enviarLinkDeRedefinicao(ctx context.Context, emailInformado string) error {
+	usuario, erro := sc.Repositorio.BuscarPorEmail(ctx, emailInformado)
+	if errors.Is(erro, repositorios.ErrNaoEncontrado) {
+		return nil
+	}
+	if erro != nil {
+		return erro
+	}
+
+	token, hash, erro := autenticacao.CriarTokenRedefinicaoSenha()
+	if erro != nil {
+		return erro
+	}
+
+	if erro = sc.RepositorioRedefinicoes.Criar(ctx, modelos.RedefinicaoSenha{
+		UsuarioID: usuario.ID,
+		Hash:      hash,
+		Validade:  sc.DuracaoDoToken,
+	}); erro != nil {
+		return erro
+	}
+
+	link, erro := linkComToken(sc.URLRedefinicao, token)
+	if erro != nil {
+		return erro
+	}
+
+	mensagem := email.Mensagem{
+		Para:    usuario.Email,
+		Assunto: "Redefinição de senha",
+		Corpo: fmt.Sprintf("Olá!\n\nRecebemos um pedido para redefinir a senha da sua conta. "+
+			"Para escolher uma nova senha, acesse o link abaixo em até %d minutos:\n\n%s\n\n"+
+			"Se você não fez esse pedido, ignore este email. Sua senha continua a mesma.\n",
+			int(sc.DuracaoDoToken.Minutes()), link),
+	}
+
+	return sc.Enviador.Enviar(ctx, mensagem)
+}
@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/email"
+	"api/src/modelos"
+	"api/src/repositorios"
+	"api/src/seguranca"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRedefinicoesRepositorio struct {
+	mock.Mock
+}
+
+func (m *MockRedefinicoesRepositorio) Criar(ctx context.Context, redefinicao modelos.RedefinicaoSenha) error {
+	args := m.Called(redefinicao)
+	return args.Error(0)
+}
+
+func (m *MockRedefinicoesRepositorio) Redefinir(ctx context.Context, hash, senha string) error {
+	args := m.Called(hash, senha)
+	return args.Error(0)
+}
+
+// enviadorDeTeste guarda as mensagens em vez de enviá-las.
+type enviadorDeTeste struct {
+	mensagens chan email.Mensagem
+}
+
+func (e *enviadorDeTeste) Enviar(ctx context.Context, mensagem email.Mensagem) error {
+	e.mensagens <- mensagem
+	return nil
+}
+
+func novoEnviadorDeTeste() *enviadorDeTeste {
+	return &enviadorDeTeste{mensagens: make(chan email.Mensagem, 10)}
+}
+
+func setupSenha(repositorio *MockRepositorio, redefinicoes *MockRedefinicoesRepositorio) (*SenhaController, *enviadorDeTeste) {
+	enviador := novoEnviadorDeTeste()
+	controller := NovoSenhaController(repositorio, redefinicoes, enviador, "https://social.teste/redefinir-senha", time.Hour)
+	return controller, enviador
+}
+
+func createForgotPasswordRequest(t *testing.T, emailInformado string) *http.Request {
+	body, _ := json.Marshal(modelos.RequisicaoEsqueciSenha{Email: emailInformado})
+	req, err := http.NewRequest(http.MethodPost, "/senha/esqueci", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	return req
+}
+
+func createResetPasswordRequest(t *testing.T, token, nova string) *http.Request {
+	body, _ := json.Marshal(modelos.RequisicaoRedefinirSenha{Token: token, Nova: nova})
+	req, err := http.NewRequest(http.MethodPost, "/senha/redefinir", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	return req
+}
+
+func TestEsqueciSenha_WhenEmailExistsOrNot_ExpectedIdenticalResponses(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	mockRedefinicoes := new(MockRedefinicoesRepositorio)
+	controller, enviador := setupSenha(mockRepo, mockRedefinicoes)
+
+	mockRepo.On("BuscarPorEmail", "usuario@teste.com").Return(modelos.Usuario{ID: 1, Email: "usuario@teste.com"}, nil)
+	mockRepo.On("BuscarPorEmail", "naoexiste@teste.com").Return(modelos.Usuario{}, repositorios.ErrNaoEncontrado)
+	mockRedefinicoes.On("Criar", mock.Anything).Return(nil)
+
+	existente := httptest.NewRecorder()
+	controller.EsqueciSenha(existente, createForgotPasswordRequest(t, "usuario@teste.com"))
+	inexistente := httptest.NewRecorder()
+	controller.EsqueciSenha(inexistente, createForgotPasswordRequest(t, "naoexiste@teste.com"))
+	assert.NoError(t, controller.Aguardar(context.Background()))
+
+	assert.Equal(t, http.StatusAccepted, existente.Code)
+	assert.Equal(t, existente.Code, inexistente.Code)
+	assert.Equal(t, existente.Body.String(), inexistente.Body.String())
+	assert.Equal(t, existente.Header(), inexistente.Header())
+	assert.Len(t, enviador.mensagens, 1)
+	mockRedefinicoes.AssertNumberOfCalls(t, "Criar", 1)
+}
+
+func TestEsqueciSenha_WhenLookupIsSlow_ExpectedResponseDoesNotWaitForIt(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	mockRedefinicoes := new(MockRedefinicoesRepositorio)
+	controller, _ := setupSenha(mockRepo, mockRedefinicoes)
+
+	liberar := make(chan time.Time)
+	mockRepo.On("BuscarPorEmail", "usuario@teste.com").WaitUntil(liberar).Return(modelos.Usuario{}, repositorios.ErrNaoEncontrado)
+
+	recorder := httptest.NewRecorder()
+	controller.EsqueciSenha(recorder, createForgotPasswordRequest(t, "usuario@teste.com"))
+
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+	close(liberar)
+	assert.NoError(t, controller.Aguardar(context.Background()))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEsqueciSenha_WhenEmailExists_ExpectedLinkSentAndOnlyHashStored(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	mockRedefinicoes := new(MockRedefinicoesRepositorio)
+	controller, enviador := setupSenha(mockRepo, mockRedefinicoes)
+
+	var redefinicao modelos.RedefinicaoSenha
+	mockRepo.On("BuscarPorEmail", "usuario@teste.com").Return(modelos.Usuario{ID: 1, Email: "usuario@teste.com"}, nil)
+	mockRedefinicoes.On("Criar", mock.Anything).Run(func(args mock.Arguments) {
+		redefinicao = args.Get(0).(modelos.RedefinicaoSenha)
+	}).Return(nil)
+
+	controller.EsqueciSenha(httptest.NewRecorder(), createForgotPasswordRequest(t, "usuario@teste.com"))
+	mensagem := <-enviador.mensagens
+
+	_, token, encontrado := strings.Cut(mensagem.Corpo, "https://social.teste/redefinir-senha?token=")
+	token, _, _ = strings.Cut(token, "\n")
+	assert.True(t, encontrado)
+	assert.Equal(t, "usuario@teste.com", mensagem.Para)
+	assert.Equal(t, uint64(1), redefinicao.UsuarioID)
+	assert.Equal(t, autenticacao.HashTokenRedefinicaoSenha(token), redefinicao.Hash)
+	assert.NotContains(t, mensagem.Corpo, redefinicao.Hash)
+	assert.Equal(t, time.Hour, redefinicao.Validade)
+}
+
+func TestRedefinirSenha_WhenTokenIsValid_ExpectedPasswordHashedAndUpdated(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	mockRedefinicoes := new(MockRedefinicoesRepositorio)
+	controller, _ := setupSenha(mockRepo, mockRedefinicoes)
+	recorder := httptest.NewRecorder()
+
+	mockRedefinicoes.On("Redefinir", autenticacao.HashTokenRedefinicaoSenha("token-do-email"), mock.MatchedBy(func(senha string) bool {
+		return seguranca.VerificarSenha(senha, "novaSenha") == nil
+	})).Return(nil)
+
+	controller.RedefinirSenha(recorder, createResetPasswordRequest(t, "token-do-email", "novaSenha"))
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	mockRedefinicoes.AssertExpectations(t)
+}
+
+func TestRedefinirSenha_WhenTokenWasAlreadyUsed_ExpectedBadRequest(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	mockRedefinicoes := new(MockRedefinicoesRepositorio)
+	controller, _ := setupSenha(mockRepo, mockRedefinicoes)
+	recorder := httptest.NewRecorder()
+
+	mockRedefinicoes.On("Redefinir", mock.Anything, mock.Anything).Return(repositorios.ErrTokenRedefinicaoInvalido)
+
+	controller.RedefinirSenha(recorder, createResetPasswordRequest(t, "token-usado", "novaSenha"))
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "senha.token_redefinicao_invalido")
+}
@@ -0,0 +1,250 @@
+package controllers
+
+import (
+	"api/src/modelos"
+	"api/src/repositorios"
+	"api/src/seguranca"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateUser_WhenAllFieldsArePassed_ExpectedNewUser(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	controller, recorder := setup(t, mockRepo)
+
+	usuario := modelos.Usuario{Nome: "Usuário", Nick: "usuario", Email: "usuario@teste.com", Senha: "123456"}
+	mockRepo.On("Criar", mock.AnythingOfType("modelos.Usuario")).Return(uint64(1), nil)
+
+	body, _ := json.Marshal(usuario)
+	r := httptest.NewRequest("POST", "/usuarios", bytes.NewReader(body))
+
+	controller.CriarUsuario(recorder, r)
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "senha")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateUser_WhenEmailIsInvalid_ExpectedBadRequestError(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	controller, recorder := setup(t, mockRepo)
+
+	usuario := modelos.Usuario{Nome: "Usuário", Nick: "usuario", Email: "email-invalido", Senha: "123456"}
+
+	body, _ := json.Marshal(usuario)
+	r := httptest.NewRequest("POST", "/usuarios", bytes.NewReader(body))
+
+	controller.CriarUsuario(recorder, r)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateUser_WhenCommandFailsInDatabase_ExpectedInternalServerError(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	controller, recorder := setup(t, mockRepo)
+
+	usuario := modelos.Usuario{Nome: "Usuário", Nick: "usuario", Email: "usuario@teste.com", Senha: "123456"}
+	mockRepo.On("Criar", mock.AnythingOfType("modelos.Usuario")).Return(uint64(0), errors.New("erro ao criar usuário"))
+
+	body, _ := json.Marshal(usuario)
+	r := httptest.NewRequest("POST", "/usuarios", bytes.NewReader(body))
+
+	controller.CriarUsuario(recorder, r)
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFindUserById_WhenUserExists_ExpectedUserReturned(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	controller, recorder := setup(t, mockRepo)
+
+	usuario := modelos.Usuario{ID: 1, Nome: "Usuário", Nick: "usuario", Email: "usuario@teste.com"}
+	mockRepo.On("BuscarPorID", uint64(1)).Return(usuario, nil)
+
+	r := httptest.NewRequest("GET", "/usuarios/1", nil)
+	r = mux.SetURLVars(r, map[string]string{"usuarioId": "1"})
+	r = autenticarRequisicao(r, 1)
+
+	controller.BuscarUsuario(recorder, r)
+
+	var retornado modelos.Usuario
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &retornado))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "usuario@teste.com", retornado.Email)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFindUserById_WhenCallerIsAnotherUser_ExpectedEmailHidden(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	controller, recorder := setup(t, mockRepo)
+
+	usuario := modelos.Usuario{ID: 1, Nome: "Usuário", Nick: "usuario", Email: "usuario@teste.com"}
+	mockRepo.On("BuscarPorID", uint64(1)).Return(usuario, nil)
+
+	r := httptest.NewRequest("GET", "/usuarios/1", nil)
+	r = mux.SetURLVars(r, map[string]string{"usuarioId": "1"})
+	r = autenticarRequisicao(r, 2)
+
+	controller.BuscarUsuario(recorder, r)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "email")
+	assert.Contains(t, recorder.Body.String(), `"nick":"usuario"`)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFindUserById_WhenUserDoesNotExist_ExpectedNotFoundError(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	controller, recorder := setup(t, mockRepo)
+
+	mockRepo.On("BuscarPorID", uint64(1)).Return(modelos.Usuario{}, repositorios.ErrNaoEncontrado)
+
+	r := httptest.NewRequest("GET", "/usuarios/1", nil)
+	r = mux.SetURLVars(r, map[string]string{"usuarioId": "1"})
+	r = autenticarRequisicao(r, 2)
+
+	controller.BuscarUsuario(recorder, r)
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateUser_WhenAllFieldsArePassed_ExpectedUpdatedUser(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	controller, recorder := setup(t, mockRepo)
+
+	usuario := modelos.Usuario{Nome: "Atualizado", Nick: "atualizado", Email: "atualizado@teste.com"}
+	mockRepo.On("Atualizar", uint64(1), usuario).Return(nil)
+
+	body, _ := json.Marshal(usuario)
+	r := httptest.NewRequest("PUT", "/usuarios/1", bytes.NewReader(body))
+	r = autenticarRequisicao(r, 1)
+	r = mux.SetURLVars(r, map[string]string{"usuarioId": "1"})
+
+	controller.AtualizarUsuario(recorder, r)
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateUser_WhenUserIsNotTheOwner_ExpectedForbiddenError(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	controller, recorder := setup(t, mockRepo)
+
+	usuario := modelos.Usuario{Nome: "Atualizado", Nick: "atualizado", Email: "atualizado@teste.com"}
+
+	body, _ := json.Marshal(usuario)
+	r := httptest.NewRequest("PUT", "/usuarios/1", bytes.NewReader(body))
+	r = autenticarRequisicao(r, 2)
+	r = mux.SetURLVars(r, map[string]string{"usuarioId": "1"})
+
+	controller.AtualizarUsuario(recorder, r)
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteUser_WhenUserIsTheOwner_ExpectedDeletedUser(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	controller, recorder := setup(t, mockRepo)
+
+	mockRepo.On("Deletar", uint64(1)).Return(nil)
+
+	r := httptest.NewRequest("DELETE", "/usuarios/1", nil)
+	r = autenticarRequisicao(r, 1)
+	r = mux.SetURLVars(r, map[string]string{"usuarioId": "1"})
+
+	controller.DeletarUsuario(recorder, r)
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteUser_WhenUserIsNotTheOwner_ExpectedForbiddenError(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	controller, recorder := setup(t, mockRepo)
+
+	r := httptest.NewRequest("DELETE", "/usuarios/1", nil)
+	r = autenticarRequisicao(r, 2)
+	r = mux.SetURLVars(r, map[string]string{"usuarioId": "1"})
+
+	controller.DeletarUsuario(recorder, r)
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func createUpdatePasswordRequest(t *testing.T, usuarioIDNoToken uint64, usuarioID string, senha modelos.Senha) *http.Request {
+	body, _ := json.Marshal(senha)
+	r := httptest.NewRequest("POST", "/usuarios/"+usuarioID+"/atualizar-senha", bytes.NewReader(body))
+	r = autenticarRequisicao(r, usuarioIDNoToken)
+	return mux.SetURLVars(r, map[string]string{"usuarioId": usuarioID})
+}
+
+func TestUpdatePassword_WhenCurrentPasswordMatches_ExpectedPasswordUpdated(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	controller, recorder := setup(t, mockRepo)
+
+	hashSenha, _ := seguranca.Hash("senhaAtual")
+	mockRepo.On("BuscarSenha", uint64(1)).Return(string(hashSenha), nil)
+	mockRepo.On("AtualizarSenha", uint64(1), mock.MatchedBy(func(senha string) bool {
+		return seguranca.VerificarSenha(senha, "senhaNova") == nil
+	})).Return(nil)
+
+	r := createUpdatePasswordRequest(t, 1, "1", modelos.Senha{Atual: "senhaAtual", Nova: "senhaNova"})
+	controller.AtualizarSenha(recorder, r)
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdatePassword_WhenCurrentPasswordIsIncorrect_ExpectedUnauthorizedError(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	controller, recorder := setup(t, mockRepo)
+
+	hashSenha, _ := seguranca.Hash("senhaAtual")
+	mockRepo.On("BuscarSenha", uint64(1)).Return(string(hashSenha), nil)
+
+	r := createUpdatePasswordRequest(t, 1, "1", modelos.Senha{Atual: "senhaErrada", Nova: "senhaNova"})
+	controller.AtualizarSenha(recorder, r)
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	mockRepo.AssertNotCalled(t, "AtualizarSenha", mock.Anything, mock.Anything)
+}
+
+func TestUpdatePassword_WhenUserIsNotTheOwner_ExpectedForbiddenError(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	controller, recorder := setup(t, mockRepo)
+
+	r := createUpdatePasswordRequest(t, 2, "1", modelos.Senha{Atual: "senhaAtual", Nova: "senhaNova"})
+	controller.AtualizarSenha(recorder, r)
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateUser_WhenNickOrEmailAlreadyExists_ExpectedConflictError(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	controller, recorder := setup(t, mockRepo)
+
+	usuario := modelos.Usuario{Nome: "Usuário", Nick: "usuario", Email: "usuario@teste.com", Senha: "123456"}
+	mockRepo.On("Criar", mock.AnythingOfType("modelos.Usuario")).Return(uint64(0), repositorios.ErrConflito)
+
+	body, _ := json.Marshal(usuario)
+	r := httptest.NewRequest("POST", "/usuarios", bytes.NewReader(body))
+
+	controller.CriarUsuario(recorder, r)
+
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/email"
+	"api/src/erros"
+	"api/src/modelos"
+	"api/src/repositorios"
+	"api/src/respostas"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+var (
+	errTokenVerificacaoInvalido = erros.Novo("usuario.token_verificacao_invalido", "Link de confirmação de email inválido ou expirado")
+	errEmailJaVerificado        = erros.Novo("usuario.email_ja_verificado", "O email já foi confirmado")
+)
+
+// VerificarEmail confirma o email com o token do link enviado no cadastro. O
+// link não exige login, pois costuma ser aberto em outro dispositivo.
+func (uc *UsuarioController) VerificarEmail(w http.ResponseWriter, r *http.Request) {
+	corpoRequest, erro := ioutil.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var requisicao modelos.RequisicaoVerificarEmail
+	if erro = json.Unmarshal(corpoRequest, &requisicao); erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, erro)
+		return
+	}
+
+	usuarioID, emailDoToken, erro := uc.Emissor.ValidarTokenVerificacaoEmail(requisicao.Token)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusBadRequest, errTokenVerificacaoInvalido)
+		return
+	}
+
+	erro = uc.Repositorio.VerificarEmail(r.Context(), usuarioID, emailDoToken)
+	if errors.Is(erro, repositorios.ErrNaoEncontrado) {
+		respostas.Erro(w, r, http.StatusBadRequest, errTokenVerificacaoInvalido)
+		return
+	}
+	if erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// ReenviarVerificacaoEmail envia um novo link de confirmação para o email
+// atual do usuário autenticado.
+func (uc *UsuarioController) ReenviarVerificacaoEmail(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, r, http.StatusUnauthorized, erro)
+		return
+	}
+
+	usuario, erro := uc.Repositorio.BuscarPorID(r.Context(), usuarioID)
+	if erro != nil {
+		respostas.ErroDeDominio(w, r, erro)
+		return
+	}
+
+	if usuario.EmailVerificado {
+		respostas.Erro(w, r, http.StatusConflict, errEmailJaVerificado)
+		return
+	}
+
+	if erro = uc.enviarVerificacaoEmail(r.Context(), usuario); erro != nil {
+		respostas.Erro(w, r, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusAccepted, nil)
+}
+
+func (uc *UsuarioController) enviarVerificacaoEmail(ctx context.Context, usuario modelos.Usuario) error {
+	token, erro := uc.Emissor.CriarTokenVerificacaoEmail(usuario.ID, usuario.Email)
+	if erro != nil {
+		return erro
+	}
+
+	link, erro := linkComToken(uc.URLVerificacaoEmail, token)
+	if erro != nil {
+		return erro
+	}
+
+	return uc.Enviador.Enviar(ctx, email.Mensagem{
+		Para:    usuario.Email,
+		Assunto: "Confirme o seu email",
+		Corpo: fmt.Sprintf("Olá!\n\nPara confirmar o email da sua conta, acesse o link abaixo em até %d horas:\n\n%s\n\n"+
+			"Se você não criou uma conta, ignore este email.\n",
+			int(autenticacao.DuracaoVerificacaoEmail.Hours()), link),
+	})
+}
+
+// linkComToken acrescenta o token à página do cliente como o parâmetro token.
+func linkComToken(pagina, token string) (string, error) {
+	link, erro := url.Parse(pagina)
+	if erro != nil {
+		return "", erro
+	}
+
+	parametros := link.Query()
+	parametros.Set("token", token)
+	link.RawQuery = parametros.Encode()
+
+	return link.String(), nil
+}
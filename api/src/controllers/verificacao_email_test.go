@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"api/src/modelos"
+	"api/src/repositorios"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func createVerifyEmailRequest(t *testing.T, token string) *http.Request {
+	body, _ := json.Marshal(modelos.RequisicaoVerificarEmail{Token: token})
+	req, err := http.NewRequest(http.MethodPost, "/usuarios/verificar-email", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	return req
+}
+
+func TestCreateUser_WhenCreated_ExpectedSignedVerificationLinkSent(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	controller, recorder := setup(t, mockRepo)
+	enviador := controller.Enviador.(*enviadorDeTeste)
+
+	mockRepo.On("Criar", mock.AnythingOfType("modelos.Usuario")).Return(uint64(7), nil)
+
+	body, _ := json.Marshal(modelos.Usuario{Nome: "Usuário", Nick: "usuario", Email: "usuario@teste.com", Senha: "123456"})
+	controller.CriarUsuario(recorder, httptest.NewRequest("POST", "/usuarios", bytes.NewReader(body)))
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	mensagem := <-enviador.mensagens
+	_, token, encontrado := strings.Cut(mensagem.Corpo, "https://social.teste/verificar-email?token=")
+	token, _, _ = strings.Cut(token, "\n")
+	assert.True(t, encontrado)
+	assert.Equal(t, "usuario@teste.com", mensagem.Para)
+
+	usuarioID, email, err := controller.Emissor.ValidarTokenVerificacaoEmail(token)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), usuarioID)
+	assert.Equal(t, "usuario@teste.com", email)
+}
+
+func TestVerificarEmail_WhenTokenIsValid_ExpectedEmailConfirmed(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	controller, recorder := setup(t, mockRepo)
+
+	token, _ := controller.Emissor.CriarTokenVerificacaoEmail(1, "usuario@teste.com")
+	mockRepo.On("VerificarEmail", uint64(1), "usuario@teste.com").Return(nil)
+
+	controller.VerificarEmail(recorder, createVerifyEmailRequest(t, token))
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestVerificarEmail_WhenEmailChangedAfterLink_ExpectedBadRequest(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	controller, recorder := setup(t, mockRepo)
+
+	token, _ := controller.Emissor.CriarTokenVerificacaoEmail(1, "antigo@teste.com")
+	mockRepo.On("VerificarEmail", uint64(1), "antigo@teste.com").Return(repositorios.ErrNaoEncontrado)
+
+	controller.VerificarEmail(recorder, createVerifyEmailRequest(t, token))
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "usuario.token_verificacao_invalido")
+}
+
+func TestVerificarEmail_WhenTokenIsAnAccessToken_ExpectedBadRequest(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	controller, recorder := setup(t, mockRepo)
+
+	tokenAcesso, _ := controller.Emissor.CriarToken(1, 0)
+	controller.VerificarEmail(recorder, createVerifyEmailRequest(t, tokenAcesso))
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	mockRepo.AssertNotCalled(t, "VerificarEmail", mock.Anything, mock.Anything)
+}
+
+func TestReenviarVerificacaoEmail_WhenAlreadyVerified_ExpectedConflictAndNoEmail(t *testing.T) {
+	mockRepo := new(MockRepositorio)
+	controller, recorder := setup(t, mockRepo)
+	enviador := controller.Enviador.(*enviadorDeTeste)
+
+	mockRepo.On("BuscarPorID", uint64(1)).Return(modelos.Usuario{ID: 1, Email: "usuario@teste.com", EmailVerificado: true}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/usuarios/reenviar-verificacao-email", nil)
+	controller.ReenviarVerificacaoEmail(recorder, autenticarRequisicao(req, 1))
+
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+	assert.Empty(t, enviador.mensagens)
+}
@@ -0,0 +1,57 @@
+// Package email envia as mensagens da API aos usuários, como os links de
+// redefinição de senha. O Enviador em SMTP é usado em produção e o local, que
+// grava as mensagens em um arquivo ou no log, no desenvolvimento e nos testes.
+package email
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Mensagem é um email de texto simples para um único destinatário.
+type Mensagem struct {
+	Para    string
+	Assunto string
+	Corpo   string
+}
+
+// Enviador (mailer) entrega as mensagens. Enviar não deve ser chamado dentro
+// do tempo da requisição quando a demora puder revelar algo ao cliente.
+type Enviador interface {
+	Enviar(ctx context.Context, mensagem Mensagem) error
+}
+
+// montar gera a mensagem no formato da RFC 5322. Os endereços são conferidos
+// antes, o que também impede a injeção de cabeçalhos pelo destinatário.
+func montar(remetente string, mensagem Mensagem, agora time.Time) (de, para *mail.Address, conteudo []byte, erro error) {
+	if de, erro = mail.ParseAddress(remetente); erro != nil {
+		return nil, nil, nil, fmt.Errorf("remetente inválido: %w", erro)
+	}
+	if para, erro = mail.ParseAddress(mensagem.Para); erro != nil {
+		return nil, nil, nil, fmt.Errorf("destinatário inválido: %w", erro)
+	}
+	if strings.ContainsAny(mensagem.Assunto, "\r\n") {
+		return nil, nil, nil, fmt.Errorf("o assunto não pode ter quebras de linha")
+	}
+
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "From: %s\r\n", de)
+	fmt.Fprintf(&buffer, "To: %s\r\n", para)
+	fmt.Fprintf(&buffer, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", mensagem.Assunto))
+	fmt.Fprintf(&buffer, "Date: %s\r\n", agora.Format(time.RFC1123Z))
+	buffer.WriteString("MIME-Version: 1.0\r\n")
+	buffer.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buffer.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	corpo := quotedprintable.NewWriter(&buffer)
+	corpo.Write([]byte(strings.ReplaceAll(mensagem.Corpo, "\n", "\r\n")))
+	corpo.Close()
+
+	return de, para, buffer.Bytes(), nil
+}
@@ -0,0 +1,134 @@
+package email
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const remetenteDeTeste = "Social Network <nao-responda@teste.com>"
+
+func TestMontar_WhenSubjectHasLineBreak_ExpectedError(t *testing.T) {
+	_, _, _, err := montar(remetenteDeTeste, Mensagem{
+		Para:    "usuario@teste.com",
+		Assunto: "Olá\r\nBcc: outro@teste.com",
+		Corpo:   "corpo",
+	}, time.Now())
+
+	assert.Error(t, err)
+}
+
+func TestMontar_WhenRecipientIsInvalid_ExpectedError(t *testing.T) {
+	_, _, _, err := montar(remetenteDeTeste, Mensagem{Para: "usuario@teste.com\r\nBcc: outro@teste.com"}, time.Now())
+
+	assert.Error(t, err)
+}
+
+func TestEnviarLocal_WhenFileIsConfigured_ExpectedMessagesAppended(t *testing.T) {
+	caminho := filepath.Join(t.TempDir(), "emails.txt")
+	enviador := NovoEnviadorLocal(caminho, remetenteDeTeste)
+
+	assert.NoError(t, enviador.Enviar(context.Background(), Mensagem{Para: "um@teste.com", Assunto: "Redefinição de senha", Corpo: "primeira"}))
+	assert.NoError(t, enviador.Enviar(context.Background(), Mensagem{Para: "dois@teste.com", Assunto: "Outro", Corpo: "segunda"}))
+
+	conteudo, err := os.ReadFile(caminho)
+	assert.NoError(t, err)
+	assert.Contains(t, string(conteudo), "To: <um@teste.com>")
+	assert.Contains(t, string(conteudo), "Subject: =?utf-8?q?Redefini=C3=A7=C3=A3o_de_senha?=")
+	assert.Contains(t, string(conteudo), "To: <dois@teste.com>")
+	assert.Contains(t, string(conteudo), "segunda")
+}
+
+// servidorSMTP atende uma única conversa SMTP sem TLS nem autenticação e
+// entrega o conteúdo recebido no DATA.
+func servidorSMTP(t *testing.T) (SMTP, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	recebido := make(chan string, 1)
+	go func() {
+		conexao, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conexao.Close()
+
+		texto := textproto.NewConn(conexao)
+		texto.PrintfLine("220 teste ESMTP")
+		for {
+			linha, err := texto.ReadLine()
+			if err != nil {
+				return
+			}
+
+			switch comando := strings.ToUpper(strings.Fields(linha)[0]); comando {
+			case "EHLO", "HELO":
+				texto.PrintfLine("250 teste")
+			case "DATA":
+				texto.PrintfLine("354 envie")
+				linhas, _ := texto.ReadDotLines()
+				recebido <- strings.Join(linhas, "\n")
+				texto.PrintfLine("250 ok")
+			case "QUIT":
+				texto.PrintfLine("221 tchau")
+				return
+			default:
+				texto.PrintfLine("250 ok")
+			}
+		}
+	}()
+
+	endereco := listener.Addr().(*net.TCPAddr)
+	return SMTP{Host: "127.0.0.1", Porta: endereco.Port}, recebido
+}
+
+func TestEnviarSMTP_WhenServerAccepts_ExpectedMessageDelivered(t *testing.T) {
+	servidor, recebido := servidorSMTP(t)
+	enviador := NovoEnviadorSMTP(servidor, remetenteDeTeste)
+
+	ctx, cancelar := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelar()
+
+	err := enviador.Enviar(ctx, Mensagem{Para: "usuario@teste.com", Assunto: "Teste", Corpo: "Olá, 42"})
+	assert.NoError(t, err)
+
+	select {
+	case mensagem := <-recebido:
+		assert.Contains(t, mensagem, "From: \"Social Network\" <nao-responda@teste.com>")
+		assert.Contains(t, mensagem, "To: <usuario@teste.com>")
+		assert.Contains(t, mensagem, "Ol=C3=A1, 42")
+	case <-ctx.Done():
+		t.Fatal("o servidor SMTP não recebeu a mensagem")
+	}
+}
+
+type enviadorLento struct {
+	enviadas chan Mensagem
+}
+
+func (e *enviadorLento) Enviar(ctx context.Context, mensagem Mensagem) error {
+	time.Sleep(20 * time.Millisecond)
+	e.enviadas <- mensagem
+	return nil
+}
+
+func TestEmSegundoPlano_WhenShuttingDown_ExpectedPendingMessagesAwaited(t *testing.T) {
+	lento := &enviadorLento{enviadas: make(chan Mensagem, 1)}
+	enviador := NovoEnviadorEmSegundoPlano(lento, time.Second)
+
+	ctx, cancelar := context.WithCancel(context.Background())
+	assert.NoError(t, enviador.Enviar(ctx, Mensagem{Para: "usuario@teste.com"}))
+	cancelar()
+
+	assert.Empty(t, lento.enviadas)
+	assert.NoError(t, enviador.Aguardar(context.Background()))
+	assert.Len(t, lento.enviadas, 1)
+}
@@ -0,0 +1,55 @@
+package email
+
+import (
+	"api/src/logs"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+type enviadorLocal struct {
+	caminho   string
+	remetente string
+	mutex     sync.Mutex
+}
+
+// NovoEnviadorLocal cria o enviador de desenvolvimento, que não entrega as
+// mensagens: elas são acrescentadas ao arquivo informado ou, sem arquivo,
+// registradas no log. O corpo pode conter tokens, então não deve ser usado em
+// produção.
+func NovoEnviadorLocal(caminho, remetente string) Enviador {
+	return &enviadorLocal{caminho: caminho, remetente: remetente}
+}
+
+func (e *enviadorLocal) Enviar(ctx context.Context, mensagem Mensagem) error {
+	_, _, conteudo, erro := montar(e.remetente, mensagem, time.Now())
+	if erro != nil {
+		return erro
+	}
+
+	if e.caminho == "" {
+		logs.DoContexto(ctx).Info("Email não enviado: enviador local",
+			"para", mensagem.Para,
+			"assunto", mensagem.Assunto,
+			"corpo", mensagem.Corpo,
+		)
+		return nil
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	arquivo, erro := os.OpenFile(e.caminho, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if erro != nil {
+		return erro
+	}
+
+	if _, erro = fmt.Fprintf(arquivo, "%s\r\n\r\n", conteudo); erro != nil {
+		arquivo.Close()
+		return erro
+	}
+
+	return arquivo.Close()
+}
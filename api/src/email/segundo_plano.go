@@ -0,0 +1,61 @@
+package email
+
+import (
+	"api/src/logs"
+	"context"
+	"sync"
+	"time"
+)
+
+// EmSegundoPlano envia as mensagens fora da requisição, para que a resposta
+// não espere o servidor de email nem revele, pelo tempo, se algo foi enviado.
+// As falhas de envio são registradas no log.
+type EmSegundoPlano struct {
+	enviador    Enviador
+	tempoLimite time.Duration
+	envios      sync.WaitGroup
+}
+
+// NovoEnviadorEmSegundoPlano envolve o enviador. Cada envio tem até
+// tempoLimite para terminar.
+func NovoEnviadorEmSegundoPlano(enviador Enviador, tempoLimite time.Duration) *EmSegundoPlano {
+	return &EmSegundoPlano{enviador: enviador, tempoLimite: tempoLimite}
+}
+
+// Enviar agenda o envio e retorna sem esperar por ele. O contexto só é usado
+// pelos valores, como o ID da requisição nos logs: o envio continua depois que
+// a requisição termina.
+func (e *EmSegundoPlano) Enviar(ctx context.Context, mensagem Mensagem) error {
+	ctx = context.WithoutCancel(ctx)
+
+	e.envios.Add(1)
+	go func() {
+		defer e.envios.Done()
+
+		ctx, cancelar := context.WithTimeout(ctx, e.tempoLimite)
+		defer cancelar()
+
+		if erro := e.enviador.Enviar(ctx, mensagem); erro != nil {
+			logs.DoContexto(ctx).Error("Erro ao enviar email", "assunto", mensagem.Assunto, "erro", erro)
+		}
+	}()
+
+	return nil
+}
+
+// Aguardar espera os envios em andamento até o fim do contexto. É usado no
+// encerramento da API.
+func (e *EmSegundoPlano) Aguardar(ctx context.Context) error {
+	concluido := make(chan struct{})
+	go func() {
+		e.envios.Wait()
+		close(concluido)
+	}()
+
+	select {
+	case <-concluido:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
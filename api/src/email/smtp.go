@@ -0,0 +1,87 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTP reúne os dados de acesso ao servidor de email. Sem Usuario, as
+// mensagens são enviadas sem autenticação.
+type SMTP struct {
+	Host    string
+	Porta   int
+	Usuario string
+	Senha   string
+}
+
+type enviadorSMTP struct {
+	servidor  SMTP
+	remetente string
+}
+
+// NovoEnviadorSMTP cria o enviador que entrega as mensagens pelo servidor
+// informado. O STARTTLS é usado sempre que o servidor oferece.
+func NovoEnviadorSMTP(servidor SMTP, remetente string) Enviador {
+	return &enviadorSMTP{servidor: servidor, remetente: remetente}
+}
+
+func (e *enviadorSMTP) Enviar(ctx context.Context, mensagem Mensagem) error {
+	de, para, conteudo, erro := montar(e.remetente, mensagem, time.Now())
+	if erro != nil {
+		return erro
+	}
+
+	var dialer net.Dialer
+	conexao, erro := dialer.DialContext(ctx, "tcp", net.JoinHostPort(e.servidor.Host, strconv.Itoa(e.servidor.Porta)))
+	if erro != nil {
+		return erro
+	}
+	defer conexao.Close()
+
+	if prazo, ok := ctx.Deadline(); ok {
+		conexao.SetDeadline(prazo)
+	}
+
+	cliente, erro := smtp.NewClient(conexao, e.servidor.Host)
+	if erro != nil {
+		return erro
+	}
+	defer cliente.Close()
+
+	if ok, _ := cliente.Extension("STARTTLS"); ok {
+		if erro = cliente.StartTLS(&tls.Config{ServerName: e.servidor.Host}); erro != nil {
+			return fmt.Errorf("starttls: %w", erro)
+		}
+	}
+
+	if e.servidor.Usuario != "" {
+		if erro = cliente.Auth(smtp.PlainAuth("", e.servidor.Usuario, e.servidor.Senha, e.servidor.Host)); erro != nil {
+			return fmt.Errorf("autenticar no smtp: %w", erro)
+		}
+	}
+
+	if erro = cliente.Mail(de.Address); erro != nil {
+		return erro
+	}
+	if erro = cliente.Rcpt(para.Address); erro != nil {
+		return erro
+	}
+
+	escritor, erro := cliente.Data()
+	if erro != nil {
+		return erro
+	}
+	if _, erro = escritor.Write(conteudo); erro != nil {
+		return erro
+	}
+	if erro = escritor.Close(); erro != nil {
+		return erro
+	}
+
+	return cliente.Quit()
+}
@@ -0,0 +1,63 @@
+package erros
+
+import "strings"
+
+// Erro é um erro com um código estável que os clientes da API podem usar
+// para decidir o que fazer, independente do texto da mensagem.
+type Erro struct {
+	Codigo   string
+	Mensagem string
+}
+
+func Novo(codigo, mensagem string) *Erro {
+	return &Erro{Codigo: codigo, Mensagem: mensagem}
+}
+
+func (erro *Erro) Error() string {
+	return erro.Mensagem
+}
+
+// Campo descreve a falha de validação de um único campo.
+type Campo struct {
+	Campo    string `json:"field"`
+	Codigo   string `json:"code"`
+	Mensagem string `json:"detail"`
+}
+
+// Validacao agrupa todas as falhas de validação de um modelo.
+type Validacao struct {
+	Campos []Campo
+}
+
+// Adicionar registra a falha de um campo.
+func (validacao *Validacao) Adicionar(campo, codigo, mensagem string) {
+	validacao.Campos = append(validacao.Campos, Campo{Campo: campo, Codigo: codigo, Mensagem: mensagem})
+}
+
+// Erro retorna a validação como erro, ou nil quando nenhum campo falhou.
+func (validacao *Validacao) Erro() error {
+	if len(validacao.Campos) == 0 {
+		return nil
+	}
+
+	return validacao
+}
+
+// Codigo retorna o código do campo quando apenas um falhou e um código
+// genérico quando há mais de uma falha.
+func (validacao *Validacao) Codigo() string {
+	if len(validacao.Campos) == 1 {
+		return validacao.Campos[0].Codigo
+	}
+
+	return "validacao.campos_invalidos"
+}
+
+func (validacao *Validacao) Error() string {
+	mensagens := make([]string, 0, len(validacao.Campos))
+	for _, campo := range validacao.Campos {
+		mensagens = append(mensagens, campo.Mensagem)
+	}
+
+	return strings.Join(mensagens, "; ")
+}
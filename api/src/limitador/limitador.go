@@ -0,0 +1,100 @@
+// Package limitador implementa o limite de requisições por balde de fichas
+// (token bucket). Cada chave, como um IP ou um usuário em uma rota, tem um
+// balde que se enche continuamente até a capacidade do limite; cada
+// requisição consome uma ficha e é recusada quando o balde está vazio.
+package limitador
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limite permite Requisicoes a cada Periodo, em rajada ou espaçadas. O valor
+// zero não limita.
+type Limite struct {
+	Requisicoes int
+	Periodo     time.Duration
+}
+
+// PorMinuto cria o limite de n requisições por minuto.
+func PorMinuto(n int) Limite {
+	return Limite{Requisicoes: n, Periodo: time.Minute}
+}
+
+// PorHora cria o limite de n requisições por hora.
+func PorHora(n int) Limite {
+	return Limite{Requisicoes: n, Periodo: time.Hour}
+}
+
+// Ativo indica se o limite deve ser aplicado.
+func (l Limite) Ativo() bool {
+	return l.Requisicoes > 0 && l.Periodo > 0
+}
+
+// intervalo é o tempo para repor uma ficha.
+func (l Limite) intervalo() time.Duration {
+	return l.Periodo / time.Duration(l.Requisicoes)
+}
+
+// Resultado descreve o estado do balde depois da tentativa de consumo.
+type Resultado struct {
+	Permitido bool
+	Limite    int
+	Restantes int
+	// Reinicio é o tempo até o balde estar cheio novamente.
+	Reinicio time.Duration
+	// TentarEm é o tempo até a próxima ficha, quando a requisição é recusada.
+	TentarEm time.Duration
+}
+
+// Armazenamento guarda os baldes. A implementação em memória atende uma
+// única réplica; a do Redis compartilha os baldes entre réplicas.
+type Armazenamento interface {
+	Consumir(ctx context.Context, chave string, limite Limite) (Resultado, error)
+}
+
+// balde é o estado de uma chave: as fichas disponíveis na última atualização.
+type balde struct {
+	fichas       float64
+	atualizadoEm time.Time
+}
+
+// consumir repõe as fichas do tempo decorrido e tenta consumir uma. As
+// implementações de Armazenamento compartilham essa regra.
+func (b *balde) consumir(limite Limite, agora time.Time) Resultado {
+	capacidade := float64(limite.Requisicoes)
+	intervalo := limite.intervalo()
+
+	if b.atualizadoEm.IsZero() {
+		b.fichas = capacidade
+	} else if decorrido := agora.Sub(b.atualizadoEm); decorrido > 0 {
+		b.fichas = math.Min(capacidade, b.fichas+float64(decorrido)/float64(intervalo))
+	}
+	b.atualizadoEm = agora
+
+	permitido := b.fichas >= 1
+	if permitido {
+		b.fichas--
+	}
+
+	return novoResultado(limite, b.fichas, permitido)
+}
+
+// novoResultado calcula os tempos do resultado a partir das fichas que
+// sobraram no balde.
+func novoResultado(limite Limite, fichas float64, permitido bool) Resultado {
+	intervalo := float64(limite.intervalo())
+
+	resultado := Resultado{
+		Permitido: permitido,
+		Limite:    limite.Requisicoes,
+		Restantes: int(fichas),
+		Reinicio:  time.Duration((float64(limite.Requisicoes) - fichas) * intervalo),
+	}
+	if !permitido {
+		resultado.TentarEm = time.Duration((1 - fichas) * intervalo)
+	}
+
+	return resultado
+}
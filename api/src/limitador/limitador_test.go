@@ -0,0 +1,108 @@
+package limitador
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+type relogio struct {
+	agora time.Time
+}
+
+func (r *relogio) Agora() time.Time { return r.agora }
+
+func (r *relogio) Avancar(d time.Duration) { r.agora = r.agora.Add(d) }
+
+// armazenamentos retorna as duas implementações com o mesmo relógio falso,
+// para que os testes de comportamento valham para ambas.
+func armazenamentos(t *testing.T, r *relogio) map[string]Armazenamento {
+	servidor := miniredis.RunT(t)
+	cliente := redis.NewClient(&redis.Options{Addr: servidor.Addr()})
+	t.Cleanup(func() { cliente.Close() })
+
+	return map[string]Armazenamento{
+		"memoria": novoArmazenamentoEmMemoria(r.Agora),
+		"redis":   &armazenamentoRedis{cliente: cliente, agora: r.Agora},
+	}
+}
+
+func TestConsumir_WhenBucketEmpties_ExpectedRejectionWithRetryAfter(t *testing.T) {
+	r := &relogio{agora: time.Unix(1700000000, 0)}
+
+	for nome, armazenamento := range armazenamentos(t, r) {
+		t.Run(nome, func(t *testing.T) {
+			limite := PorMinuto(3)
+			chave := "ip:10.0.0.1:" + nome
+
+			for i := 2; i >= 0; i-- {
+				resultado, err := armazenamento.Consumir(context.Background(), chave, limite)
+				assert.NoError(t, err)
+				assert.True(t, resultado.Permitido)
+				assert.Equal(t, i, resultado.Restantes)
+			}
+
+			resultado, err := armazenamento.Consumir(context.Background(), chave, limite)
+			assert.NoError(t, err)
+			assert.False(t, resultado.Permitido)
+			assert.Equal(t, 3, resultado.Limite)
+			assert.Equal(t, 20*time.Second, resultado.TentarEm)
+			assert.Equal(t, time.Minute, resultado.Reinicio)
+		})
+	}
+}
+
+func TestConsumir_WhenTimePasses_ExpectedTokensRefilled(t *testing.T) {
+	r := &relogio{agora: time.Unix(1700000000, 0)}
+
+	for nome, armazenamento := range armazenamentos(t, r) {
+		t.Run(nome, func(t *testing.T) {
+			limite := PorMinuto(2)
+			chave := "usuario:1:" + nome
+
+			for i := 0; i < 2; i++ {
+				armazenamento.Consumir(context.Background(), chave, limite)
+			}
+
+			r.Avancar(30 * time.Second)
+			resultado, err := armazenamento.Consumir(context.Background(), chave, limite)
+			assert.NoError(t, err)
+			assert.True(t, resultado.Permitido)
+			assert.Equal(t, 0, resultado.Restantes)
+
+			r.Avancar(time.Hour)
+			resultado, err = armazenamento.Consumir(context.Background(), chave, limite)
+			assert.NoError(t, err)
+			assert.True(t, resultado.Permitido)
+			assert.Equal(t, 1, resultado.Restantes)
+		})
+	}
+}
+
+func TestConsumir_WhenKeysDiffer_ExpectedIndependentBuckets(t *testing.T) {
+	r := &relogio{agora: time.Unix(1700000000, 0)}
+	armazenamento := novoArmazenamentoEmMemoria(r.Agora)
+	limite := PorMinuto(1)
+
+	primeiro, _ := armazenamento.Consumir(context.Background(), "ip:10.0.0.1", limite)
+	segundo, _ := armazenamento.Consumir(context.Background(), "ip:10.0.0.2", limite)
+
+	assert.True(t, primeiro.Permitido)
+	assert.True(t, segundo.Permitido)
+}
+
+func TestConsumir_WhenBucketsIdleForAPeriod_ExpectedTheyAreDiscarded(t *testing.T) {
+	r := &relogio{agora: time.Unix(1700000000, 0)}
+	armazenamento := novoArmazenamentoEmMemoria(r.Agora)
+
+	armazenamento.Consumir(context.Background(), "ip:10.0.0.1", PorMinuto(5))
+	r.Avancar(2 * time.Minute)
+	armazenamento.Consumir(context.Background(), "ip:10.0.0.2", PorMinuto(5))
+
+	assert.Len(t, armazenamento.baldes, 1)
+	assert.Contains(t, armazenamento.baldes, "ip:10.0.0.2")
+}
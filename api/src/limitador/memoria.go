@@ -0,0 +1,62 @@
+package limitador
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const intervaloDeLimpeza = time.Minute
+
+type armazenamentoEmMemoria struct {
+	mutex         sync.Mutex
+	baldes        map[string]*baldeEmMemoria
+	agora         func() time.Time
+	ultimaLimpeza time.Time
+}
+
+type baldeEmMemoria struct {
+	balde
+	limite Limite
+}
+
+// NovoArmazenamentoEmMemoria cria o armazenamento local do processo. Os
+// baldes cheios são descartados periodicamente, pois equivalem a um balde
+// novo.
+func NovoArmazenamentoEmMemoria() Armazenamento {
+	return novoArmazenamentoEmMemoria(time.Now)
+}
+
+func novoArmazenamentoEmMemoria(agora func() time.Time) *armazenamentoEmMemoria {
+	return &armazenamentoEmMemoria{baldes: make(map[string]*baldeEmMemoria), agora: agora}
+}
+
+func (a *armazenamentoEmMemoria) Consumir(ctx context.Context, chave string, limite Limite) (Resultado, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	agora := a.agora()
+	a.limpar(agora)
+
+	b, ok := a.baldes[chave]
+	if !ok {
+		b = &baldeEmMemoria{limite: limite}
+		a.baldes[chave] = b
+	}
+	b.limite = limite
+
+	return b.consumir(limite, agora), nil
+}
+
+func (a *armazenamentoEmMemoria) limpar(agora time.Time) {
+	if agora.Sub(a.ultimaLimpeza) < intervaloDeLimpeza {
+		return
+	}
+	a.ultimaLimpeza = agora
+
+	for chave, b := range a.baldes {
+		if agora.Sub(b.atualizadoEm) >= b.limite.Periodo {
+			delete(a.baldes, chave)
+		}
+	}
+}
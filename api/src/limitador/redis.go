@@ -0,0 +1,74 @@
+package limitador
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const prefixoRedis = "limite:"
+
+// scriptConsumir aplica a mesma regra de balde.consumir de forma atômica no
+// Redis. O balde expira depois de um período sem uso, quando já estaria cheio.
+var scriptConsumir = redis.NewScript(`
+local capacidade = tonumber(ARGV[1])
+local intervalo = tonumber(ARGV[2])
+local agora = tonumber(ARGV[3])
+local periodo = tonumber(ARGV[4])
+
+local estado = redis.call('HMGET', KEYS[1], 'fichas', 'atualizado_em')
+local fichas = tonumber(estado[1])
+local atualizadoEm = tonumber(estado[2])
+
+if fichas == nil then
+	fichas = capacidade
+elseif agora > atualizadoEm then
+	fichas = math.min(capacidade, fichas + (agora - atualizadoEm) / intervalo)
+end
+
+local permitido = 0
+if fichas >= 1 then
+	fichas = fichas - 1
+	permitido = 1
+end
+
+redis.call('HSET', KEYS[1], 'fichas', tostring(fichas), 'atualizado_em', agora)
+redis.call('PEXPIRE', KEYS[1], periodo)
+
+return {permitido, tostring(fichas)}
+`)
+
+type armazenamentoRedis struct {
+	cliente redis.Scripter
+	agora   func() time.Time
+}
+
+// NovoArmazenamentoRedis cria o armazenamento compartilhado entre réplicas em
+// um servidor compatível com Redis. O relógio usado é o da API, então as
+// réplicas devem ter os relógios sincronizados.
+func NovoArmazenamentoRedis(cliente redis.Scripter) Armazenamento {
+	return &armazenamentoRedis{cliente: cliente, agora: time.Now}
+}
+
+func (a *armazenamentoRedis) Consumir(ctx context.Context, chave string, limite Limite) (Resultado, error) {
+	valores, erro := scriptConsumir.Run(ctx, a.cliente, []string{prefixoRedis + chave},
+		limite.Requisicoes,
+		float64(limite.intervalo())/float64(time.Millisecond),
+		a.agora().UnixMilli(),
+		limite.Periodo.Milliseconds(),
+	).Slice()
+	if erro != nil {
+		return Resultado{}, erro
+	}
+
+	permitido, _ := valores[0].(int64)
+	textoFichas, _ := valores[1].(string)
+	fichas, erro := strconv.ParseFloat(textoFichas, 64)
+	if erro != nil {
+		return Resultado{}, erro
+	}
+
+	return novoResultado(limite, fichas, permitido == 1), nil
+}
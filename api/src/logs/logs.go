@@ -0,0 +1,85 @@
+// Package logs configura o logger estruturado da API e guarda no contexto o
+// logger de cada requisição, já com o ID da requisição.
+package logs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+type chaveContexto int
+
+const (
+	chaveLogger chaveContexto = iota
+	chaveRequisicao
+)
+
+// Novo cria o logger no nível e formato informados. O nível aceita debug,
+// info, warn ou error e o formato aceita json ou text.
+func Novo(nivel, formato string, saida io.Writer) (*slog.Logger, error) {
+	var nivelLog slog.Level
+	if erro := nivelLog.UnmarshalText([]byte(nivel)); erro != nil {
+		return nil, fmt.Errorf("nível de log inválido: %q", nivel)
+	}
+
+	opcoes := &slog.HandlerOptions{Level: nivelLog}
+
+	switch strings.ToLower(formato) {
+	case "json":
+		return slog.New(slog.NewJSONHandler(saida, opcoes)), nil
+	case "text":
+		return slog.New(slog.NewTextHandler(saida, opcoes)), nil
+	default:
+		return nil, fmt.Errorf("formato de log inválido: %q", formato)
+	}
+}
+
+// Requisicao acumula os atributos que entram na linha de log da requisição.
+// Os middlewares internos, como o de autenticação, acrescentam atributos que
+// só o middleware de log, mais externo, escreve no fim.
+type Requisicao struct {
+	mutex     sync.Mutex
+	atributos []any
+}
+
+// Atributos retorna os atributos acumulados, como pares chave e valor.
+func (requisicao *Requisicao) Atributos() []any {
+	requisicao.mutex.Lock()
+	defer requisicao.mutex.Unlock()
+
+	return append([]any(nil), requisicao.atributos...)
+}
+
+// IniciarRequisicao guarda o logger da requisição no contexto e retorna o
+// acumulador da linha de log.
+func IniciarRequisicao(ctx context.Context, logger *slog.Logger) (context.Context, *Requisicao) {
+	requisicao := &Requisicao{}
+	ctx = context.WithValue(ctx, chaveLogger, logger)
+	return context.WithValue(ctx, chaveRequisicao, requisicao), requisicao
+}
+
+// Enriquecer acrescenta atributos ao logger do contexto e à linha de log da
+// requisição.
+func Enriquecer(ctx context.Context, atributos ...any) context.Context {
+	if requisicao, ok := ctx.Value(chaveRequisicao).(*Requisicao); ok {
+		requisicao.mutex.Lock()
+		requisicao.atributos = append(requisicao.atributos, atributos...)
+		requisicao.mutex.Unlock()
+	}
+
+	return context.WithValue(ctx, chaveLogger, DoContexto(ctx).With(atributos...))
+}
+
+// DoContexto retorna o logger da requisição, ou o logger padrão quando o
+// contexto não veio de uma requisição.
+func DoContexto(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(chaveLogger).(*slog.Logger); ok {
+		return logger
+	}
+
+	return slog.Default()
+}
@@ -0,0 +1,49 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNovo_WhenLevelIsInvalid_ExpectedError(t *testing.T) {
+	_, err := Novo("verbose", "json", &bytes.Buffer{})
+
+	assert.Error(t, err)
+}
+
+func TestNovo_WhenFormatIsInvalid_ExpectedError(t *testing.T) {
+	_, err := Novo("info", "xml", &bytes.Buffer{})
+
+	assert.Error(t, err)
+}
+
+func TestNovo_WhenLevelIsWarn_ExpectedInfoDiscarded(t *testing.T) {
+	saida := &bytes.Buffer{}
+	logger, err := Novo("warn", "json", saida)
+	assert.NoError(t, err)
+
+	logger.Info("descartada")
+	logger.Warn("registrada")
+
+	var linha map[string]any
+	assert.NoError(t, json.Unmarshal(saida.Bytes(), &linha))
+	assert.Equal(t, "registrada", linha["msg"])
+}
+
+func TestEnriquecer_WhenRequestWasStarted_ExpectedAttributesAccumulated(t *testing.T) {
+	saida := &bytes.Buffer{}
+	logger, _ := Novo("info", "json", saida)
+
+	ctx, requisicao := IniciarRequisicao(context.Background(), logger)
+	ctx = Enriquecer(ctx, "user_id", 7)
+	DoContexto(ctx).Info("dentro do controller")
+
+	var linha map[string]any
+	assert.NoError(t, json.Unmarshal(saida.Bytes(), &linha))
+	assert.Equal(t, float64(7), linha["user_id"])
+	assert.Equal(t, []any{"user_id", 7}, requisicao.Atributos())
+}
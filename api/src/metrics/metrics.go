@@ -1,17 +1,29 @@
 package metrics
 
 import (
-	"fmt"
+	"database/sql"
+	"log/slog"
+	"sync"
+
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 )
 
+// Registro guarda as métricas expostas em /metrics. Ele é separado do
+// registro global do client_golang para que dependências não publiquem
+// métricas sem querer.
+var Registro = prometheus.NewRegistry()
+
+// Os rótulos usam o template da rota (ex.: /publicacoes/{publicacaoId}) e a
+// classe do status (ex.: 2xx), para que o número de séries não cresça com os
+// IDs das requisições.
 var (
 	TotalRequests = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "api_requests_total",
 			Help: "Número total de requisições recebidas",
 		},
-		[]string{"method", "endpoint"},
+		[]string{"method", "endpoint", "status"},
 	)
 
 	ResponseTime = prometheus.NewHistogramVec(
@@ -20,12 +32,64 @@ var (
 			Help:    "Tempo de resposta das requisições",
 			Buckets: prometheus.DefBuckets,
 		},
+		[]string{"method", "endpoint", "status"},
+	)
+
+	RequestsInFlight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "api_requests_in_flight",
+			Help: "Número de requisições sendo atendidas no momento",
+		},
 		[]string{"method", "endpoint"},
 	)
+
+	ResponseSize = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "api_response_size_bytes",
+			Help:    "Tamanho do corpo das respostas",
+			Buckets: prometheus.ExponentialBuckets(64, 4, 8),
+		},
+		[]string{"method", "endpoint", "status"},
+	)
+
+	DuracaoConsultas = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "api_db_query_duration_seconds",
+			Help:    "Duração das consultas ao banco, por método de repositório",
+			Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		},
+		[]string{"query"},
+	)
+
+	FalhasLogin = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_login_failures_total",
+			Help: "Tentativas de login recusadas, por senha incorreta (falha) ou por bloqueio (bloqueado)",
+		},
+		[]string{"reason"},
+	)
 )
 
+var registrar sync.Once
+
 func Init() {
-	fmt.Println("Registrando métricas no Prometheus...")
-	prometheus.MustRegister(TotalRequests)
-	prometheus.MustRegister(ResponseTime)
+	registrar.Do(func() {
+		slog.Info("Registrando métricas no Prometheus")
+		Registro.MustRegister(
+			TotalRequests,
+			ResponseTime,
+			RequestsInFlight,
+			ResponseSize,
+			DuracaoConsultas,
+			FalhasLogin,
+			collectors.NewGoCollector(),
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		)
+	})
+}
+
+// RegistrarBanco exporta as estatísticas do pool de conexões (sql.DBStats):
+// conexões em uso e ociosas, quantidade e tempo total de espera por conexão.
+func RegistrarBanco(db *sql.DB) {
+	Registro.MustRegister(collectors.NewDBStatsCollector(db, "social_network"))
 }
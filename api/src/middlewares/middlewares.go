@@ -2,16 +2,43 @@ package middlewares
 
 import (
 	"api/src/autenticacao"
+	"api/src/erros"
+	"api/src/limitador"
+	"api/src/logs"
 	"api/src/metrics"
+	"api/src/repositorios"
 	"api/src/respostas"
-	"fmt"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"math"
 	"net/http"
+	"regexp"
+	"strconv"
 	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// CabecalhoIDRequisicao é o cabeçalho que identifica a requisição nos logs.
+const CabecalhoIDRequisicao = "X-Request-ID"
+
+// padraoIDRequisicao limita os IDs aceitos do cliente, para que um valor
+// arbitrário não seja copiado para os logs e para a resposta.
+var padraoIDRequisicao = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+var (
+	errLimiteExcedido     = erros.Novo("requisicoes.limite_excedido", "Limite de requisições excedido, tente novamente mais tarde")
+	errEmailNaoVerificado = erros.Novo("usuario.email_nao_verificado", "Confirme o seu email para usar esta rota")
+	errTokenRevogado      = erros.Novo("autenticacao.token_revogado", "Token revogado")
 )
 
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
+	bytes      int
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
@@ -19,34 +46,215 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+func (rw *responseWriter) Write(dados []byte) (int, error) {
+	escritos, erro := rw.ResponseWriter.Write(dados)
+	rw.bytes += escritos
+	return escritos, erro
+}
+
+// Logger reaproveita o X-Request-ID recebido, ou gera um novo, guarda no
+// contexto um logger com esse ID e escreve uma linha por requisição com o
+// status, o tamanho e a duração da resposta.
 func Logger(proximaFuncao http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		fmt.Printf("\n %s %s %s", r.Method, r.RequestURI, r.Host)
+		inicio := time.Now()
+
+		idRequisicao := r.Header.Get(CabecalhoIDRequisicao)
+		if !padraoIDRequisicao.MatchString(idRequisicao) {
+			idRequisicao = gerarIDRequisicao()
+		}
+		w.Header().Set(CabecalhoIDRequisicao, idRequisicao)
+
+		logger := slog.Default().With("request_id", idRequisicao)
+		ctx, requisicao := logs.IniciarRequisicao(r.Context(), logger)
+
+		rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		proximaFuncao(rw, r.WithContext(ctx))
+
+		atributos := []any{
+			"method", r.Method,
+			"path", r.URL.Path,
+			"route", templateDaRota(r),
+			"status", rw.statusCode,
+			"bytes", rw.bytes,
+			"duration_ms", float64(time.Since(inicio).Microseconds()) / 1000,
+		}
+		atributos = append(atributos, requisicao.Atributos()...)
+
+		logger.Log(r.Context(), nivelDoStatus(rw.statusCode), "requisição atendida", atributos...)
+	}
+}
+
+func templateDaRota(r *http.Request) string {
+	if rota := mux.CurrentRoute(r); rota != nil {
+		if template, erro := rota.GetPathTemplate(); erro == nil {
+			return template
+		}
+	}
+
+	return r.URL.Path
+}
+
+func nivelDoStatus(status int) slog.Level {
+	if status >= http.StatusInternalServerError {
+		return slog.LevelError
+	}
+
+	return slog.LevelInfo
+}
+
+func gerarIDRequisicao() string {
+	bytes := make([]byte, 16)
+	if _, erro := rand.Read(bytes); erro != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+
+	return hex.EncodeToString(bytes)
+}
+
+// LimitarTempoDeConsulta aplica ao contexto da requisição o prazo informado
+// para as consultas ao banco. O contexto também é cancelado quando o cliente
+// se desconecta.
+func LimitarTempoDeConsulta(tempoLimite time.Duration, proximaFuncao http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancelar := context.WithTimeout(r.Context(), tempoLimite)
+		defer cancelar()
+
+		proximaFuncao(w, r.WithContext(ctx))
+	}
+}
+
+// LimitarRequisicoes aplica o limite da rota com um balde por cliente: o
+// usuário, quando a rota é autenticada, ou o IP, quando não é. As respostas
+// trazem os cabeçalhos RateLimit-*, e as recusadas também o Retry-After. Se o
+// armazenamento falhar, a requisição segue sem limite.
+func LimitarRequisicoes(armazenamento limitador.Armazenamento, limite limitador.Limite, proximaFuncao http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		chave := r.Method + " " + templateDaRota(r) + ":" + identificarCliente(r)
+
+		resultado, erro := armazenamento.Consumir(r.Context(), chave, limite)
+		if erro != nil {
+			logs.DoContexto(r.Context()).Warn("Limite de requisições indisponível", "erro", erro)
+			proximaFuncao(w, r)
+			return
+		}
+
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(resultado.Limite))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(resultado.Restantes))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(segundosArredondados(resultado.Reinicio)))
+
+		if !resultado.Permitido {
+			w.Header().Set("Retry-After", strconv.Itoa(max(1, segundosArredondados(resultado.TentarEm))))
+			respostas.Erro(w, r, http.StatusTooManyRequests, errLimiteExcedido)
+			return
+		}
+
 		proximaFuncao(w, r)
 	}
 }
 
-func Autenticar(proximaFuncao http.HandlerFunc) http.HandlerFunc {
+// identificarCliente usa o usuário autenticado ou, sem ele, o IP da conexão.
+func identificarCliente(r *http.Request) string {
+	if principal, ok := autenticacao.PrincipalDoContexto(r.Context()); ok {
+		return "usuario:" + strconv.FormatUint(principal.UsuarioID, 10)
+	}
+
+	return "ip:" + autenticacao.IPDoCliente(r)
+}
+
+func segundosArredondados(duracao time.Duration) int {
+	return int(math.Ceil(duracao.Seconds()))
+}
+
+// Autenticar valida o token da requisição, rejeita tokens emitidos antes da
+// última troca de senha do usuário e guarda o principal no contexto.
+func Autenticar(emissor *autenticacao.Emissor, repositorio repositorios.UsuarioRepositorio, proximaFuncao http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if erro := autenticacao.ValidarToken(r); erro != nil {
-			respostas.Erro(w, http.StatusUnauthorized, erro)
+		principal, erro := emissor.ValidarToken(r)
+		if erro != nil {
+			respostas.Erro(w, r, http.StatusUnauthorized, erro)
+			return
+		}
+
+		erro = verificarVersaoToken(r.Context(), repositorio, principal)
+		if errors.Is(erro, errTokenRevogado) {
+			respostas.Erro(w, r, http.StatusUnauthorized, erro)
+			return
+		}
+		if erro != nil {
+			respostas.ErroDeDominio(w, r, erro)
 			return
 		}
+		ctx := logs.Enriquecer(r.Context(), "user_id", principal.UsuarioID)
+		proximaFuncao(w, r.WithContext(autenticacao.ComPrincipal(ctx, principal)))
+	}
+}
+
+// ExigirEmailVerificado recusa os usuários que ainda não confirmaram o email.
+// Deve ser executado depois de Autenticar.
+func ExigirEmailVerificado(repositorio repositorios.UsuarioRepositorio, proximaFuncao http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+		if erro != nil {
+			respostas.Erro(w, r, http.StatusUnauthorized, erro)
+			return
+		}
+
+		verificado, erro := repositorio.EmailVerificado(r.Context(), usuarioID)
+		if erro != nil {
+			respostas.ErroDeDominio(w, r, erro)
+			return
+		}
+		if !verificado {
+			respostas.Erro(w, r, http.StatusForbidden, errEmailNaoVerificado)
+			return
+		}
+
 		proximaFuncao(w, r)
 	}
 }
 
+// verificarVersaoToken retorna errTokenRevogado quando o usuário não existe mais
+// ou trocou a senha depois da emissão do token. Os demais erros do banco são
+// retornados como estão, para não encerrar a sessão por uma falha passageira.
+func verificarVersaoToken(ctx context.Context, repositorio repositorios.UsuarioRepositorio, principal autenticacao.Principal) error {
+	versaoAtual, erro := repositorio.BuscarVersaoToken(ctx, principal.UsuarioID)
+	if errors.Is(erro, repositorios.ErrNaoEncontrado) {
+		return errTokenRevogado
+	}
+	if erro != nil {
+		return erro
+	}
+
+	if principal.VersaoToken != versaoAtual {
+		return errTokenRevogado
+	}
+
+	return nil
+}
+
+// PrometheusMiddleware registra a contagem, a duração, o tamanho e as
+// requisições em andamento de cada rota.
 func PrometheusMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
+		endpoint := templateDaRota(r)
 
-		rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		emAndamento := metrics.RequestsInFlight.WithLabelValues(r.Method, endpoint)
+		emAndamento.Inc()
+		defer emAndamento.Dec()
 
-		metrics.TotalRequests.WithLabelValues(r.Method, r.URL.Path).Inc()
+		rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 
 		next(rw, r)
 
-		duration := time.Since(start).Seconds()
-		metrics.ResponseTime.WithLabelValues(r.Method, r.URL.Path).Observe(duration)
+		status := classeDoStatus(rw.statusCode)
+		metrics.TotalRequests.WithLabelValues(r.Method, endpoint, status).Inc()
+		metrics.ResponseTime.WithLabelValues(r.Method, endpoint, status).Observe(time.Since(start).Seconds())
+		metrics.ResponseSize.WithLabelValues(r.Method, endpoint, status).Observe(float64(rw.bytes))
 	}
 }
+
+func classeDoStatus(status int) string {
+	return strconv.Itoa(status/100) + "xx"
+}
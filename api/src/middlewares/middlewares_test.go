@@ -0,0 +1,401 @@
+package middlewares
+
+import (
+	"api/src/autenticacao"
+	"api/src/limitador"
+	"api/src/logs"
+	"api/src/metrics"
+	"api/src/repositorios"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type repositorioDeVersaoToken struct {
+	repositorios.UsuarioRepositorio
+	versaoToken uint64
+	erro        error
+}
+
+func (r *repositorioDeVersaoToken) BuscarVersaoToken(ctx context.Context, usuarioID uint64) (uint64, error) {
+	return r.versaoToken, r.erro
+}
+
+type repositorioDeEmailVerificado struct {
+	repositorios.UsuarioRepositorio
+	verificado bool
+}
+
+func (r *repositorioDeEmailVerificado) EmailVerificado(ctx context.Context, usuarioID uint64) (bool, error) {
+	return r.verificado, nil
+}
+
+var emissor = func() *autenticacao.Emissor {
+	chaveiro, _ := autenticacao.NovoChaveiro(autenticacao.NovaChaveHMAC([]byte("chave-secreta-usada-apenas-nos-testes")))
+	return autenticacao.NovoEmissor(chaveiro, "social-network-api", "social-network")
+}()
+
+func requisicaoAutenticada(t *testing.T, versaoToken uint64) *http.Request {
+	tokenString, err := emissor.CriarToken(1, versaoToken)
+	if err != nil {
+		t.Fatalf("Erro ao gerar token JWT: %v", err)
+	}
+	r := httptest.NewRequest("GET", "/publicacoes", nil)
+	r.Header.Set("Authorization", "Bearer "+tokenString)
+	return r
+}
+
+func TestAuthenticate_WhenTokenVersionIsCurrent_ExpectedNextHandlerCalled(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	chamado := false
+
+	handler := Autenticar(emissor, &repositorioDeVersaoToken{versaoToken: 1}, func(w http.ResponseWriter, r *http.Request) {
+		chamado = true
+	})
+	handler(recorder, requisicaoAutenticada(t, 1))
+
+	assert.True(t, chamado)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestAuthenticate_WhenTokenIsValid_ExpectedPrincipalInContext(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	var principal autenticacao.Principal
+
+	handler := Autenticar(emissor, &repositorioDeVersaoToken{versaoToken: 0}, func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = autenticacao.ExtrairPrincipal(r)
+	})
+	handler(recorder, requisicaoAutenticada(t, 0))
+
+	assert.Equal(t, uint64(1), principal.UsuarioID)
+	assert.NotEmpty(t, principal.TokenID)
+	assert.True(t, principal.PossuiEscopo(autenticacao.EscopoUsuario))
+	assert.True(t, principal.ExpiraEm.After(time.Now()))
+}
+
+func TestAuthenticate_WhenTokenIsMissing_ExpectedUnauthorizedError(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	chamado := false
+
+	handler := Autenticar(emissor, &repositorioDeVersaoToken{}, func(w http.ResponseWriter, r *http.Request) {
+		chamado = true
+	})
+	handler(recorder, httptest.NewRequest("GET", "/publicacoes", nil))
+
+	assert.False(t, chamado)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestAuthenticate_WhenPasswordWasChangedAfterToken_ExpectedUnauthorizedError(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	chamado := false
+
+	handler := Autenticar(emissor, &repositorioDeVersaoToken{versaoToken: 2}, func(w http.ResponseWriter, r *http.Request) {
+		chamado = true
+	})
+	handler(recorder, requisicaoAutenticada(t, 1))
+
+	assert.False(t, chamado)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestAuthenticate_WhenUserNoLongerExists_ExpectedUnauthorizedError(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	handler := Autenticar(emissor, &repositorioDeVersaoToken{erro: repositorios.ErrNaoEncontrado}, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("o handler não deveria ser chamado")
+	})
+	handler(recorder, requisicaoAutenticada(t, 0))
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"code":"autenticacao.token_revogado"`)
+}
+
+func TestAuthenticate_WhenTokenVersionLookupFails_ExpectedInternalErrorInsteadOfRevokedToken(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	handler := Autenticar(emissor, &repositorioDeVersaoToken{erro: errors.New("conexão perdida")}, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("o handler não deveria ser chamado")
+	})
+	handler(recorder, requisicaoAutenticada(t, 0))
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "token_revogado")
+}
+
+func TestAuthenticate_WhenTokenIsTwoFactorChallenge_ExpectedUnauthorizedError(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	chamado := false
+
+	tokenDesafio, err := emissor.CriarDesafioDoisFatores(1, 0)
+	assert.NoError(t, err)
+	r := httptest.NewRequest("GET", "/publicacoes", nil)
+	r.Header.Set("Authorization", "Bearer "+tokenDesafio)
+
+	handler := Autenticar(emissor, &repositorioDeVersaoToken{}, func(w http.ResponseWriter, r *http.Request) {
+		chamado = true
+	})
+	handler(recorder, r)
+
+	assert.False(t, chamado)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestRequireVerifiedEmail_WhenEmailIsNotVerified_ExpectedForbiddenError(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	chamado := false
+
+	handler := ExigirEmailVerificado(&repositorioDeEmailVerificado{verificado: false}, func(w http.ResponseWriter, r *http.Request) {
+		chamado = true
+	})
+	r := httptest.NewRequest("POST", "/publicacoes", nil)
+	handler(recorder, r.WithContext(autenticacao.ComPrincipal(r.Context(), autenticacao.Principal{UsuarioID: 1})))
+
+	assert.False(t, chamado)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "usuario.email_nao_verificado")
+}
+
+func TestRequireVerifiedEmail_WhenEmailIsVerified_ExpectedNextHandlerCalled(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	chamado := false
+
+	handler := ExigirEmailVerificado(&repositorioDeEmailVerificado{verificado: true}, func(w http.ResponseWriter, r *http.Request) {
+		chamado = true
+	})
+	r := httptest.NewRequest("POST", "/publicacoes", nil)
+	handler(recorder, r.WithContext(autenticacao.ComPrincipal(r.Context(), autenticacao.Principal{UsuarioID: 1})))
+
+	assert.True(t, chamado)
+}
+
+func TestLimitQueryTime_WhenHandlerRuns_ExpectedContextWithConfiguredDeadline(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	var prazo time.Time
+	var possuiPrazo bool
+
+	handler := LimitarTempoDeConsulta(3*time.Second, func(w http.ResponseWriter, r *http.Request) {
+		prazo, possuiPrazo = r.Context().Deadline()
+	})
+	inicio := time.Now()
+	handler(recorder, httptest.NewRequest("GET", "/publicacoes", nil))
+
+	assert.True(t, possuiPrazo)
+	assert.WithinDuration(t, inicio.Add(3*time.Second), prazo, time.Second)
+}
+
+func capturarLogs(t *testing.T) *bytes.Buffer {
+	saida := &bytes.Buffer{}
+	anterior := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(saida, nil)))
+	t.Cleanup(func() { slog.SetDefault(anterior) })
+	return saida
+}
+
+func lerLinhaDeLog(t *testing.T, saida *bytes.Buffer) map[string]any {
+	var linha map[string]any
+	assert.NoError(t, json.Unmarshal(saida.Bytes(), &linha))
+	return linha
+}
+
+func TestLogger_WhenRequestHasNoID_ExpectedIDGeneratedAndReturned(t *testing.T) {
+	saida := capturarLogs(t)
+	recorder := httptest.NewRecorder()
+
+	handler := Logger(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("criado"))
+	})
+	handler(recorder, httptest.NewRequest(http.MethodPost, "/publicacoes", nil))
+
+	idRequisicao := recorder.Header().Get(CabecalhoIDRequisicao)
+	linha := lerLinhaDeLog(t, saida)
+
+	assert.Len(t, idRequisicao, 32)
+	assert.Equal(t, idRequisicao, linha["request_id"])
+	assert.Equal(t, float64(http.StatusCreated), linha["status"])
+	assert.Equal(t, float64(len("criado")), linha["bytes"])
+	assert.Equal(t, http.MethodPost, linha["method"])
+	assert.Contains(t, linha, "duration_ms")
+}
+
+func TestLogger_WhenRequestHasValidID_ExpectedIDPropagated(t *testing.T) {
+	saida := capturarLogs(t)
+	recorder := httptest.NewRecorder()
+
+	handler := Logger(func(w http.ResponseWriter, r *http.Request) {
+		logs.DoContexto(r.Context()).Info("dentro do controller")
+	})
+	r := httptest.NewRequest(http.MethodGet, "/publicacoes", nil)
+	r.Header.Set(CabecalhoIDRequisicao, "abc-123")
+	handler(recorder, r)
+
+	assert.Equal(t, "abc-123", recorder.Header().Get(CabecalhoIDRequisicao))
+	assert.Equal(t, "abc-123", lerPrimeiraLinha(t, saida)["request_id"])
+}
+
+func TestLogger_WhenRequestIDIsInvalid_ExpectedNewIDGenerated(t *testing.T) {
+	capturarLogs(t)
+	recorder := httptest.NewRecorder()
+
+	handler := Logger(func(w http.ResponseWriter, r *http.Request) {})
+	r := httptest.NewRequest(http.MethodGet, "/publicacoes", nil)
+	r.Header.Set(CabecalhoIDRequisicao, "id com espaços\ne quebra de linha")
+	handler(recorder, r)
+
+	assert.Len(t, recorder.Header().Get(CabecalhoIDRequisicao), 32)
+}
+
+func TestLogger_WhenRouteIsAuthenticated_ExpectedRouteTemplateAndUserLogged(t *testing.T) {
+	saida := capturarLogs(t)
+	recorder := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/publicacoes/{publicacaoId}", Logger(Autenticar(emissor, &repositorioDeVersaoToken{versaoToken: 0}, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	r := requisicaoAutenticada(t, 0)
+	r.URL.Path = "/publicacoes/42"
+	router.ServeHTTP(recorder, r)
+
+	linha := lerLinhaDeLog(t, saida)
+	assert.Equal(t, "/publicacoes/{publicacaoId}", linha["route"])
+	assert.Equal(t, "/publicacoes/42", linha["path"])
+	assert.Equal(t, float64(1), linha["user_id"])
+	assert.Equal(t, float64(http.StatusNoContent), linha["status"])
+}
+
+func lerPrimeiraLinha(t *testing.T, saida *bytes.Buffer) map[string]any {
+	primeira, _, _ := bytes.Cut(saida.Bytes(), []byte("\n"))
+	var linha map[string]any
+	assert.NoError(t, json.Unmarshal(primeira, &linha))
+	return linha
+}
+
+func TestPrometheusMiddleware_WhenRouteHasVariables_ExpectedOneSeriesPerTemplateAndStatusClass(t *testing.T) {
+	metrics.Init()
+	router := mux.NewRouter()
+	router.HandleFunc("/metricas-teste/{id}", PrometheusMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		if mux.Vars(r)["id"] == "3" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+
+	for _, id := range []string{"1", "2", "3"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metricas-teste/"+id, nil))
+	}
+
+	esperado := `
+# HELP api_requests_total Número total de requisições recebidas
+# TYPE api_requests_total counter
+api_requests_total{endpoint="/metricas-teste/{id}",method="GET",status="2xx"} 2
+api_requests_total{endpoint="/metricas-teste/{id}",method="GET",status="4xx"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(metrics.Registro, strings.NewReader(esperado), "api_requests_total"))
+	assert.Equal(t, float64(4), somaDoHistograma(t, "api_response_size_bytes", "/metricas-teste/{id}"))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.RequestsInFlight.WithLabelValues(http.MethodGet, "/metricas-teste/{id}")))
+}
+
+func TestPrometheusMiddleware_WhenRequestIsRunning_ExpectedInFlightGaugeIncremented(t *testing.T) {
+	metrics.Init()
+	var emAndamento float64
+	router := mux.NewRouter()
+	router.HandleFunc("/em-andamento", PrometheusMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		emAndamento = testutil.ToFloat64(metrics.RequestsInFlight.WithLabelValues(http.MethodGet, "/em-andamento"))
+	}))
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/em-andamento", nil))
+
+	assert.Equal(t, float64(1), emAndamento)
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.RequestsInFlight.WithLabelValues(http.MethodGet, "/em-andamento")))
+}
+
+func TestRegistro_WhenGathered_ExpectedRuntimeCollectorsIncluded(t *testing.T) {
+	metrics.Init()
+
+	familias, err := metrics.Registro.Gather()
+	assert.NoError(t, err)
+
+	nomes := map[string]bool{}
+	for _, familia := range familias {
+		nomes[familia.GetName()] = true
+	}
+
+	assert.True(t, nomes["go_goroutines"])
+	assert.True(t, nomes["process_cpu_seconds_total"])
+}
+
+func somaDoHistograma(t *testing.T, nome, endpoint string) float64 {
+	familias, err := metrics.Registro.Gather()
+	assert.NoError(t, err)
+
+	var soma float64
+	for _, familia := range familias {
+		if familia.GetName() != nome {
+			continue
+		}
+		for _, metrica := range familia.GetMetric() {
+			for _, rotulo := range metrica.GetLabel() {
+				if rotulo.GetName() == "endpoint" && rotulo.GetValue() == endpoint {
+					soma += metrica.GetHistogram().GetSampleSum()
+				}
+			}
+		}
+	}
+
+	return soma
+}
+
+func TestLimitRequests_WhenBudgetExhausted_ExpectedTooManyRequestsWithRetryAfter(t *testing.T) {
+	handler := LimitarRequisicoes(limitador.NovoArmazenamentoEmMemoria(), limitador.PorMinuto(2), func(w http.ResponseWriter, r *http.Request) {})
+
+	var recorder *httptest.ResponseRecorder
+	for i := 0; i < 3; i++ {
+		recorder = httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/login", nil)
+		r.RemoteAddr = "10.0.0.1:5000"
+		handler(recorder, r)
+	}
+
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "2", recorder.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", recorder.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", recorder.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "30", recorder.Header().Get("Retry-After"))
+	assert.Contains(t, recorder.Body.String(), `"code":"requisicoes.limite_excedido"`)
+}
+
+func TestLimitRequests_WhenDifferentClients_ExpectedSeparateBudgets(t *testing.T) {
+	handler := LimitarRequisicoes(limitador.NovoArmazenamentoEmMemoria(), limitador.PorMinuto(1), func(w http.ResponseWriter, r *http.Request) {})
+
+	requisicoes := []*http.Request{
+		httptest.NewRequest("POST", "/publicacoes", nil),
+		httptest.NewRequest("POST", "/publicacoes", nil),
+	}
+	for i, r := range requisicoes {
+		r.RemoteAddr = "10.0.0.1:5000"
+		principal := autenticacao.Principal{UsuarioID: uint64(i + 1)}
+		requisicoes[i] = r.WithContext(autenticacao.ComPrincipal(r.Context(), principal))
+	}
+
+	for _, r := range requisicoes {
+		recorder := httptest.NewRecorder()
+		handler(recorder, r)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "0", recorder.Header().Get("RateLimit-Remaining"))
+	}
+}
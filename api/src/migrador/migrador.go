@@ -0,0 +1,316 @@
+// Package migrador aplica as migrações do banco a partir de arquivos embutidos
+// no binário. O controle de versão usa a mesma tabela schema_migrations da
+// CLI migrate, de modo que bancos já migrados por ela continuam compatíveis.
+package migrador
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// chaveLock identifica o advisory lock usado para que apenas uma réplica
+// aplique migrações por vez.
+const chaveLock int64 = 7316482093
+
+const codigoTabelaInexistente = "42P01"
+
+var padraoArquivo = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+var (
+	// ErrBancoSujo indica que uma migração anterior falhou no meio e o banco
+	// precisa ser corrigido manualmente antes de novas migrações.
+	ErrBancoSujo = errors.New("O banco está marcado como sujo por uma migração que não terminou")
+	// ErrVersaoInexistente indica que a versão pedida não existe nos arquivos.
+	ErrVersaoInexistente = errors.New("A versão de migração informada não existe")
+)
+
+type migracao struct {
+	versao  uint64
+	nome    string
+	subida  string
+	descida string
+}
+
+// Migrador aplica e reverte as migrações de um banco Postgres.
+type Migrador struct {
+	db        *sql.DB
+	migracoes []migracao
+}
+
+// Novo lê as migrações de arquivos. Cada versão precisa ter os arquivos de
+// subida e de descida.
+func Novo(db *sql.DB, arquivos fs.FS) (*Migrador, error) {
+	migracoes, erro := lerMigracoes(arquivos)
+	if erro != nil {
+		return nil, erro
+	}
+
+	return &Migrador{db: db, migracoes: migracoes}, nil
+}
+
+// Subir aplica todas as migrações pendentes.
+func (m *Migrador) Subir(ctx context.Context) error {
+	if len(m.migracoes) == 0 {
+		return nil
+	}
+
+	return m.IrPara(ctx, m.migracoes[len(m.migracoes)-1].versao)
+}
+
+// Descer reverte todas as migrações aplicadas.
+func (m *Migrador) Descer(ctx context.Context) error {
+	return m.IrPara(ctx, 0)
+}
+
+// IrPara aplica ou reverte migrações até que o banco esteja na versão
+// informada. A versão 0 representa o banco sem nenhuma migração.
+func (m *Migrador) IrPara(ctx context.Context, versaoDestino uint64) error {
+	if versaoDestino != 0 && m.indice(versaoDestino) < 0 {
+		return ErrVersaoInexistente
+	}
+
+	return m.comLock(ctx, func(conexao *sql.Conn) error {
+		if _, erro := conexao.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version bigint not null primary key, dirty boolean not null)`); erro != nil {
+			return erro
+		}
+
+		versaoAtual, sujo, erro := versao(ctx, conexao)
+		if erro != nil {
+			return erro
+		}
+		if sujo {
+			return ErrBancoSujo
+		}
+
+		for _, passo := range m.planejar(versaoAtual, versaoDestino) {
+			if erro = aplicar(ctx, conexao, passo.sql, passo.versaoFinal); erro != nil {
+				return fmt.Errorf("migração %d_%s: %w", passo.versao, passo.nome, erro)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Versao retorna a versão atual do banco e se ela está marcada como suja. Um
+// banco que nunca foi migrado está na versão 0.
+func (m *Migrador) Versao(ctx context.Context) (uint64, bool, error) {
+	conexao, erro := m.db.Conn(ctx)
+	if erro != nil {
+		return 0, false, erro
+	}
+	defer conexao.Close()
+
+	return versao(ctx, conexao)
+}
+
+// UltimaVersao retorna a versão da migração mais recente embutida no binário.
+func (m *Migrador) UltimaVersao() uint64 {
+	if len(m.migracoes) == 0 {
+		return 0
+	}
+
+	return m.migracoes[len(m.migracoes)-1].versao
+}
+
+// Semear executa os arquivos de dados iniciais em ordem alfabética, numa única
+// transação. Os arquivos devem ser idempotentes, pois não há controle de quais
+// já foram executados.
+func (m *Migrador) Semear(ctx context.Context, arquivos fs.FS) error {
+	nomes, erro := fs.Glob(arquivos, "*.sql")
+	if erro != nil {
+		return erro
+	}
+	sort.Strings(nomes)
+
+	return m.comLock(ctx, func(conexao *sql.Conn) error {
+		transacao, erro := conexao.BeginTx(ctx, nil)
+		if erro != nil {
+			return erro
+		}
+		defer transacao.Rollback()
+
+		for _, nome := range nomes {
+			conteudo, erro := fs.ReadFile(arquivos, nome)
+			if erro != nil {
+				return erro
+			}
+
+			if _, erro = transacao.ExecContext(ctx, string(conteudo)); erro != nil {
+				return fmt.Errorf("semente %s: %w", nome, erro)
+			}
+		}
+
+		return transacao.Commit()
+	})
+}
+
+type passo struct {
+	versao      uint64
+	nome        string
+	sql         string
+	versaoFinal uint64
+}
+
+// planejar monta a sequência de arquivos que leva o banco da versão atual até
+// a versão de destino. Na descida, cada passo deixa o banco na versão anterior.
+func (m *Migrador) planejar(versaoAtual, versaoDestino uint64) []passo {
+	var passos []passo
+
+	if versaoDestino >= versaoAtual {
+		for _, migracao := range m.migracoes {
+			if migracao.versao > versaoAtual && migracao.versao <= versaoDestino {
+				passos = append(passos, passo{migracao.versao, migracao.nome, migracao.subida, migracao.versao})
+			}
+		}
+
+		return passos
+	}
+
+	for i := len(m.migracoes) - 1; i >= 0; i-- {
+		migracao := m.migracoes[i]
+		if migracao.versao > versaoAtual || migracao.versao <= versaoDestino {
+			continue
+		}
+
+		var anterior uint64
+		if i > 0 {
+			anterior = m.migracoes[i-1].versao
+		}
+		passos = append(passos, passo{migracao.versao, migracao.nome, migracao.descida, anterior})
+	}
+
+	return passos
+}
+
+func (m *Migrador) indice(versao uint64) int {
+	for i, migracao := range m.migracoes {
+		if migracao.versao == versao {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// comLock executa a função numa conexão exclusiva que segura o advisory lock.
+// O lock é de sessão, por isso todas as operações precisam usar a mesma conexão.
+func (m *Migrador) comLock(ctx context.Context, funcao func(conexao *sql.Conn) error) error {
+	conexao, erro := m.db.Conn(ctx)
+	if erro != nil {
+		return erro
+	}
+	defer conexao.Close()
+
+	if _, erro = conexao.ExecContext(ctx, "SELECT pg_advisory_lock($1)", chaveLock); erro != nil {
+		return erro
+	}
+	defer conexao.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", chaveLock)
+
+	return funcao(conexao)
+}
+
+// aplicar executa o arquivo e registra a nova versão na mesma transação. Como
+// o DDL do Postgres é transacional, uma falha não deixa o banco sujo.
+func aplicar(ctx context.Context, conexao *sql.Conn, comandos string, versaoFinal uint64) error {
+	transacao, erro := conexao.BeginTx(ctx, nil)
+	if erro != nil {
+		return erro
+	}
+	defer transacao.Rollback()
+
+	if _, erro = transacao.ExecContext(ctx, comandos); erro != nil {
+		return erro
+	}
+
+	if _, erro = transacao.ExecContext(ctx, "TRUNCATE schema_migrations"); erro != nil {
+		return erro
+	}
+
+	if versaoFinal > 0 {
+		if _, erro = transacao.ExecContext(ctx, "INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)", versaoFinal); erro != nil {
+			return erro
+		}
+	}
+
+	return transacao.Commit()
+}
+
+func versao(ctx context.Context, conexao *sql.Conn) (uint64, bool, error) {
+	var (
+		versao uint64
+		sujo   bool
+	)
+
+	erro := conexao.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&versao, &sujo)
+	if errors.Is(erro, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+
+	var erroPostgres *pgconn.PgError
+	if errors.As(erro, &erroPostgres) && erroPostgres.Code == codigoTabelaInexistente {
+		return 0, false, nil
+	}
+
+	return versao, sujo, erro
+}
+
+func lerMigracoes(arquivos fs.FS) ([]migracao, error) {
+	entradas, erro := fs.ReadDir(arquivos, ".")
+	if erro != nil {
+		return nil, erro
+	}
+
+	porVersao := make(map[uint64]*migracao)
+	for _, entrada := range entradas {
+		partes := padraoArquivo.FindStringSubmatch(entrada.Name())
+		if entrada.IsDir() || partes == nil {
+			continue
+		}
+
+		versao, erro := strconv.ParseUint(partes[1], 10, 64)
+		if erro != nil || versao == 0 {
+			return nil, fmt.Errorf("versão inválida no arquivo %s", entrada.Name())
+		}
+
+		conteudo, erro := fs.ReadFile(arquivos, path.Clean(entrada.Name()))
+		if erro != nil {
+			return nil, erro
+		}
+
+		atual, ok := porVersao[versao]
+		if !ok {
+			atual = &migracao{versao: versao, nome: partes[2]}
+			porVersao[versao] = atual
+		}
+
+		if partes[3] == "up" {
+			atual.subida = string(conteudo)
+		} else {
+			atual.descida = string(conteudo)
+		}
+	}
+
+	migracoes := make([]migracao, 0, len(porVersao))
+	for _, atual := range porVersao {
+		if atual.subida == "" || atual.descida == "" {
+			return nil, fmt.Errorf("a migração %d precisa dos arquivos up e down", atual.versao)
+		}
+		migracoes = append(migracoes, *atual)
+	}
+
+	sort.Slice(migracoes, func(i, j int) bool {
+		return migracoes[i].versao < migracoes[j].versao
+	})
+
+	return migracoes, nil
+}
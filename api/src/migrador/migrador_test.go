@@ -0,0 +1,143 @@
+package migrador
+
+import (
+	"api/config/database/postgres"
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var arquivosDeTeste = fstest.MapFS{
+	"000001_criar_usuarios.up.sql":     {Data: []byte("CREATE TABLE usuarios (id int)")},
+	"000001_criar_usuarios.down.sql":   {Data: []byte("DROP TABLE usuarios")},
+	"000002_criar_curtidas.up.sql":     {Data: []byte("CREATE TABLE curtidas (id int)")},
+	"000002_criar_curtidas.down.sql":   {Data: []byte("DROP TABLE curtidas")},
+	"000010_adicionar_indice.up.sql":   {Data: []byte("CREATE INDEX curtidas_idx ON curtidas (id)")},
+	"000010_adicionar_indice.down.sql": {Data: []byte("DROP INDEX curtidas_idx")},
+}
+
+func novoMigradorDeTeste(t *testing.T) (*Migrador, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	m, err := Novo(db, arquivosDeTeste)
+	assert.NoError(t, err)
+
+	return m, mock
+}
+
+func esperarInicio(mock sqlmock.Sqlmock, versao *uint64, sujo bool) {
+	mock.ExpectExec("SELECT pg_advisory_lock($1)").WithArgs(chaveLock).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations (version bigint not null primary key, dirty boolean not null)").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	linhas := sqlmock.NewRows([]string{"version", "dirty"})
+	if versao != nil {
+		linhas.AddRow(*versao, sujo)
+	}
+	mock.ExpectQuery("SELECT version, dirty FROM schema_migrations LIMIT 1").WillReturnRows(linhas)
+}
+
+func esperarPasso(mock sqlmock.Sqlmock, comandos string, versaoFinal uint64) {
+	mock.ExpectBegin()
+	mock.ExpectExec(comandos).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("TRUNCATE schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	if versaoFinal > 0 {
+		mock.ExpectExec("INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)").
+			WithArgs(versaoFinal).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+}
+
+func esperarUnlock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec("SELECT pg_advisory_unlock($1)").WithArgs(chaveLock).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestSubir_WhenDatabaseIsEmpty_ExpectedEveryMigrationAppliedInOrder(t *testing.T) {
+	m, mock := novoMigradorDeTeste(t)
+
+	esperarInicio(mock, nil, false)
+	esperarPasso(mock, "CREATE TABLE usuarios (id int)", 1)
+	esperarPasso(mock, "CREATE TABLE curtidas (id int)", 2)
+	esperarPasso(mock, "CREATE INDEX curtidas_idx ON curtidas (id)", 10)
+	esperarUnlock(mock)
+
+	assert.NoError(t, m.Subir(context.Background()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSubir_WhenDatabaseIsPartiallyMigrated_ExpectedOnlyPendingMigrationsApplied(t *testing.T) {
+	m, mock := novoMigradorDeTeste(t)
+
+	versaoAtual := uint64(2)
+	esperarInicio(mock, &versaoAtual, false)
+	esperarPasso(mock, "CREATE INDEX curtidas_idx ON curtidas (id)", 10)
+	esperarUnlock(mock)
+
+	assert.NoError(t, m.Subir(context.Background()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIrPara_WhenTargetIsBelowCurrentVersion_ExpectedDownMigrationsApplied(t *testing.T) {
+	m, mock := novoMigradorDeTeste(t)
+
+	versaoAtual := uint64(10)
+	esperarInicio(mock, &versaoAtual, false)
+	esperarPasso(mock, "DROP INDEX curtidas_idx", 2)
+	esperarPasso(mock, "DROP TABLE curtidas", 1)
+	esperarUnlock(mock)
+
+	assert.NoError(t, m.IrPara(context.Background(), 1))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDescer_WhenDatabaseIsMigrated_ExpectedVersionCleared(t *testing.T) {
+	m, mock := novoMigradorDeTeste(t)
+
+	versaoAtual := uint64(1)
+	esperarInicio(mock, &versaoAtual, false)
+	esperarPasso(mock, "DROP TABLE usuarios", 0)
+	esperarUnlock(mock)
+
+	assert.NoError(t, m.Descer(context.Background()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSubir_WhenDatabaseIsDirty_ExpectedDirtyError(t *testing.T) {
+	m, mock := novoMigradorDeTeste(t)
+
+	versaoAtual := uint64(2)
+	esperarInicio(mock, &versaoAtual, true)
+	esperarUnlock(mock)
+
+	assert.ErrorIs(t, m.Subir(context.Background()), ErrBancoSujo)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIrPara_WhenVersionDoesNotExist_ExpectedErrorWithoutTouchingDatabase(t *testing.T) {
+	m, mock := novoMigradorDeTeste(t)
+
+	assert.ErrorIs(t, m.IrPara(context.Background(), 3), ErrVersaoInexistente)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNovo_WhenDownFileIsMissing_ExpectedError(t *testing.T) {
+	arquivos := fstest.MapFS{
+		"000001_criar_usuarios.up.sql": {Data: []byte("CREATE TABLE usuarios (id int)")},
+	}
+
+	_, err := Novo(nil, arquivos)
+
+	assert.Error(t, err)
+}
+
+func TestNovo_WhenEmbeddedMigrationsAreRead_ExpectedEveryVersionLoaded(t *testing.T) {
+	m, err := Novo(nil, postgres.Migracoes())
+
+	assert.NoError(t, err)
+	assert.NotZero(t, m.UltimaVersao())
+}
@@ -0,0 +1,48 @@
+package modelos
+
+import (
+	"api/src/erros"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// TamanhoMaximoComentario é o tamanho da coluna conteudo da tabela comentarios.
+const TamanhoMaximoComentario = 300
+
+type Comentario struct {
+	ID           uint64    `json:"id,omitempty"`
+	PublicacaoID uint64    `json:"publicacaoId,omitempty"`
+	AutorID      uint64    `json:"autorId,omitempty"`
+	AutorNick    string    `json:"autorNick,omitempty"`
+	Conteudo     string    `json:"conteudo,omitempty"`
+	CriadoEm     time.Time `json:"CriadoEm,omitempty"`
+}
+
+func (comentario *Comentario) Preparar() error {
+	if erro := comentario.Validar(); erro != nil {
+		return erro
+	}
+
+	comentario.formatar()
+	return nil
+}
+
+func (comentario *Comentario) Validar() error {
+	var validacao erros.Validacao
+
+	conteudo := strings.TrimSpace(comentario.Conteudo)
+	if conteudo == "" {
+		validacao.Adicionar("conteudo", "comentario.conteudo_obrigatorio", "O conteúdo do comentário é obrigatório e não pode estar em branco")
+	}
+	if utf8.RuneCountInString(conteudo) > TamanhoMaximoComentario {
+		validacao.Adicionar("conteudo", "comentario.conteudo_muito_longo", fmt.Sprintf("O conteúdo do comentário deve ter no máximo %d caracteres", TamanhoMaximoComentario))
+	}
+
+	return validacao.Erro()
+}
+
+func (comentario *Comentario) formatar() {
+	comentario.Conteudo = strings.TrimSpace(comentario.Conteudo)
+}
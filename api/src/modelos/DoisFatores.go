@@ -0,0 +1,37 @@
+package modelos
+
+// DoisFatores é o estado da autenticação em dois fatores de um usuário. O
+// segredo existe desde a inscrição, mas só é exigido no login depois que a
+// inscrição é confirmada com o primeiro código.
+type DoisFatores struct {
+	Segredo string
+	Ativo   bool
+}
+
+type InscricaoDoisFatores struct {
+	Segredo            string `json:"segredo"`
+	URIProvisionamento string `json:"uriProvisionamento"`
+}
+
+type CodigosRecuperacao struct {
+	Codigos []string `json:"codigosRecuperacao"`
+}
+
+// DesafioDoisFatores é a resposta do login de quem tem a autenticação em dois
+// fatores ativa, no lugar do par de tokens.
+type DesafioDoisFatores struct {
+	DoisFatoresPendente bool   `json:"doisFatoresPendente"`
+	TokenDesafio        string `json:"tokenDesafio"`
+	ExpiraEm            int64  `json:"expiraEm"`
+}
+
+// RequisicaoCodigoDoisFatores traz o código do autenticador ou um código de
+// recuperação. TokenDesafio só é usado no login.
+type RequisicaoCodigoDoisFatores struct {
+	TokenDesafio string `json:"tokenDesafio,omitempty"`
+	Codigo       string `json:"codigo"`
+}
+
+type RequisicaoDesativarDoisFatores struct {
+	Senha string `json:"senha"`
+}
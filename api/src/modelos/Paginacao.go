@@ -0,0 +1,83 @@
+package modelos
+
+import (
+	"api/src/erros"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	LimitePadrao = 20
+	LimiteMaximo = 100
+)
+
+// Paginacao representa o pedido de uma página ordenada por (criadaEm, id) de
+// forma decrescente. Um cursor vazio indica a primeira página.
+type Paginacao struct {
+	Limite   int
+	CriadaEm time.Time
+	ID       uint64
+}
+
+type PaginaDePublicacoes struct {
+	Publicacoes   []Publicacao `json:"publicacoes"`
+	ProximoCursor string       `json:"proximoCursor,omitempty"`
+}
+
+// NovaPaginacao interpreta os parâmetros `limit` e `cursor` recebidos na query string.
+func NovaPaginacao(limite, cursor string) (Paginacao, error) {
+	paginacao := Paginacao{Limite: LimitePadrao}
+
+	if limite != "" {
+		valor, erro := strconv.Atoi(limite)
+		if erro != nil || valor <= 0 {
+			return Paginacao{}, erros.Novo("paginacao.limite_invalido", "O limite deve ser um número inteiro positivo")
+		}
+		if valor > LimiteMaximo {
+			valor = LimiteMaximo
+		}
+		paginacao.Limite = valor
+	}
+
+	if cursor != "" {
+		if erro := paginacao.decodificarCursor(cursor); erro != nil {
+			return Paginacao{}, erros.Novo("paginacao.cursor_invalido", "O cursor informado é inválido")
+		}
+	}
+
+	return paginacao, nil
+}
+
+// PrimeiraPagina informa se a paginação não possui cursor.
+func (paginacao Paginacao) PrimeiraPagina() bool {
+	return paginacao.ID == 0
+}
+
+// CodificarCursor gera o cursor opaco que aponta para os itens posteriores à publicação.
+func CodificarCursor(publicacao Publicacao) string {
+	valor := fmt.Sprintf("%d:%d", publicacao.CriadoEm.UnixMicro(), publicacao.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(valor))
+}
+
+func (paginacao *Paginacao) decodificarCursor(cursor string) error {
+	valor, erro := base64.RawURLEncoding.DecodeString(cursor)
+	if erro != nil {
+		return erro
+	}
+
+	var microssegundos int64
+	var id uint64
+	if _, erro = fmt.Sscanf(string(valor), "%d:%d", &microssegundos, &id); erro != nil {
+		return erro
+	}
+	if id == 0 {
+		return errors.New("cursor sem identificador")
+	}
+
+	paginacao.CriadaEm = time.UnixMicro(microssegundos).UTC()
+	paginacao.ID = id
+	return nil
+}
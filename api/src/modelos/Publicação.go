@@ -1,19 +1,22 @@
 package modelos
 
 import (
-	"errors"
+	"api/src/erros"
 	"strings"
 	"time"
 )
 
 type Publicacao struct {
-	ID        uint64    `json:"id, omitempty"`
-	Titulo    string    `json:"titulo, omitempty"`
-	Conteudo  string    `json:"conteudo, omitempty"`
-	AutorID   uint64    `json:"autorId, omitempty"`
-	AutorNick string    `json:"autorNick, omitempty"`
+	ID        uint64    `json:"id"`
+	Titulo    string    `json:"titulo"`
+	Conteudo  string    `json:"conteudo"`
+	AutorID   uint64    `json:"autorId"`
+	AutorNick string    `json:"autorNick"`
 	Curtidas  uint64    `json:"curtidas"`
 	CriadoEm  time.Time `json:"CriadoEm,omitempty"`
+
+	Comentarios   uint64 `json:"comentarios"`
+	CurtidoPorMim bool   `json:"curtidoPorMim"`
 }
 
 func (publicacao *Publicacao) Preparar() error {
@@ -26,14 +29,16 @@ func (publicacao *Publicacao) Preparar() error {
 }
 
 func (publicacao *Publicacao) Validar() error {
+	var validacao erros.Validacao
+
 	if publicacao.Titulo == "" {
-		return errors.New("O título é obrigatório e não pode estar em branco")
+		validacao.Adicionar("titulo", "publicacao.titulo_obrigatorio", "O título é obrigatório e não pode estar em branco")
 	}
 	if publicacao.Conteudo == "" {
-		return errors.New("O conteudo é obrigatório e não pode estar em branco")
+		validacao.Adicionar("conteudo", "publicacao.conteudo_obrigatorio", "O conteudo é obrigatório e não pode estar em branco")
 	}
 
-	return nil
+	return validacao.Erro()
 }
 
 func (publicacao *Publicacao) formatar() {
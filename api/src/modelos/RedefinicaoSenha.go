@@ -0,0 +1,37 @@
+package modelos
+
+import (
+	"api/src/erros"
+	"time"
+)
+
+// RedefinicaoSenha é o registro de um token de redefinição de senha enviado
+// por email. Assim como nos refresh tokens, apenas o hash é persistido. A
+// validade é somada ao relógio do banco, o mesmo que confere a expiração.
+type RedefinicaoSenha struct {
+	UsuarioID uint64
+	Hash      string
+	Validade  time.Duration
+}
+
+type RequisicaoEsqueciSenha struct {
+	Email string `json:"email"`
+}
+
+type RequisicaoRedefinirSenha struct {
+	Token string `json:"token"`
+	Nova  string `json:"nova"`
+}
+
+func (requisicao *RequisicaoRedefinirSenha) Validar() error {
+	var validacao erros.Validacao
+
+	if requisicao.Token == "" {
+		validacao.Adicionar("token", "senha.token_redefinicao_obrigatorio", "O token de redefinição é obrigatório")
+	}
+	if requisicao.Nova == "" {
+		validacao.Adicionar("nova", "senha.nova_obrigatoria", "A nova senha é obrigatória e não pode estar em branco")
+	}
+
+	return validacao.Erro()
+}
@@ -0,0 +1,32 @@
+package modelos
+
+const (
+	StatusSaudavel     = "ok"
+	StatusIndisponivel = "indisponivel"
+)
+
+// Saude é o resultado das verificações de liveness e readiness da API.
+type Saude struct {
+	Status       string                 `json:"status"`
+	Verificacoes map[string]Verificacao `json:"verificacoes"`
+}
+
+// Verificacao é o resultado de uma única verificação de saúde.
+type Verificacao struct {
+	Status  string `json:"status"`
+	Detalhe string `json:"detalhe,omitempty"`
+	Duracao string `json:"duracao,omitempty"`
+}
+
+// NovaSaude cria o resultado a partir das verificações. A API só está
+// saudável quando todas as verificações passaram.
+func NovaSaude(verificacoes map[string]Verificacao) Saude {
+	saude := Saude{Status: StatusSaudavel, Verificacoes: verificacoes}
+	for _, verificacao := range verificacoes {
+		if verificacao.Status != StatusSaudavel {
+			saude.Status = StatusIndisponivel
+		}
+	}
+
+	return saude
+}
@@ -1,6 +1,21 @@
 package modelos
 
+import "api/src/erros"
+
 type Senha struct {
 	Nova  string `json:"nova"`
 	Atual string `json:"atual"`
 }
+
+func (senha *Senha) Validar() error {
+	var validacao erros.Validacao
+
+	if senha.Atual == "" {
+		validacao.Adicionar("atual", "senha.atual_obrigatoria", "A senha atual é obrigatória e não pode estar em branco")
+	}
+	if senha.Nova == "" {
+		validacao.Adicionar("nova", "senha.nova_obrigatoria", "A nova senha é obrigatória e não pode estar em branco")
+	}
+
+	return validacao.Erro()
+}
@@ -0,0 +1,30 @@
+package modelos
+
+import "time"
+
+// Resultados registrados na trilha de auditoria do login.
+const (
+	ResultadoLoginSucesso     = "sucesso"
+	ResultadoLoginFalha       = "falha"
+	ResultadoLoginBloqueado   = "bloqueado"
+	ResultadoLoginDesbloqueio = "desbloqueio"
+)
+
+// TentativaLogin é um registro da trilha de auditoria do login. O email é
+// guardado mesmo quando não pertence a nenhum usuário, para que emails
+// inexistentes sejam bloqueados da mesma forma que os existentes.
+type TentativaLogin struct {
+	ID        uint64
+	Email     string
+	UsuarioID uint64
+	IP        string
+	Resultado string
+	// ResponsavelID é o administrador que fez o desbloqueio.
+	ResponsavelID uint64
+}
+
+// FalhasDeLogin resume as falhas recentes de uma conta ou de um IP.
+type FalhasDeLogin struct {
+	Quantidade  int
+	DesdeUltima time.Duration
+}
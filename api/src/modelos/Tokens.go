@@ -0,0 +1,25 @@
+package modelos
+
+import "time"
+
+// TokenAtualizacao é o registro de um refresh token. Apenas o hash do token é
+// persistido; tokens rotacionados a partir do mesmo login compartilham a família.
+// A validade é somada ao relógio do banco, o mesmo que confere a expiração.
+type TokenAtualizacao struct {
+	ID        uint64
+	UsuarioID uint64
+	Familia   string
+	Hash      string
+	Validade  time.Duration
+}
+
+type ParDeTokens struct {
+	TokenAcesso      string `json:"tokenAcesso"`
+	TokenAtualizacao string `json:"tokenAtualizacao"`
+	TipoToken        string `json:"tipoToken"`
+	ExpiraEm         int64  `json:"expiraEm"`
+}
+
+type RequisicaoTokenAtualizacao struct {
+	TokenAtualizacao string `json:"tokenAtualizacao"`
+}
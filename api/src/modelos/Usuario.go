@@ -1,8 +1,8 @@
 package modelos
 
 import (
+	"api/src/erros"
 	"api/src/seguranca"
-	"errors"
 	"github.com/badoux/checkmail"
 	"strings"
 	"time"
@@ -15,6 +15,24 @@ type Usuario struct {
 	Email    string    `json:"email,omitempty"`
 	Senha    string    `json:"senha,omitempty"`
 	CriadoEm time.Time `json:"CriadoEm,omitempty"`
+
+	VersaoToken      uint64 `json:"-"`
+	DoisFatoresAtivo bool   `json:"-"`
+	EmailVerificado  bool   `json:"-"`
+}
+
+type RequisicaoVerificarEmail struct {
+	Token string `json:"token"`
+}
+
+// Publico devolve apenas os dados do perfil que qualquer usuário autenticado pode ver.
+func (usuario Usuario) Publico() Usuario {
+	return Usuario{
+		ID:       usuario.ID,
+		Nome:     usuario.Nome,
+		Nick:     usuario.Nick,
+		CriadoEm: usuario.CriadoEm,
+	}
 }
 
 func (usuario *Usuario) Preparar(etapa string) error {
@@ -29,27 +47,27 @@ func (usuario *Usuario) Preparar(etapa string) error {
 }
 
 func (usuario *Usuario) validar(etapa string) error {
+	var validacao erros.Validacao
+
 	if usuario.Nome == "" {
-		return errors.New("O nome é obrigatório e não pode estar em branco")
+		validacao.Adicionar("nome", "usuario.nome_obrigatorio", "O nome é obrigatório e não pode estar em branco")
 	}
 
 	if usuario.Nick == "" {
-		return errors.New("O nick é obrigatório e não pode estar em branco")
+		validacao.Adicionar("nick", "usuario.nick_obrigatorio", "O nick é obrigatório e não pode estar em branco")
 	}
 
 	if usuario.Email == "" {
-		return errors.New("O email é obrigatório e não pode estar em branco")
-	}
-
-	if erro := checkmail.ValidateFormat(usuario.Email); erro != nil {
-		return errors.New("O email inserido é inválido")
+		validacao.Adicionar("email", "usuario.email_obrigatorio", "O email é obrigatório e não pode estar em branco")
+	} else if erro := checkmail.ValidateFormat(usuario.Email); erro != nil {
+		validacao.Adicionar("email", "usuario.email_invalido", "O email inserido é inválido")
 	}
 
 	if etapa == "cadastro" && usuario.Senha == "" {
-		return errors.New("A senha é obrigatório e não pode estar em branco")
+		validacao.Adicionar("senha", "usuario.senha_obrigatoria", "A senha é obrigatório e não pode estar em branco")
 	}
 
-	return nil
+	return validacao.Erro()
 }
 
 func (usuario *Usuario) formatar(etapa string) error {
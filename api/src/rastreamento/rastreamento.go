@@ -0,0 +1,156 @@
+// Package rastreamento integra a API ao OpenTelemetry. O rastreamento é
+// opcional: desligado, nenhum span é criado e os middlewares não entram na
+// cadeia das rotas.
+package rastreamento
+
+import (
+	"api/src/logs"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const nomeInstrumentacao = "api"
+
+// Exportadores aceitos em Configuracao.Exportador.
+const (
+	ExportadorNenhum = "none"
+	ExportadorOTLP   = "otlp"
+	ExportadorStdout = "stdout"
+)
+
+// Configuracao define para onde os spans são enviados. O endpoint do OTLP é
+// lido das variáveis padrão do OpenTelemetry, como OTEL_EXPORTER_OTLP_ENDPOINT.
+type Configuracao struct {
+	Exportador  string
+	NomeServico string
+	Amostragem  float64
+	// Saida recebe os spans do exportador stdout. Quando nula, usa os.Stdout.
+	Saida io.Writer
+}
+
+var tracer trace.Tracer
+
+// Habilitado indica se o rastreamento foi configurado com algum exportador.
+func Habilitado() bool {
+	return tracer != nil
+}
+
+// Configurar registra o provedor de traces e o propagador W3C traceparent. A
+// função retornada envia os spans pendentes e deve ser chamada no encerramento.
+func Configurar(ctx context.Context, configuracao Configuracao) (func(context.Context) error, error) {
+	var (
+		exportador sdktrace.SpanExporter
+		erro       error
+	)
+
+	switch strings.ToLower(configuracao.Exportador) {
+	case "", ExportadorNenhum:
+		tracer = nil
+		return func(context.Context) error { return nil }, nil
+	case ExportadorOTLP:
+		exportador, erro = otlptracehttp.New(ctx)
+	case ExportadorStdout:
+		opcoes := []stdouttrace.Option{}
+		if configuracao.Saida != nil {
+			opcoes = append(opcoes, stdouttrace.WithWriter(configuracao.Saida))
+		}
+		exportador, erro = stdouttrace.New(opcoes...)
+	default:
+		return nil, fmt.Errorf("exportador de traces inválido: %q", configuracao.Exportador)
+	}
+	if erro != nil {
+		return nil, erro
+	}
+
+	recurso, erro := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(configuracao.NomeServico),
+	))
+	if erro != nil {
+		return nil, erro
+	}
+
+	provedor := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exportador),
+		sdktrace.WithResource(recurso),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(configuracao.Amostragem))),
+	)
+
+	otel.SetTracerProvider(provedor)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	tracer = provedor.Tracer(nomeInstrumentacao)
+
+	return provedor.Shutdown, nil
+}
+
+// IniciarConsulta abre o span de uma chamada de repositório, com a operação
+// SQL principal como atributo. A função retornada encerra o span.
+func IniciarConsulta(ctx context.Context, consulta, operacao string) func() {
+	if tracer == nil {
+		return func() {}
+	}
+
+	_, span := tracer.Start(ctx, consulta,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(operacao),
+		),
+	)
+
+	return func() { span.End() }
+}
+
+// Middleware abre o span de servidor de cada requisição, continuando o trace
+// recebido no cabeçalho traceparent. O span recebe o nome do template da
+// rota, e não do caminho, para que as rotas com IDs sejam agrupadas.
+func Middleware(rota string, proximaFuncao http.HandlerFunc) http.HandlerFunc {
+	if tracer == nil {
+		return proximaFuncao
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method+" "+rota,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.HTTPRoute(rota),
+				semconv.URLPath(r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		ctx = logs.Enriquecer(ctx, "trace_id", span.SpanContext().TraceID().String())
+
+		rw := &respostaRastreada{ResponseWriter: w, status: http.StatusOK}
+		proximaFuncao(rw, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(rw.status))
+		if rw.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rw.status))
+		}
+	}
+}
+
+type respostaRastreada struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rw *respostaRastreada) WriteHeader(status int) {
+	rw.status = status
+	rw.ResponseWriter.WriteHeader(status)
+}
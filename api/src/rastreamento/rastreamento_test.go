@@ -0,0 +1,108 @@
+package rastreamento
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type spanExportado struct {
+	Name        string
+	SpanContext struct {
+		TraceID string
+		SpanID  string
+	}
+	Parent struct {
+		TraceID string
+		SpanID  string
+	}
+	Attributes []struct {
+		Key   string
+		Value struct {
+			Value any
+		}
+	}
+}
+
+func (s spanExportado) atributo(chave string) any {
+	for _, atributo := range s.Attributes {
+		if atributo.Key == chave {
+			return atributo.Value.Value
+		}
+	}
+
+	return nil
+}
+
+func lerSpans(t *testing.T, saida *bytes.Buffer) map[string]spanExportado {
+	spans := map[string]spanExportado{}
+	decoder := json.NewDecoder(saida)
+	for decoder.More() {
+		var span spanExportado
+		assert.NoError(t, decoder.Decode(&span))
+		spans[span.Name] = span
+	}
+
+	return spans
+}
+
+func TestMiddleware_WhenTraceparentIsSent_ExpectedServerAndRepositorySpansInSameTrace(t *testing.T) {
+	saida := &bytes.Buffer{}
+	encerrar, err := Configurar(context.Background(), Configuracao{Exportador: ExportadorStdout, NomeServico: "teste", Amostragem: 1, Saida: saida})
+	assert.NoError(t, err)
+	t.Cleanup(func() { Configurar(context.Background(), Configuracao{Exportador: ExportadorNenhum}) })
+
+	router := mux.NewRouter()
+	rota := "/publicacoes/{publicacaoId}"
+	router.HandleFunc(rota, Middleware(rota, func(w http.ResponseWriter, r *http.Request) {
+		IniciarConsulta(r.Context(), "publicacoes.buscar_por_id", "SELECT")()
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/publicacoes/42", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.NoError(t, encerrar(context.Background()))
+	spans := lerSpans(t, saida)
+
+	servidor, ok := spans["GET /publicacoes/{publicacaoId}"]
+	assert.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", servidor.SpanContext.TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", servidor.Parent.SpanID)
+	assert.Equal(t, rota, servidor.atributo("http.route"))
+	assert.Equal(t, float64(http.StatusInternalServerError), servidor.atributo("http.response.status_code"))
+
+	consulta, ok := spans["publicacoes.buscar_por_id"]
+	assert.True(t, ok)
+	assert.Equal(t, servidor.SpanContext.TraceID, consulta.SpanContext.TraceID)
+	assert.Equal(t, servidor.SpanContext.SpanID, consulta.Parent.SpanID)
+	assert.Equal(t, "SELECT", consulta.atributo("db.operation.name"))
+}
+
+func TestMiddleware_WhenTracingIsDisabled_ExpectedHandlerUnwrapped(t *testing.T) {
+	_, err := Configurar(context.Background(), Configuracao{Exportador: ExportadorNenhum})
+	assert.NoError(t, err)
+
+	var spanValido bool
+	handler := Middleware("/publicacoes", func(w http.ResponseWriter, r *http.Request) {
+		spanValido = trace.SpanContextFromContext(r.Context()).IsValid()
+	})
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/publicacoes", nil))
+
+	assert.False(t, Habilitado())
+	assert.False(t, spanValido)
+}
+
+func TestConfigurar_WhenExporterIsUnknown_ExpectedError(t *testing.T) {
+	_, err := Configurar(context.Background(), Configuracao{Exportador: "zipkin"})
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,143 @@
+package repositorios
+
+import (
+	"api/src/modelos"
+	"context"
+	"database/sql"
+)
+
+type ComentariosRepositorio interface {
+	Criar(ctx context.Context, comentario modelos.Comentario) (uint64, error)
+	BuscarPorID(ctx context.Context, comentarioID uint64) (modelos.Comentario, error)
+	BuscarPorPublicacao(ctx context.Context, publicacaoID uint64) ([]modelos.Comentario, error)
+	Atualizar(ctx context.Context, comentarioID uint64, comentario modelos.Comentario) error
+	Deletar(ctx context.Context, comentarioID uint64) error
+}
+
+type comentariosRepositorio struct {
+	db *sql.DB
+}
+
+func NovoRepositorioDeComentarios(db *sql.DB) ComentariosRepositorio {
+	return &comentariosRepositorio{db}
+}
+
+func (repositorio *comentariosRepositorio) Criar(ctx context.Context, comentario modelos.Comentario) (uint64, error) {
+	defer medir(ctx, "comentarios.criar", "INSERT")()
+
+	query := "INSERT INTO comentarios (publicacao_id, autor_id, conteudo) VALUES ($1, $2, $3) RETURNING id"
+	var ultimoIDInserido uint64
+
+	erro := repositorio.db.QueryRowContext(ctx, query, comentario.PublicacaoID, comentario.AutorID, comentario.Conteudo).Scan(&ultimoIDInserido)
+	if erro != nil {
+		return 0, traduzirErro(erro)
+	}
+
+	return ultimoIDInserido, nil
+}
+
+func (repositorio *comentariosRepositorio) BuscarPorID(ctx context.Context, comentarioID uint64) (modelos.Comentario, error) {
+	defer medir(ctx, "comentarios.buscar_por_id", "SELECT")()
+
+	linhas, erro := repositorio.db.QueryContext(ctx, `
+		SELECT c.id, c.publicacao_id, c.autor_id, u.nick, c.conteudo, c.criadoEm
+		FROM comentarios c INNER JOIN usuarios u
+		ON u.id = c.autor_id WHERE c.id = $1
+	`, comentarioID)
+	if erro != nil {
+		return modelos.Comentario{}, erro
+	}
+	defer linhas.Close()
+
+	var comentario modelos.Comentario
+
+	if !linhas.Next() {
+		return modelos.Comentario{}, nenhumaLinha(linhas)
+	}
+
+	if comentario, erro = escanearComentario(linhas); erro != nil {
+		return modelos.Comentario{}, erro
+	}
+
+	return comentario, nil
+}
+
+func (repositorio *comentariosRepositorio) BuscarPorPublicacao(ctx context.Context, publicacaoID uint64) ([]modelos.Comentario, error) {
+	defer medir(ctx, "comentarios.buscar_por_publicacao", "SELECT")()
+
+	linhas, erro := repositorio.db.QueryContext(ctx, `
+		SELECT c.id, c.publicacao_id, c.autor_id, u.nick, c.conteudo, c.criadoEm
+		FROM comentarios c INNER JOIN usuarios u
+		ON u.id = c.autor_id WHERE c.publicacao_id = $1
+		ORDER BY c.criadoEm, c.id
+	`, publicacaoID)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	comentarios := []modelos.Comentario{}
+
+	for linhas.Next() {
+		comentario, erro := escanearComentario(linhas)
+		if erro != nil {
+			return nil, erro
+		}
+		comentarios = append(comentarios, comentario)
+	}
+
+	if erro := linhas.Err(); erro != nil {
+		return nil, erro
+	}
+
+	return comentarios, nil
+}
+
+func (repositorio *comentariosRepositorio) Atualizar(ctx context.Context, comentarioID uint64, comentario modelos.Comentario) error {
+	defer medir(ctx, "comentarios.atualizar", "UPDATE")()
+
+	statement, erro := repositorio.db.PrepareContext(ctx, "UPDATE comentarios set conteudo = $1 WHERE id = $2")
+	if erro != nil {
+		return erro
+	}
+	defer statement.Close()
+
+	resultado, erro := statement.ExecContext(ctx, comentario.Conteudo, comentarioID)
+	if erro != nil {
+		return traduzirErro(erro)
+	}
+
+	return verificarLinhasAfetadas(resultado)
+}
+
+func (repositorio *comentariosRepositorio) Deletar(ctx context.Context, comentarioID uint64) error {
+	defer medir(ctx, "comentarios.deletar", "DELETE")()
+
+	statement, erro := repositorio.db.PrepareContext(ctx, "DELETE FROM comentarios WHERE id = $1")
+	if erro != nil {
+		return erro
+	}
+	defer statement.Close()
+
+	resultado, erro := statement.ExecContext(ctx, comentarioID)
+	if erro != nil {
+		return traduzirErro(erro)
+	}
+
+	return verificarLinhasAfetadas(resultado)
+}
+
+func escanearComentario(linhas *sql.Rows) (modelos.Comentario, error) {
+	var comentario modelos.Comentario
+
+	erro := linhas.Scan(
+		&comentario.ID,
+		&comentario.PublicacaoID,
+		&comentario.AutorID,
+		&comentario.AutorNick,
+		&comentario.Conteudo,
+		&comentario.CriadoEm,
+	)
+
+	return comentario, erro
+}
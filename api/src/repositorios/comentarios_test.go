@@ -0,0 +1,27 @@
+package repositorios
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuscarPorPublicacao_WhenReadingFailsMidway_ExpectedError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	linhas := sqlmock.NewRows([]string{"id", "publicacao_id", "autor_id", "nick", "conteudo", "criadoEm"}).
+		AddRow(1, 1, 2, "usuario_2", "Primeiro", time.Now()).
+		AddRow(2, 1, 3, "usuario_3", "Segundo", time.Now()).
+		RowError(1, context.DeadlineExceeded)
+	mock.ExpectQuery("SELECT c.id").WithArgs(uint64(1)).WillReturnRows(linhas)
+
+	comentarios, err := NovoRepositorioDeComentarios(db).BuscarPorPublicacao(context.Background(), 1)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, comentarios)
+}
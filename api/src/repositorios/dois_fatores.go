@@ -0,0 +1,160 @@
+package repositorios
+
+import (
+	"api/src/modelos"
+	"context"
+	"database/sql"
+)
+
+type DoisFatoresRepositorio interface {
+	Buscar(ctx context.Context, usuarioID uint64) (modelos.DoisFatores, error)
+	IniciarInscricao(ctx context.Context, usuarioID uint64, segredo string) error
+	Ativar(ctx context.Context, usuarioID uint64, passo int64, hashesCodigos []string) error
+	Desativar(ctx context.Context, usuarioID uint64) error
+	RegistrarPasso(ctx context.Context, usuarioID uint64, passo int64) (bool, error)
+	ConsumirCodigoRecuperacao(ctx context.Context, usuarioID uint64, hash string) (bool, error)
+}
+
+type doisFatoresRepositorio struct {
+	db *sql.DB
+}
+
+func NovoRepositorioDeDoisFatores(db *sql.DB) DoisFatoresRepositorio {
+	return &doisFatoresRepositorio{db}
+}
+
+func (repositorio *doisFatoresRepositorio) Buscar(ctx context.Context, usuarioID uint64) (modelos.DoisFatores, error) {
+	defer medir(ctx, "dois_fatores.buscar", "SELECT")()
+
+	var (
+		doisFatores modelos.DoisFatores
+		segredo     sql.NullString
+	)
+
+	erro := repositorio.db.QueryRowContext(ctx,
+		"SELECT totp_segredo, totp_ativo FROM usuarios WHERE id = $1", usuarioID,
+	).Scan(&segredo, &doisFatores.Ativo)
+	if erro != nil {
+		return modelos.DoisFatores{}, traduzirErro(erro)
+	}
+
+	doisFatores.Segredo = segredo.String
+	return doisFatores, nil
+}
+
+// IniciarInscricao troca o segredo de uma inscrição ainda não confirmada.
+func (repositorio *doisFatoresRepositorio) IniciarInscricao(ctx context.Context, usuarioID uint64, segredo string) error {
+	defer medir(ctx, "dois_fatores.iniciar_inscricao", "UPDATE")()
+
+	resultado, erro := repositorio.db.ExecContext(ctx,
+		"UPDATE usuarios set totp_segredo = $1, totp_ultimo_passo = 0 WHERE id = $2 AND NOT totp_ativo",
+		segredo, usuarioID,
+	)
+	if erro != nil {
+		return traduzirErro(erro)
+	}
+
+	return verificarLinhasAfetadas(resultado)
+}
+
+// Ativar confirma a inscrição e substitui os códigos de recuperação.
+func (repositorio *doisFatoresRepositorio) Ativar(ctx context.Context, usuarioID uint64, passo int64, hashesCodigos []string) error {
+	defer medir(ctx, "dois_fatores.ativar", "UPDATE")()
+
+	transacao, erro := repositorio.db.BeginTx(ctx, nil)
+	if erro != nil {
+		return erro
+	}
+	defer transacao.Rollback()
+
+	resultado, erro := transacao.ExecContext(ctx,
+		"UPDATE usuarios set totp_ativo = true, totp_ultimo_passo = $1 WHERE id = $2 AND totp_segredo IS NOT NULL AND NOT totp_ativo",
+		passo, usuarioID,
+	)
+	if erro != nil {
+		return erro
+	}
+	if erro = verificarLinhasAfetadas(resultado); erro != nil {
+		return erro
+	}
+
+	if _, erro = transacao.ExecContext(ctx, "DELETE FROM codigos_recuperacao WHERE usuario_id = $1", usuarioID); erro != nil {
+		return erro
+	}
+
+	for _, hash := range hashesCodigos {
+		if _, erro = transacao.ExecContext(ctx,
+			"INSERT INTO codigos_recuperacao (usuario_id, hash) VALUES ($1, $2)", usuarioID, hash,
+		); erro != nil {
+			return erro
+		}
+	}
+
+	return transacao.Commit()
+}
+
+// Desativar apaga o segredo e os códigos de recuperação.
+func (repositorio *doisFatoresRepositorio) Desativar(ctx context.Context, usuarioID uint64) error {
+	defer medir(ctx, "dois_fatores.desativar", "UPDATE")()
+
+	transacao, erro := repositorio.db.BeginTx(ctx, nil)
+	if erro != nil {
+		return erro
+	}
+	defer transacao.Rollback()
+
+	if _, erro = transacao.ExecContext(ctx,
+		"UPDATE usuarios set totp_segredo = NULL, totp_ativo = false, totp_ultimo_passo = 0 WHERE id = $1",
+		usuarioID,
+	); erro != nil {
+		return erro
+	}
+
+	if _, erro = transacao.ExecContext(ctx, "DELETE FROM codigos_recuperacao WHERE usuario_id = $1", usuarioID); erro != nil {
+		return erro
+	}
+
+	return transacao.Commit()
+}
+
+// RegistrarPasso guarda o passo do último código TOTP aceito. Retorna false
+// quando um código do mesmo passo, ou de um posterior, já foi usado, o que
+// impede que um código interceptado seja reaproveitado.
+func (repositorio *doisFatoresRepositorio) RegistrarPasso(ctx context.Context, usuarioID uint64, passo int64) (bool, error) {
+	defer medir(ctx, "dois_fatores.registrar_passo", "UPDATE")()
+
+	resultado, erro := repositorio.db.ExecContext(ctx,
+		"UPDATE usuarios set totp_ultimo_passo = $1 WHERE id = $2 AND totp_ultimo_passo < $1",
+		passo, usuarioID,
+	)
+	if erro != nil {
+		return false, erro
+	}
+
+	return afetouLinhas(resultado)
+}
+
+// ConsumirCodigoRecuperacao marca o código como usado. Retorna false quando o
+// código não existe ou já foi usado.
+func (repositorio *doisFatoresRepositorio) ConsumirCodigoRecuperacao(ctx context.Context, usuarioID uint64, hash string) (bool, error) {
+	defer medir(ctx, "dois_fatores.consumir_codigo_recuperacao", "UPDATE")()
+
+	resultado, erro := repositorio.db.ExecContext(ctx,
+		"UPDATE codigos_recuperacao set usadoEm = current_timestamp WHERE usuario_id = $1 AND hash = $2 AND usadoEm IS NULL",
+		usuarioID, hash,
+	)
+	if erro != nil {
+		return false, erro
+	}
+
+	return afetouLinhas(resultado)
+}
+
+func afetouLinhas(resultado sql.Result) (bool, error) {
+	linhasAfetadas, erro := resultado.RowsAffected()
+	if erro != nil {
+		return false, erro
+	}
+
+	return linhasAfetadas > 0, nil
+}
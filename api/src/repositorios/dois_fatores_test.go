@@ -0,0 +1,71 @@
+package repositorios
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtivar_WhenEnrollmentIsPending_ExpectedRecoveryCodesReplaced(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE usuarios set totp_ativo = true").
+		WithArgs(int64(56666666), uint64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM codigos_recuperacao").
+		WithArgs(uint64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO codigos_recuperacao").
+		WithArgs(uint64(1), "hash-1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO codigos_recuperacao").
+		WithArgs(uint64(1), "hash-2").
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	repositorio := NovoRepositorioDeDoisFatores(db)
+	err = repositorio.Ativar(context.Background(), 1, 56666666, []string{"hash-1", "hash-2"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAtivar_WhenAlreadyActive_ExpectedNotFoundAndRollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE usuarios set totp_ativo = true").
+		WithArgs(int64(56666666), uint64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	repositorio := NovoRepositorioDeDoisFatores(db)
+	err = repositorio.Ativar(context.Background(), 1, 56666666, []string{"hash-1"})
+
+	assert.ErrorIs(t, err, ErrNaoEncontrado)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRegistrarPasso_WhenStepWasAlreadyUsed_ExpectedFalse(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE usuarios set totp_ultimo_passo").
+		WithArgs(int64(56666666), uint64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	repositorio := NovoRepositorioDeDoisFatores(db)
+	aceito, err := repositorio.RegistrarPasso(context.Background(), 1, 56666666)
+
+	assert.NoError(t, err)
+	assert.False(t, aceito)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,68 @@
+package repositorios
+
+import (
+	"api/src/erros"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Erros de domínio retornados pelos repositórios. As mensagens do driver nunca
+// são expostas; respostas.ErroDeDominio converte estes erros no status HTTP adequado.
+var (
+	ErrNaoEncontrado = erros.Novo("recurso.nao_encontrado", "Registro não encontrado")
+	ErrConflito      = erros.Novo("recurso.conflito", "Já existe um registro com esses dados")
+)
+
+const (
+	codigoViolacaoUnicidade    = "23505"
+	codigoViolacaoChaveExterna = "23503"
+)
+
+// traduzirErro converte os erros do driver nos erros de domínio equivalentes.
+func traduzirErro(erro error) error {
+	if erro == nil {
+		return nil
+	}
+
+	if errors.Is(erro, sql.ErrNoRows) {
+		return ErrNaoEncontrado
+	}
+
+	var erroPostgres *pgconn.PgError
+	if errors.As(erro, &erroPostgres) {
+		switch erroPostgres.Code {
+		case codigoViolacaoUnicidade:
+			return ErrConflito
+		case codigoViolacaoChaveExterna:
+			return ErrNaoEncontrado
+		}
+	}
+
+	return erro
+}
+
+// nenhumaLinha deve ser chamada quando linhas.Next() retorna false em uma
+// busca que esperava encontrar um registro.
+func nenhumaLinha(linhas *sql.Rows) error {
+	if erro := linhas.Err(); erro != nil {
+		return erro
+	}
+
+	return ErrNaoEncontrado
+}
+
+// verificarLinhasAfetadas retorna ErrNaoEncontrado quando o comando não alterou nenhum registro.
+func verificarLinhasAfetadas(resultado sql.Result) error {
+	linhasAfetadas, erro := resultado.RowsAffected()
+	if erro != nil {
+		return erro
+	}
+
+	if linhasAfetadas == 0 {
+		return ErrNaoEncontrado
+	}
+
+	return nil
+}
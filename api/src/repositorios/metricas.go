@@ -0,0 +1,21 @@
+package repositorios
+
+import (
+	"api/src/metrics"
+	"api/src/rastreamento"
+	"context"
+	"time"
+)
+
+// medir inicia a medição de uma chamada de repositório: registra a duração no
+// histograma de consultas e, com o rastreamento ligado, abre um span com a
+// operação SQL principal. Uso: defer medir(ctx, "usuarios.criar", "INSERT")().
+func medir(ctx context.Context, consulta, operacao string) func() {
+	inicio := time.Now()
+	encerrarSpan := rastreamento.IniciarConsulta(ctx, consulta, operacao)
+
+	return func() {
+		encerrarSpan()
+		metrics.DuracaoConsultas.WithLabelValues(consulta).Observe(time.Since(inicio).Seconds())
+	}
+}
@@ -2,18 +2,20 @@ package repositorios
 
 import (
 	"api/src/modelos"
+	"context"
 	"database/sql"
 )
 
 type PublicacoesRepositorio interface {
-	Criar(publicacao modelos.Publicacao) (uint64, error)
-	BuscarPorId(publicacaoID uint64) (modelos.Publicacao, error)
-	BuscarPublicacoes(usuarioID uint64) ([]modelos.Publicacao, error)
-	Atualizar(publicacaoID uint64, publicacao modelos.Publicacao) error
-	DeletarPublicacao(publicacaoID uint64) error
-	BuscarPorUsuario(usuarioID uint64) ([]modelos.Publicacao, error)
-	Curtir(publicacaoID uint64) error
-	Descurtir(publicacaoID uint64) error
+	Criar(ctx context.Context, publicacao modelos.Publicacao) (uint64, error)
+	BuscarPorId(ctx context.Context, publicacaoID, usuarioID uint64) (modelos.Publicacao, error)
+	BuscarPublicacoes(ctx context.Context, usuarioID uint64, paginacao modelos.Paginacao) (modelos.PaginaDePublicacoes, error)
+	Atualizar(ctx context.Context, publicacaoID uint64, publicacao modelos.Publicacao) error
+	DeletarPublicacao(ctx context.Context, publicacaoID uint64) error
+	BuscarPorUsuario(ctx context.Context, autorID, usuarioID uint64, paginacao modelos.Paginacao) (modelos.PaginaDePublicacoes, error)
+	Curtir(ctx context.Context, publicacaoID, usuarioID uint64) error
+	Descurtir(ctx context.Context, publicacaoID, usuarioID uint64) error
+	BuscarCurtidas(ctx context.Context, publicacaoID uint64) ([]modelos.Usuario, error)
 }
 
 type publicacoesRepositorio struct {
@@ -24,24 +26,31 @@ func NovoRepositorioDePublicacoes(db *sql.DB) PublicacoesRepositorio {
 	return &publicacoesRepositorio{db}
 }
 
-func (repositorio *publicacoesRepositorio) Criar(publicacao modelos.Publicacao) (uint64, error) {
+func (repositorio *publicacoesRepositorio) Criar(ctx context.Context, publicacao modelos.Publicacao) (uint64, error) {
+	defer medir(ctx, "publicacoes.criar", "INSERT")()
+
 	query := "INSERT INTO publicacoes (titulo, conteudo, autor_id) VALUES ($1, $2, $3) RETURNING id"
 	var ultimoIDInserido uint64
 
-	erro := repositorio.db.QueryRow(query, publicacao.Titulo, publicacao.Conteudo, publicacao.AutorID).Scan(&ultimoIDInserido)
+	erro := repositorio.db.QueryRowContext(ctx, query, publicacao.Titulo, publicacao.Conteudo, publicacao.AutorID).Scan(&ultimoIDInserido)
 	if erro != nil {
-		return 0, erro
+		return 0, traduzirErro(erro)
 	}
 
 	return ultimoIDInserido, nil
 }
 
-func (repositorio *publicacoesRepositorio) BuscarPorId(publicacaoID uint64) (modelos.Publicacao, error) {
-	linhas, erro := repositorio.db.Query(`
-		SELECT p.*, u.nick FROM 
-		publicacoes p INNER JOIN usuarios u 
+func (repositorio *publicacoesRepositorio) BuscarPorId(ctx context.Context, publicacaoID, usuarioID uint64) (modelos.Publicacao, error) {
+	defer medir(ctx, "publicacoes.buscar_por_id", "SELECT")()
+
+	linhas, erro := repositorio.db.QueryContext(ctx, `
+		SELECT p.id, p.titulo, p.conteudo, p.autor_id, p.criadaEm, u.nick,
+		(SELECT COUNT(*) FROM curtidas c WHERE c.publicacao_id = p.id),
+		(SELECT COUNT(*) FROM comentarios c WHERE c.publicacao_id = p.id),
+		EXISTS (SELECT 1 FROM curtidas c WHERE c.publicacao_id = p.id AND c.usuario_id = $2)
+		FROM publicacoes p INNER JOIN usuarios u 
 		ON u.id = p.autor_id WHERE p.id = $1
-	`, publicacaoID)
+	`, publicacaoID, usuarioID)
 
 	if erro != nil {
 		return modelos.Publicacao{}, erro
@@ -50,147 +59,194 @@ func (repositorio *publicacoesRepositorio) BuscarPorId(publicacaoID uint64) (mod
 
 	var publicacao modelos.Publicacao
 
-	if linhas.Next() {
-		if erro = linhas.Scan(
-			&publicacao.ID,
-			&publicacao.Titulo,
-			&publicacao.Conteudo,
-			&publicacao.AutorID,
-			&publicacao.Curtidas,
-			&publicacao.CriadoEm,
-			&publicacao.AutorNick,
-		); erro != nil {
-			return modelos.Publicacao{}, erro
-		}
+	if !linhas.Next() {
+		return modelos.Publicacao{}, nenhumaLinha(linhas)
+	}
+
+	if publicacao, erro = escanearPublicacao(linhas); erro != nil {
+		return modelos.Publicacao{}, erro
 	}
 
 	return publicacao, nil
 }
 
-func (repositorio *publicacoesRepositorio) BuscarPublicacoes(usuarioID uint64) ([]modelos.Publicacao, error) {
-	linhas, erro := repositorio.db.Query(`
-	SELECT DISTINCT p.*, u.nick FROM publicacoes p 
+func (repositorio *publicacoesRepositorio) BuscarPublicacoes(ctx context.Context, usuarioID uint64, paginacao modelos.Paginacao) (modelos.PaginaDePublicacoes, error) {
+	defer medir(ctx, "publicacoes.buscar_publicacoes", "SELECT")()
+
+	linhas, erro := repositorio.db.QueryContext(ctx, `
+	SELECT p.id, p.titulo, p.conteudo, p.autor_id, p.criadaEm, u.nick,
+	(SELECT COUNT(*) FROM curtidas c WHERE c.publicacao_id = p.id),
+	(SELECT COUNT(*) FROM comentarios c WHERE c.publicacao_id = p.id),
+	EXISTS (SELECT 1 FROM curtidas c WHERE c.publicacao_id = p.id AND c.usuario_id = $1)
+	FROM publicacoes p 
 	INNER JOIN usuarios u ON u.id = p.autor_id 
-	INNER JOIN seguidores s ON p.autor_id = s.usuario_id 
-	WHERE u.id = $1 or s.seguidor_id = $2
-	ORDER BY 1 DESC`,
-		usuarioID, usuarioID,
+	WHERE (p.autor_id = $1 OR p.autor_id IN (SELECT s.usuario_id FROM seguidores s WHERE s.seguidor_id = $1))
+	AND ($2 OR (p.criadaEm, p.id) < ($3::timestamp, $4::int))
+	ORDER BY p.criadaEm DESC, p.id DESC
+	LIMIT $5`,
+		usuarioID, paginacao.PrimeiraPagina(), paginacao.CriadaEm, paginacao.ID, paginacao.Limite+1,
 	)
 	if erro != nil {
-		return nil, erro
+		return modelos.PaginaDePublicacoes{}, erro
 	}
 	defer linhas.Close()
 
-	var publicacoes []modelos.Publicacao
-
-	for linhas.Next() {
-		var publicacao modelos.Publicacao
-		if erro = linhas.Scan(
-			&publicacao.ID,
-			&publicacao.Titulo,
-			&publicacao.Conteudo,
-			&publicacao.AutorID,
-			&publicacao.Curtidas,
-			&publicacao.CriadoEm,
-			&publicacao.AutorNick,
-		); erro != nil {
-			return nil, erro
-		}
-		publicacoes = append(publicacoes, publicacao)
-	}
-
-	return publicacoes, nil
+	return montarPagina(linhas, paginacao.Limite)
 }
 
-func (repositorio *publicacoesRepositorio) Atualizar(publicacaoID uint64, publicacao modelos.Publicacao) error {
-	statement, erro := repositorio.db.Prepare("UPDATE publicacoes set titulo = $1, conteudo = $2 WHERE id = $3")
+func (repositorio *publicacoesRepositorio) Atualizar(ctx context.Context, publicacaoID uint64, publicacao modelos.Publicacao) error {
+	defer medir(ctx, "publicacoes.atualizar", "UPDATE")()
+
+	statement, erro := repositorio.db.PrepareContext(ctx, "UPDATE publicacoes set titulo = $1, conteudo = $2 WHERE id = $3")
 	if erro != nil {
 		return erro
 	}
 	defer statement.Close()
 
-	if _, erro = statement.Exec(publicacao.Titulo, publicacao.Conteudo, publicacaoID); erro != nil {
-		return erro
+	resultado, erro := statement.ExecContext(ctx, publicacao.Titulo, publicacao.Conteudo, publicacaoID)
+	if erro != nil {
+		return traduzirErro(erro)
 	}
 
-	return nil
+	return verificarLinhasAfetadas(resultado)
 }
 
-func (repositorio *publicacoesRepositorio) DeletarPublicacao(publicacaoID uint64) error {
-	statement, erro := repositorio.db.Prepare("DELETE FROM publicacoes WHERE id = $1")
+func (repositorio *publicacoesRepositorio) DeletarPublicacao(ctx context.Context, publicacaoID uint64) error {
+	defer medir(ctx, "publicacoes.deletar_publicacao", "DELETE")()
+
+	statement, erro := repositorio.db.PrepareContext(ctx, "DELETE FROM publicacoes WHERE id = $1")
 	if erro != nil {
 		return erro
 	}
 	defer statement.Close()
 
-	if _, erro = statement.Exec(publicacaoID); erro != nil {
-		return erro
+	resultado, erro := statement.ExecContext(ctx, publicacaoID)
+	if erro != nil {
+		return traduzirErro(erro)
 	}
 
-	return nil
+	return verificarLinhasAfetadas(resultado)
 }
 
-func (repositorio *publicacoesRepositorio) BuscarPorUsuario(usuarioID uint64) ([]modelos.Publicacao, error) {
-	linhas, erro := repositorio.db.Query(`
-	SELECT p.*, u.nick FROM publicacoes p 
+func (repositorio *publicacoesRepositorio) BuscarPorUsuario(ctx context.Context, autorID, usuarioID uint64, paginacao modelos.Paginacao) (modelos.PaginaDePublicacoes, error) {
+	defer medir(ctx, "publicacoes.buscar_por_usuario", "SELECT")()
+
+	linhas, erro := repositorio.db.QueryContext(ctx, `
+	SELECT p.id, p.titulo, p.conteudo, p.autor_id, p.criadaEm, u.nick,
+	(SELECT COUNT(*) FROM curtidas c WHERE c.publicacao_id = p.id),
+	(SELECT COUNT(*) FROM comentarios c WHERE c.publicacao_id = p.id),
+	EXISTS (SELECT 1 FROM curtidas c WHERE c.publicacao_id = p.id AND c.usuario_id = $6)
+	FROM publicacoes p 
 	INNER JOIN usuarios u ON u.id = p.autor_id 
-	WHERE p.autor_id = $1`,
-		usuarioID,
+	WHERE p.autor_id = $1
+	AND ($2 OR (p.criadaEm, p.id) < ($3::timestamp, $4::int))
+	ORDER BY p.criadaEm DESC, p.id DESC
+	LIMIT $5`,
+		autorID, paginacao.PrimeiraPagina(), paginacao.CriadaEm, paginacao.ID, paginacao.Limite+1, usuarioID,
 	)
 	if erro != nil {
-		return nil, erro
+		return modelos.PaginaDePublicacoes{}, erro
 	}
 	defer linhas.Close()
 
-	var publicacoes []modelos.Publicacao
-
-	for linhas.Next() {
-		var publicacao modelos.Publicacao
-		if erro = linhas.Scan(
-			&publicacao.ID,
-			&publicacao.Titulo,
-			&publicacao.Conteudo,
-			&publicacao.AutorID,
-			&publicacao.Curtidas,
-			&publicacao.CriadoEm,
-			&publicacao.AutorNick,
-		); erro != nil {
-			return nil, erro
-		}
-		publicacoes = append(publicacoes, publicacao)
-	}
-
-	return publicacoes, nil
+	return montarPagina(linhas, paginacao.Limite)
 }
 
-func (repositorio *publicacoesRepositorio) Curtir(publicacaoID uint64) error {
-	statement, erro := repositorio.db.Prepare("UPDATE publicacoes set curtidas = curtidas + 1 WHERE id = $1")
+func (repositorio *publicacoesRepositorio) Curtir(ctx context.Context, publicacaoID, usuarioID uint64) error {
+	defer medir(ctx, "publicacoes.curtir", "INSERT")()
+
+	statement, erro := repositorio.db.PrepareContext(ctx,
+		"INSERT INTO curtidas (publicacao_id, usuario_id) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+	)
 	if erro != nil {
 		return erro
 	}
 	defer statement.Close()
 
-	if _, erro = statement.Exec(publicacaoID); erro != nil {
-		return erro
+	if _, erro = statement.ExecContext(ctx, publicacaoID, usuarioID); erro != nil {
+		return traduzirErro(erro)
 	}
 
 	return nil
 }
 
-func (repositorio *publicacoesRepositorio) Descurtir(publicacaoID uint64) error {
-	statement, erro := repositorio.db.Prepare(`
-	UPDATE publicacoes set curtidas = 
-	CASE WHEN curtidas > 0 THEN curtidas - 1 
-	ELSE curtidas END 
-	WHERE id = $1`)
+func (repositorio *publicacoesRepositorio) Descurtir(ctx context.Context, publicacaoID, usuarioID uint64) error {
+	defer medir(ctx, "publicacoes.descurtir", "DELETE")()
+
+	statement, erro := repositorio.db.PrepareContext(ctx,
+		"DELETE FROM curtidas WHERE publicacao_id = $1 AND usuario_id = $2",
+	)
 	if erro != nil {
 		return erro
 	}
 	defer statement.Close()
 
-	if _, erro = statement.Exec(publicacaoID); erro != nil {
-		return erro
+	if _, erro = statement.ExecContext(ctx, publicacaoID, usuarioID); erro != nil {
+		return traduzirErro(erro)
 	}
 
 	return nil
 }
+
+func (repositorio *publicacoesRepositorio) BuscarCurtidas(ctx context.Context, publicacaoID uint64) ([]modelos.Usuario, error) {
+	defer medir(ctx, "publicacoes.buscar_curtidas", "SELECT")()
+
+	linhas, erro := repositorio.db.QueryContext(ctx, `
+	SELECT u.id, u.nome, u.nick, u.criadoEm
+	FROM usuarios u INNER JOIN curtidas c ON u.id = c.usuario_id
+	WHERE c.publicacao_id = $1
+	ORDER BY c.curtidaEm DESC`,
+		publicacaoID,
+	)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	return escanearUsuarios(linhas)
+}
+
+func escanearPublicacao(linhas *sql.Rows) (modelos.Publicacao, error) {
+	var publicacao modelos.Publicacao
+
+	erro := linhas.Scan(
+		&publicacao.ID,
+		&publicacao.Titulo,
+		&publicacao.Conteudo,
+		&publicacao.AutorID,
+		&publicacao.CriadoEm,
+		&publicacao.AutorNick,
+		&publicacao.Curtidas,
+		&publicacao.Comentarios,
+		&publicacao.CurtidoPorMim,
+	)
+
+	return publicacao, erro
+}
+
+// montarPagina lê até limite+1 linhas; a linha excedente só indica que existe
+// uma próxima página e não é retornada.
+func montarPagina(linhas *sql.Rows, limite int) (modelos.PaginaDePublicacoes, error) {
+	pagina := modelos.PaginaDePublicacoes{Publicacoes: []modelos.Publicacao{}}
+
+	for linhas.Next() {
+		publicacao, erro := escanearPublicacao(linhas)
+		if erro != nil {
+			return modelos.PaginaDePublicacoes{}, erro
+		}
+		pagina.Publicacoes = append(pagina.Publicacoes, publicacao)
+	}
+
+	// Um erro no meio da leitura, como o fim do prazo da consulta, encerra o
+	// laço sem avisar e deixaria a página incompleta, com o cursor errado.
+	if erro := linhas.Err(); erro != nil {
+		return modelos.PaginaDePublicacoes{}, erro
+	}
+
+	if len(pagina.Publicacoes) > limite {
+		pagina.Publicacoes = pagina.Publicacoes[:limite]
+		pagina.ProximoCursor = modelos.CodificarCursor(pagina.Publicacoes[limite-1])
+	}
+
+	return pagina, nil
+}
@@ -0,0 +1,180 @@
+package repositorios
+
+import (
+	"api/src/metrics"
+	"api/src/modelos"
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+var colunasPublicacao = []string{"id", "titulo", "conteudo", "autor_id", "criadaEm", "nick", "curtidas", "comentarios", "curtido_por_mim"}
+
+func TestBuscarPublicacoes_WhenThereAreMoreRowsThanLimit_ExpectedNextCursor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	criadaEm := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	linhas := sqlmock.NewRows(colunasPublicacao).
+		AddRow(3, "Título 3", "Conteúdo 3", 1, criadaEm, "usuario_1", 0, 0, false).
+		AddRow(2, "Título 2", "Conteúdo 2", 1, criadaEm, "usuario_1", 0, 0, false).
+		AddRow(1, "Título 1", "Conteúdo 1", 1, criadaEm, "usuario_1", 0, 0, false)
+	mock.ExpectQuery("SELECT p.id").WithArgs(uint64(1), true, time.Time{}, uint64(0), 3).WillReturnRows(linhas)
+
+	repositorio := NovoRepositorioDePublicacoes(db)
+	pagina, err := repositorio.BuscarPublicacoes(context.Background(), 1, modelos.Paginacao{Limite: 2})
+
+	assert.NoError(t, err)
+	assert.Len(t, pagina.Publicacoes, 2)
+	assert.Equal(t, modelos.CodificarCursor(pagina.Publicacoes[1]), pagina.ProximoCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBuscarPublicacoes_WhenLastPage_ExpectedNoCursor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	criadaEm := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	linhas := sqlmock.NewRows(colunasPublicacao).
+		AddRow(1, "Título 1", "Conteúdo 1", 1, criadaEm, "usuario_1", 0, 0, false)
+	mock.ExpectQuery("SELECT p.id").WithArgs(uint64(1), false, criadaEm, uint64(2), 3).WillReturnRows(linhas)
+
+	repositorio := NovoRepositorioDePublicacoes(db)
+	pagina, err := repositorio.BuscarPublicacoes(context.Background(), 1, modelos.Paginacao{Limite: 2, CriadaEm: criadaEm, ID: 2})
+
+	assert.NoError(t, err)
+	assert.Len(t, pagina.Publicacoes, 1)
+	assert.Empty(t, pagina.ProximoCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBuscarPublicacoes_WhenContextIsCanceled_ExpectedQueryAborted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT p.id").WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows(colunasPublicacao))
+
+	ctx, cancelar := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelar()
+
+	_, err = NovoRepositorioDePublicacoes(db).BuscarPublicacoes(ctx, 1, modelos.Paginacao{Limite: 2})
+
+	assert.Error(t, err)
+}
+
+func TestBuscarPorId_WhenPublicationDoesNotExist_ExpectedNotFoundError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT p.id").WithArgs(uint64(1), uint64(1)).WillReturnRows(sqlmock.NewRows(colunasPublicacao))
+
+	_, err = NovoRepositorioDePublicacoes(db).BuscarPorId(context.Background(), 1, 1)
+
+	assert.ErrorIs(t, err, ErrNaoEncontrado)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCurtir_WhenPublicationDoesNotExist_ExpectedNotFoundError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectPrepare("INSERT INTO curtidas").ExpectExec().WithArgs(uint64(1), uint64(1)).
+		WillReturnError(&pgconn.PgError{Code: codigoViolacaoChaveExterna})
+
+	err = NovoRepositorioDePublicacoes(db).Curtir(context.Background(), 1, 1)
+
+	assert.ErrorIs(t, err, ErrNaoEncontrado)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDescurtir_WhenQueryRuns_ExpectedDurationObservedByQueryName(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	antes := amostrasDaConsulta(t, "publicacoes.descurtir")
+
+	mock.ExpectPrepare("DELETE FROM curtidas").ExpectExec().WithArgs(uint64(1), uint64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = NovoRepositorioDePublicacoes(db).Descurtir(context.Background(), 1, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, antes+1, amostrasDaConsulta(t, "publicacoes.descurtir"))
+}
+
+func amostrasDaConsulta(t *testing.T, consulta string) uint64 {
+	var metrica dto.Metric
+	assert.NoError(t, metrics.DuracaoConsultas.WithLabelValues(consulta).(prometheus.Metric).Write(&metrica))
+	return metrica.GetHistogram().GetSampleCount()
+}
+
+func TestBuscarPublicacoes_WhenReadingFailsMidway_ExpectedErrorInsteadOfPartialPage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	criadaEm := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	linhas := sqlmock.NewRows(colunasPublicacao).
+		AddRow(3, "Título 3", "Conteúdo 3", 1, criadaEm, "usuario_1", 0, 0, false).
+		AddRow(2, "Título 2", "Conteúdo 2", 1, criadaEm, "usuario_1", 0, 0, false).
+		RowError(1, context.DeadlineExceeded)
+	mock.ExpectQuery("SELECT p.id").WillReturnRows(linhas)
+
+	pagina, err := NovoRepositorioDePublicacoes(db).BuscarPublicacoes(context.Background(), 1, modelos.Paginacao{Limite: 2})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Empty(t, pagina.Publicacoes)
+	assert.Empty(t, pagina.ProximoCursor)
+}
+
+func TestBuscarPorUsuario_WhenViewerLikedPublication_ExpectedCurtidoPorMim(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	criadaEm := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	linhas := sqlmock.NewRows(colunasPublicacao).
+		AddRow(1, "Título 1", "Conteúdo 1", 1, criadaEm, "usuario_1", 1, 0, true)
+	mock.ExpectQuery("SELECT p.id").WithArgs(uint64(1), true, time.Time{}, uint64(0), 3, uint64(2)).WillReturnRows(linhas)
+
+	repositorio := NovoRepositorioDePublicacoes(db)
+	pagina, err := repositorio.BuscarPorUsuario(context.Background(), 1, 2, modelos.Paginacao{Limite: 2})
+
+	assert.NoError(t, err)
+	assert.Len(t, pagina.Publicacoes, 1)
+	assert.True(t, pagina.Publicacoes[0].CurtidoPorMim)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBuscarCurtidas_WhenPostHasLikes_ExpectedEmailNotSelected(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	linhas := sqlmock.NewRows(colunasUsuario).AddRow(2, "Usuário 2", "usuario_2", time.Now())
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT u.id, u.nome, u.nick, u.criadoEm")).
+		WithArgs(uint64(1)).WillReturnRows(linhas)
+
+	usuarios, err := NovoRepositorioDePublicacoes(db).BuscarCurtidas(context.Background(), 1)
+	assert.NoError(t, err)
+
+	corpo, err := json.Marshal(usuarios)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(corpo), "email")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,86 @@
+package repositorios
+
+import (
+	"api/src/erros"
+	"api/src/modelos"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+var ErrTokenRedefinicaoInvalido = erros.Novo("senha.token_redefinicao_invalido", "Token de redefinição de senha inválido ou expirado")
+
+type RedefinicoesSenhaRepositorio interface {
+	Criar(ctx context.Context, redefinicao modelos.RedefinicaoSenha) error
+	Redefinir(ctx context.Context, hash, senha string) error
+}
+
+type redefinicoesSenhaRepositorio struct {
+	db *sql.DB
+}
+
+func NovoRepositorioDeRedefinicoesSenha(db *sql.DB) RedefinicoesSenhaRepositorio {
+	return &redefinicoesSenhaRepositorio{db}
+}
+
+// Criar grava o novo token e descarta os que o usuário ainda não usou, para
+// que apenas o último link enviado funcione.
+func (repositorio *redefinicoesSenhaRepositorio) Criar(ctx context.Context, redefinicao modelos.RedefinicaoSenha) error {
+	defer medir(ctx, "redefinicoes_senha.criar", "INSERT")()
+
+	transacao, erro := repositorio.db.BeginTx(ctx, nil)
+	if erro != nil {
+		return erro
+	}
+	defer transacao.Rollback()
+
+	if _, erro = transacao.ExecContext(ctx,
+		"DELETE FROM redefinicoes_senha WHERE usuario_id = $1 AND usadoEm IS NULL",
+		redefinicao.UsuarioID,
+	); erro != nil {
+		return erro
+	}
+
+	if _, erro = transacao.ExecContext(ctx,
+		"INSERT INTO redefinicoes_senha (usuario_id, hash, expiraEm) VALUES ($1, $2, current_timestamp + make_interval(secs => $3))",
+		redefinicao.UsuarioID, redefinicao.Hash, redefinicao.Validade.Seconds(),
+	); erro != nil {
+		return traduzirErro(erro)
+	}
+
+	return transacao.Commit()
+}
+
+// Redefinir marca o token como usado e troca a senha do dono dele na mesma
+// transação, para que uma falha na troca não gaste o token. Tokens usados,
+// expirados ou desconhecidos resultam em ErrTokenRedefinicaoInvalido.
+func (repositorio *redefinicoesSenhaRepositorio) Redefinir(ctx context.Context, hash, senha string) error {
+	defer medir(ctx, "redefinicoes_senha.redefinir", "UPDATE")()
+
+	transacao, erro := repositorio.db.BeginTx(ctx, nil)
+	if erro != nil {
+		return erro
+	}
+	defer transacao.Rollback()
+
+	var usuarioID uint64
+
+	erro = transacao.QueryRowContext(ctx, `
+	UPDATE redefinicoes_senha set usadoEm = current_timestamp
+	WHERE hash = $1 AND usadoEm IS NULL AND expiraEm > current_timestamp
+	RETURNING usuario_id`,
+		hash,
+	).Scan(&usuarioID)
+	if errors.Is(erro, sql.ErrNoRows) {
+		return ErrTokenRedefinicaoInvalido
+	}
+	if erro != nil {
+		return erro
+	}
+
+	if erro = trocarSenha(ctx, transacao, usuarioID, senha); erro != nil {
+		return erro
+	}
+
+	return transacao.Commit()
+}
@@ -0,0 +1,95 @@
+package repositorios
+
+import (
+	"api/src/modelos"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCriarRedefinicao_WhenUserHasPendingTokens_ExpectedThemDiscarded(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM redefinicoes_senha WHERE usuario_id").
+		WithArgs(uint64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO redefinicoes_senha").
+		WithArgs(uint64(1), "hash", float64(3600)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	repositorio := NovoRepositorioDeRedefinicoesSenha(db)
+	err = repositorio.Criar(context.Background(), modelos.RedefinicaoSenha{UsuarioID: 1, Hash: "hash", Validade: time.Hour})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedefinir_WhenTokenIsUsedOrExpired_ExpectedInvalidTokenError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE redefinicoes_senha set usadoEm").
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"usuario_id"}))
+	mock.ExpectRollback()
+
+	repositorio := NovoRepositorioDeRedefinicoesSenha(db)
+	err = repositorio.Redefinir(context.Background(), "hash", "senha-com-hash")
+
+	assert.ErrorIs(t, err, ErrTokenRedefinicaoInvalido)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedefinir_WhenPasswordUpdateFails_ExpectedTokenKept(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE redefinicoes_senha set usadoEm").
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"usuario_id"}).AddRow(1))
+	mock.ExpectExec("UPDATE usuarios set senha").
+		WithArgs("senha-com-hash", uint64(1)).
+		WillReturnError(context.DeadlineExceeded)
+	mock.ExpectRollback()
+
+	repositorio := NovoRepositorioDeRedefinicoesSenha(db)
+	err = repositorio.Redefinir(context.Background(), "hash", "senha-com-hash")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedefinir_WhenTokenIsValid_ExpectedPasswordChangedAndSessionsRevokedInOneTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE redefinicoes_senha set usadoEm").
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"usuario_id"}).AddRow(1))
+	mock.ExpectExec("UPDATE usuarios set senha").
+		WithArgs("senha-com-hash", uint64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE tokens_atualizacao set revogadoEm").
+		WithArgs(uint64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	repositorio := NovoRepositorioDeRedefinicoesSenha(db)
+	err = repositorio.Redefinir(context.Background(), "hash", "senha-com-hash")
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,116 @@
+package repositorios
+
+import (
+	"api/src/modelos"
+	"context"
+	"database/sql"
+)
+
+type SeguidoresRepositorio interface {
+	Seguir(ctx context.Context, usuarioID, seguidorID uint64) error
+	PararDeSeguir(ctx context.Context, usuarioID, seguidorID uint64) error
+	BuscarSeguidores(ctx context.Context, usuarioID uint64) ([]modelos.Usuario, error)
+	BuscarSeguindo(ctx context.Context, usuarioID uint64) ([]modelos.Usuario, error)
+}
+
+type seguidoresRepositorio struct {
+	db *sql.DB
+}
+
+func NovoRepositorioDeSeguidores(db *sql.DB) SeguidoresRepositorio {
+	return &seguidoresRepositorio{db}
+}
+
+func (repositorio *seguidoresRepositorio) Seguir(ctx context.Context, usuarioID, seguidorID uint64) error {
+	defer medir(ctx, "seguidores.seguir", "INSERT")()
+
+	statement, erro := repositorio.db.PrepareContext(ctx,
+		"INSERT INTO seguidores (usuario_id, seguidor_id) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+	)
+	if erro != nil {
+		return erro
+	}
+	defer statement.Close()
+
+	if _, erro = statement.ExecContext(ctx, usuarioID, seguidorID); erro != nil {
+		return traduzirErro(erro)
+	}
+
+	return nil
+}
+
+func (repositorio *seguidoresRepositorio) PararDeSeguir(ctx context.Context, usuarioID, seguidorID uint64) error {
+	defer medir(ctx, "seguidores.parar_de_seguir", "DELETE")()
+
+	statement, erro := repositorio.db.PrepareContext(ctx,
+		"DELETE FROM seguidores WHERE usuario_id = $1 AND seguidor_id = $2",
+	)
+	if erro != nil {
+		return erro
+	}
+	defer statement.Close()
+
+	if _, erro = statement.ExecContext(ctx, usuarioID, seguidorID); erro != nil {
+		return traduzirErro(erro)
+	}
+
+	return nil
+}
+
+func (repositorio *seguidoresRepositorio) BuscarSeguidores(ctx context.Context, usuarioID uint64) ([]modelos.Usuario, error) {
+	defer medir(ctx, "seguidores.buscar_seguidores", "SELECT")()
+
+	linhas, erro := repositorio.db.QueryContext(ctx, `
+	SELECT u.id, u.nome, u.nick, u.criadoEm
+	FROM usuarios u INNER JOIN seguidores s ON u.id = s.seguidor_id
+	WHERE s.usuario_id = $1`,
+		usuarioID,
+	)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	return escanearUsuarios(linhas)
+}
+
+func (repositorio *seguidoresRepositorio) BuscarSeguindo(ctx context.Context, usuarioID uint64) ([]modelos.Usuario, error) {
+	defer medir(ctx, "seguidores.buscar_seguindo", "SELECT")()
+
+	linhas, erro := repositorio.db.QueryContext(ctx, `
+	SELECT u.id, u.nome, u.nick, u.criadoEm
+	FROM usuarios u INNER JOIN seguidores s ON u.id = s.usuario_id
+	WHERE s.seguidor_id = $1`,
+		usuarioID,
+	)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	return escanearUsuarios(linhas)
+}
+
+// escanearUsuarios lê a projeção pública dos usuários, sem o email.
+func escanearUsuarios(linhas *sql.Rows) ([]modelos.Usuario, error) {
+	usuarios := []modelos.Usuario{}
+
+	for linhas.Next() {
+		var usuario modelos.Usuario
+		if erro := linhas.Scan(
+			&usuario.ID,
+			&usuario.Nome,
+			&usuario.Nick,
+			&usuario.CriadoEm,
+		); erro != nil {
+			return nil, erro
+		}
+		usuarios = append(usuarios, usuario)
+	}
+
+	if erro := linhas.Err(); erro != nil {
+		return nil, erro
+	}
+
+	return usuarios, nil
+}
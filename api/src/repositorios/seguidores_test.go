@@ -0,0 +1,64 @@
+package repositorios
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var colunasUsuario = []string{"id", "nome", "nick", "criadoEm"}
+
+func TestBuscarSeguidores_WhenReadingFailsMidway_ExpectedError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	linhas := sqlmock.NewRows(colunasUsuario).
+		AddRow(2, "Usuário 2", "usuario_2", time.Now()).
+		AddRow(3, "Usuário 3", "usuario_3", time.Now()).
+		RowError(1, context.DeadlineExceeded)
+	mock.ExpectQuery("SELECT u.id").WithArgs(uint64(1)).WillReturnRows(linhas)
+
+	seguidores, err := NovoRepositorioDeSeguidores(db).BuscarSeguidores(context.Background(), 1)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, seguidores)
+}
+
+func TestBuscarSeguidores_WhenUserHasNoFollowers_ExpectedEmptyListInsteadOfNull(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT u.id").WithArgs(uint64(1)).WillReturnRows(sqlmock.NewRows(colunasUsuario))
+
+	seguidores, err := NovoRepositorioDeSeguidores(db).BuscarSeguidores(context.Background(), 1)
+	assert.NoError(t, err)
+
+	corpo, err := json.Marshal(seguidores)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[]`, string(corpo))
+}
+
+func TestBuscarSeguindo_WhenUserFollowsSomeone_ExpectedEmailNotSelected(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	linhas := sqlmock.NewRows(colunasUsuario).AddRow(2, "Usuário 2", "usuario_2", time.Now())
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT u.id, u.nome, u.nick, u.criadoEm")).
+		WithArgs(uint64(1)).WillReturnRows(linhas)
+
+	seguindo, err := NovoRepositorioDeSeguidores(db).BuscarSeguindo(context.Background(), 1)
+	assert.NoError(t, err)
+
+	corpo, err := json.Marshal(seguindo)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(corpo), "email")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
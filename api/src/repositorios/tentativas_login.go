@@ -0,0 +1,150 @@
+package repositorios
+
+import (
+	"api/src/modelos"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// classeTravaLogin separa as travas de login das demais travas consultivas
+// do banco, como a das migrações.
+const classeTravaLogin = 1001
+
+type TentativasLoginRepositorio interface {
+	// Iniciar grava a tentativa como falha e retorna as falhas recentes da
+	// conta e do IP, nessa ordem, e o ID da tentativa. O resultado definitivo
+	// é gravado com Concluir.
+	Iniciar(ctx context.Context, tentativa modelos.TentativaLogin, janela time.Duration) (uint64, modelos.FalhasDeLogin, modelos.FalhasDeLogin, error)
+	Concluir(ctx context.Context, tentativa modelos.TentativaLogin) error
+	// Descartar apaga uma tentativa iniciada que não chegou a ser julgada.
+	Descartar(ctx context.Context, tentativaID uint64) error
+	Registrar(ctx context.Context, tentativa modelos.TentativaLogin) error
+}
+
+type tentativasLoginRepositorio struct {
+	db *sql.DB
+}
+
+func NovoRepositorioDeTentativasLogin(db *sql.DB) TentativasLoginRepositorio {
+	return &tentativasLoginRepositorio{db}
+}
+
+// Iniciar conta as falhas e grava a nova tentativa numa transação curta,
+// serializada por uma trava consultiva do email. Sem ela, requisições
+// simultâneas leriam a mesma contagem antes que qualquer uma fosse gravada e
+// passariam do limite da política. Como a tentativa já entra como falha, a
+// trava não precisa durar até a conferência da senha, e nenhuma conexão fica
+// presa enquanto o resto do login consulta o banco.
+func (repositorio *tentativasLoginRepositorio) Iniciar(ctx context.Context, tentativa modelos.TentativaLogin, janela time.Duration) (uint64, modelos.FalhasDeLogin, modelos.FalhasDeLogin, error) {
+	defer medir(ctx, "tentativas_login.iniciar", "INSERT")()
+
+	transacao, erro := repositorio.db.BeginTx(ctx, nil)
+	if erro != nil {
+		return 0, modelos.FalhasDeLogin{}, modelos.FalhasDeLogin{}, erro
+	}
+	defer transacao.Rollback()
+
+	if _, erro = transacao.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1, hashtext($2))", classeTravaLogin, tentativa.Email); erro != nil {
+		return 0, modelos.FalhasDeLogin{}, modelos.FalhasDeLogin{}, erro
+	}
+
+	conta, doIP, erro := resumirFalhas(ctx, transacao, tentativa.Email, tentativa.IP, janela)
+	if erro != nil {
+		return 0, modelos.FalhasDeLogin{}, modelos.FalhasDeLogin{}, erro
+	}
+
+	var tentativaID uint64
+	erro = transacao.QueryRowContext(ctx,
+		"INSERT INTO tentativas_login (email, usuario_id, ip, resultado) VALUES ($1, $2, $3, $4) RETURNING id",
+		tentativa.Email, idOuNulo(tentativa.UsuarioID), tentativa.IP, modelos.ResultadoLoginFalha,
+	).Scan(&tentativaID)
+	if erro != nil {
+		return 0, modelos.FalhasDeLogin{}, modelos.FalhasDeLogin{}, traduzirErro(erro)
+	}
+
+	if erro = transacao.Commit(); erro != nil {
+		return 0, modelos.FalhasDeLogin{}, modelos.FalhasDeLogin{}, erro
+	}
+
+	return tentativaID, conta, doIP, nil
+}
+
+func (repositorio *tentativasLoginRepositorio) Concluir(ctx context.Context, tentativa modelos.TentativaLogin) error {
+	defer medir(ctx, "tentativas_login.concluir", "UPDATE")()
+
+	resultado, erro := repositorio.db.ExecContext(ctx,
+		"UPDATE tentativas_login set resultado = $2, usuario_id = $3 WHERE id = $1",
+		tentativa.ID, tentativa.Resultado, idOuNulo(tentativa.UsuarioID),
+	)
+	if erro != nil {
+		return traduzirErro(erro)
+	}
+
+	return verificarLinhasAfetadas(resultado)
+}
+
+func (repositorio *tentativasLoginRepositorio) Descartar(ctx context.Context, tentativaID uint64) error {
+	defer medir(ctx, "tentativas_login.descartar", "DELETE")()
+
+	resultado, erro := repositorio.db.ExecContext(ctx, "DELETE FROM tentativas_login WHERE id = $1", tentativaID)
+	if erro != nil {
+		return traduzirErro(erro)
+	}
+
+	return verificarLinhasAfetadas(resultado)
+}
+
+func (repositorio *tentativasLoginRepositorio) Registrar(ctx context.Context, tentativa modelos.TentativaLogin) error {
+	defer medir(ctx, "tentativas_login.registrar", "INSERT")()
+
+	_, erro := repositorio.db.ExecContext(ctx,
+		"INSERT INTO tentativas_login (email, usuario_id, ip, resultado, responsavel_id) VALUES ($1, $2, $3, $4, $5)",
+		tentativa.Email, idOuNulo(tentativa.UsuarioID), tentativa.IP, tentativa.Resultado, idOuNulo(tentativa.ResponsavelID),
+	)
+
+	return traduzirErro(erro)
+}
+
+// resumirFalhas conta as falhas dentro da janela. As falhas da conta são
+// contadas só depois do último login com sucesso ou desbloqueio; as do IP não
+// são zeradas por um sucesso, para que o atacante não as zere entrando na
+// própria conta. Os tempos são calculados pelo relógio do banco.
+func resumirFalhas(ctx context.Context, transacao *sql.Tx, email, ip string, janela time.Duration) (modelos.FalhasDeLogin, modelos.FalhasDeLogin, error) {
+	var (
+		conta, doIP                   modelos.FalhasDeLogin
+		desdeFalhaConta, desdeFalhaIP float64
+	)
+
+	erro := transacao.QueryRowContext(ctx, `
+	WITH reinicio AS (
+		SELECT coalesce(max(criadoEm), '-infinity'::timestamp) AS em
+		FROM tentativas_login
+		WHERE email = $1 AND resultado IN ('sucesso', 'desbloqueio')
+	), falhas AS (
+		SELECT email = $1 AND criadoEm > (SELECT em FROM reinicio) AS da_conta, ip = $2 AS do_ip, criadoEm
+		FROM tentativas_login
+		WHERE resultado = 'falha' AND (email = $1 OR ip = $2)
+		AND criadoEm > current_timestamp - make_interval(secs => $3)
+	)
+	SELECT
+		count(*) FILTER (WHERE da_conta),
+		coalesce(extract(epoch FROM current_timestamp - max(criadoEm) FILTER (WHERE da_conta)), 0)::float8,
+		count(*) FILTER (WHERE do_ip),
+		coalesce(extract(epoch FROM current_timestamp - max(criadoEm) FILTER (WHERE do_ip)), 0)::float8
+	FROM falhas`,
+		email, ip, janela.Seconds(),
+	).Scan(&conta.Quantidade, &desdeFalhaConta, &doIP.Quantidade, &desdeFalhaIP)
+	if erro != nil {
+		return modelos.FalhasDeLogin{}, modelos.FalhasDeLogin{}, erro
+	}
+
+	conta.DesdeUltima = time.Duration(desdeFalhaConta * float64(time.Second))
+	doIP.DesdeUltima = time.Duration(desdeFalhaIP * float64(time.Second))
+
+	return conta, doIP, nil
+}
+
+func idOuNulo(id uint64) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(id), Valid: id != 0}
+}
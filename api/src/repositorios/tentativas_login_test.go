@@ -0,0 +1,93 @@
+package repositorios
+
+import (
+	"api/src/modelos"
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistrar_WhenEmailHasNoUser_ExpectedNullUserID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO tentativas_login").
+		WithArgs("naoexiste@teste.com", sql.NullInt64{}, "192.0.2.1", modelos.ResultadoLoginFalha, sql.NullInt64{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	repositorio := NovoRepositorioDeTentativasLogin(db)
+	err = repositorio.Registrar(context.Background(), modelos.TentativaLogin{
+		Email:     "naoexiste@teste.com",
+		IP:        "192.0.2.1",
+		Resultado: modelos.ResultadoLoginFalha,
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIniciar_WhenThereAreRecentFailures_ExpectedCountsAndAttemptRecordedAsFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1, hashtext($2))")).
+		WithArgs(classeTravaLogin, "usuario@teste.com").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("WITH reinicio AS").
+		WithArgs("usuario@teste.com", "192.0.2.1", float64(900)).
+		WillReturnRows(sqlmock.NewRows([]string{"falhas_conta", "desde_conta", "falhas_ip", "desde_ip"}).AddRow(3, 1.5, 8, 0.25))
+	mock.ExpectQuery("INSERT INTO tentativas_login").
+		WithArgs("usuario@teste.com", sql.NullInt64{}, "192.0.2.1", modelos.ResultadoLoginFalha).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	mock.ExpectCommit()
+
+	repositorio := NovoRepositorioDeTentativasLogin(db)
+	tentativaID, conta, ip, err := repositorio.Iniciar(context.Background(), modelos.TentativaLogin{Email: "usuario@teste.com", IP: "192.0.2.1"}, 15*time.Minute)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), tentativaID)
+	assert.Equal(t, modelos.FalhasDeLogin{Quantidade: 3, DesdeUltima: 1500 * time.Millisecond}, conta)
+	assert.Equal(t, modelos.FalhasDeLogin{Quantidade: 8, DesdeUltima: 250 * time.Millisecond}, ip)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIniciar_WhenLockFails_ExpectedErrorAndTransactionRolledBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1, hashtext($2))")).
+		WillReturnError(context.DeadlineExceeded)
+	mock.ExpectRollback()
+
+	repositorio := NovoRepositorioDeTentativasLogin(db)
+	_, _, _, err = repositorio.Iniciar(context.Background(), modelos.TentativaLogin{Email: "usuario@teste.com", IP: "192.0.2.1"}, 15*time.Minute)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConcluir_WhenAttemptDoesNotExist_ExpectedNotFoundError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE tentativas_login set resultado").
+		WithArgs(uint64(5), modelos.ResultadoLoginSucesso, sql.NullInt64{Int64: 7, Valid: true}).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	repositorio := NovoRepositorioDeTentativasLogin(db)
+	err = repositorio.Concluir(context.Background(), modelos.TentativaLogin{ID: 5, UsuarioID: 7, Resultado: modelos.ResultadoLoginSucesso})
+
+	assert.ErrorIs(t, err, ErrNaoEncontrado)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
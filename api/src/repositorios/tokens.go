@@ -0,0 +1,148 @@
+package repositorios
+
+import (
+	"api/src/erros"
+	"api/src/modelos"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+var (
+	ErrTokenAtualizacaoInvalido    = erros.Novo("autenticacao.token_atualizacao_invalido", "Token de atualização inválido ou expirado")
+	ErrTokenAtualizacaoReutilizado = erros.Novo("autenticacao.token_atualizacao_reutilizado", "Token de atualização reutilizado; a sessão foi encerrada")
+)
+
+const inserirToken = "INSERT INTO tokens_atualizacao (usuario_id, familia, hash, expiraEm) VALUES ($1, $2, $3, current_timestamp + make_interval(secs => $4))"
+
+type TokensRepositorio interface {
+	Criar(ctx context.Context, token modelos.TokenAtualizacao) error
+	Rotacionar(ctx context.Context, hash string, novoToken func(consumido modelos.TokenAtualizacao, versaoToken uint64) (modelos.TokenAtualizacao, error)) error
+	RevogarFamilia(ctx context.Context, hash string) error
+}
+
+type tokensRepositorio struct {
+	db *sql.DB
+}
+
+func NovoRepositorioDeTokens(db *sql.DB) TokensRepositorio {
+	return &tokensRepositorio{db}
+}
+
+func (repositorio *tokensRepositorio) Criar(ctx context.Context, token modelos.TokenAtualizacao) error {
+	defer medir(ctx, "tokens.criar", "INSERT")()
+
+	statement, erro := repositorio.db.PrepareContext(ctx, inserirToken)
+	if erro != nil {
+		return erro
+	}
+	defer statement.Close()
+
+	if _, erro = statement.ExecContext(ctx, token.UsuarioID, token.Familia, token.Hash, token.Validade.Seconds()); erro != nil {
+		return traduzirErro(erro)
+	}
+
+	return nil
+}
+
+// Rotacionar consome o token e grava o token gerado por novoToken numa única
+// transação, que também lê a versão atual do token do dono, para que uma falha ao gerar ou gravar o novo token não deixe o
+// usado sem substituto. Um token que já havia sido usado indica roubo: toda a
+// sua família é revogada e ErrTokenAtualizacaoReutilizado é retornado.
+func (repositorio *tokensRepositorio) Rotacionar(ctx context.Context, hash string, novoToken func(consumido modelos.TokenAtualizacao, versaoToken uint64) (modelos.TokenAtualizacao, error)) error {
+	defer medir(ctx, "tokens.rotacionar", "UPDATE")()
+
+	transacao, erro := repositorio.db.BeginTx(ctx, nil)
+	if erro != nil {
+		return erro
+	}
+	defer transacao.Rollback()
+
+	var consumido modelos.TokenAtualizacao
+
+	erro = transacao.QueryRowContext(ctx, `
+	UPDATE tokens_atualizacao set usadoEm = current_timestamp
+	WHERE hash = $1 AND usadoEm IS NULL AND revogadoEm IS NULL AND expiraEm > current_timestamp
+	RETURNING id, usuario_id, familia, hash`,
+		hash,
+	).Scan(&consumido.ID, &consumido.UsuarioID, &consumido.Familia, &consumido.Hash)
+	if errors.Is(erro, sql.ErrNoRows) {
+		transacao.Rollback()
+		return repositorio.recusar(ctx, hash)
+	}
+	if erro != nil {
+		return erro
+	}
+
+	// A versão é lida com FOR SHARE para que uma troca de senha espere a
+	// rotação terminar, em vez de passar despercebida entre a leitura e o commit.
+	var versaoToken uint64
+	erro = transacao.QueryRowContext(ctx,
+		"SELECT versao_token FROM usuarios WHERE id = $1 FOR SHARE", consumido.UsuarioID,
+	).Scan(&versaoToken)
+	if errors.Is(erro, sql.ErrNoRows) {
+		return ErrTokenAtualizacaoInvalido
+	}
+	if erro != nil {
+		return erro
+	}
+
+	novo, erro := novoToken(consumido, versaoToken)
+	if erro != nil {
+		return erro
+	}
+
+	if _, erro = transacao.ExecContext(ctx, inserirToken,
+		novo.UsuarioID, novo.Familia, novo.Hash, novo.Validade.Seconds(),
+	); erro != nil {
+		return traduzirErro(erro)
+	}
+
+	return transacao.Commit()
+}
+
+// recusar explica por que o token não pôde ser consumido, revogando a família
+// quando ele já havia sido usado.
+func (repositorio *tokensRepositorio) recusar(ctx context.Context, hash string) error {
+	var usado bool
+	erro := repositorio.db.QueryRowContext(ctx,
+		"SELECT usadoEm IS NOT NULL FROM tokens_atualizacao WHERE hash = $1", hash,
+	).Scan(&usado)
+	if errors.Is(erro, sql.ErrNoRows) {
+		return ErrTokenAtualizacaoInvalido
+	}
+	if erro != nil {
+		return erro
+	}
+
+	if !usado {
+		return ErrTokenAtualizacaoInvalido
+	}
+
+	if erro = repositorio.RevogarFamilia(ctx, hash); erro != nil {
+		return erro
+	}
+
+	return ErrTokenAtualizacaoReutilizado
+}
+
+// RevogarFamilia revoga todos os tokens da mesma família do token informado.
+func (repositorio *tokensRepositorio) RevogarFamilia(ctx context.Context, hash string) error {
+	defer medir(ctx, "tokens.revogar_familia", "UPDATE")()
+
+	statement, erro := repositorio.db.PrepareContext(ctx, `
+	UPDATE tokens_atualizacao set revogadoEm = current_timestamp
+	WHERE revogadoEm IS NULL
+	AND familia = (SELECT familia FROM tokens_atualizacao WHERE hash = $1)`,
+	)
+	if erro != nil {
+		return erro
+	}
+	defer statement.Close()
+
+	if _, erro = statement.ExecContext(ctx, hash); erro != nil {
+		return traduzirErro(erro)
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package repositorios
+
+import (
+	"api/src/modelos"
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotacionar_WhenTokenWasAlreadyUsed_ExpectedFamilyRevoked(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE tokens_atualizacao set usadoEm").WithArgs("hash").WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+	mock.ExpectQuery("SELECT usadoEm IS NOT NULL").WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"usado"}).AddRow(true))
+	mock.ExpectPrepare("UPDATE tokens_atualizacao set revogadoEm").
+		ExpectExec().WithArgs("hash").WillReturnResult(sqlmock.NewResult(0, 3))
+
+	err = NovoRepositorioDeTokens(db).Rotacionar(context.Background(), "hash", naoDeveGerar(t))
+
+	assert.ErrorIs(t, err, ErrTokenAtualizacaoReutilizado)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRotacionar_WhenTokenDoesNotExist_ExpectedInvalidTokenError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE tokens_atualizacao set usadoEm").WithArgs("hash").WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+	mock.ExpectQuery("SELECT usadoEm IS NOT NULL").WithArgs("hash").WillReturnError(sql.ErrNoRows)
+
+	err = NovoRepositorioDeTokens(db).Rotacionar(context.Background(), "hash", naoDeveGerar(t))
+
+	assert.ErrorIs(t, err, ErrTokenAtualizacaoInvalido)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func naoDeveGerar(t *testing.T) func(modelos.TokenAtualizacao, uint64) (modelos.TokenAtualizacao, error) {
+	return func(modelos.TokenAtualizacao, uint64) (modelos.TokenAtualizacao, error) {
+		t.Fatal("o novo token não deveria ser gerado")
+		return modelos.TokenAtualizacao{}, nil
+	}
+}
+
+var colunasTokenConsumido = []string{"id", "usuario_id", "familia", "hash"}
+
+func TestRotacionar_WhenTokenIsValid_ExpectedConsumeAndInsertCommittedTogether(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE tokens_atualizacao set usadoEm").WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows(colunasTokenConsumido).AddRow(1, 7, "familia", "hash"))
+	mock.ExpectQuery("SELECT versao_token FROM usuarios WHERE id = \\$1 FOR SHARE").WithArgs(uint64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"versao_token"}).AddRow(3))
+	mock.ExpectExec(regexp.QuoteMeta("current_timestamp + make_interval(secs => $4)")).
+		WithArgs(uint64(7), "familia", "novo-hash", float64(3600)).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	var versaoLida uint64
+	err = NovoRepositorioDeTokens(db).Rotacionar(context.Background(), "hash", func(consumido modelos.TokenAtualizacao, versaoToken uint64) (modelos.TokenAtualizacao, error) {
+		versaoLida = versaoToken
+		return modelos.TokenAtualizacao{UsuarioID: consumido.UsuarioID, Familia: consumido.Familia, Hash: "novo-hash", Validade: time.Hour}, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), versaoLida)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRotacionar_WhenNewTokenCannotBeGenerated_ExpectedConsumptionRolledBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE tokens_atualizacao set usadoEm").WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows(colunasTokenConsumido).AddRow(1, 7, "familia", "hash"))
+	mock.ExpectQuery("SELECT versao_token FROM usuarios").WithArgs(uint64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"versao_token"}).AddRow(0))
+	mock.ExpectRollback()
+
+	erroEsperado := errors.New("conexão perdida")
+	err = NovoRepositorioDeTokens(db).Rotacionar(context.Background(), "hash", func(modelos.TokenAtualizacao, uint64) (modelos.TokenAtualizacao, error) {
+		return modelos.TokenAtualizacao{}, erroEsperado
+	})
+
+	assert.ErrorIs(t, err, erroEsperado)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,11 +2,22 @@ package repositorios
 
 import (
 	"api/src/modelos"
+	"context"
 	"database/sql"
 )
 
 type UsuarioRepositorio interface {
-	BuscarPorEmail(email string) (modelos.Usuario, error)
+	Criar(ctx context.Context, usuario modelos.Usuario) (uint64, error)
+	BuscarPorID(ctx context.Context, usuarioID uint64) (modelos.Usuario, error)
+	BuscarPorEmail(ctx context.Context, email string) (modelos.Usuario, error)
+	Atualizar(ctx context.Context, usuarioID uint64, usuario modelos.Usuario) error
+	Deletar(ctx context.Context, usuarioID uint64) error
+	BuscarSenha(ctx context.Context, usuarioID uint64) (string, error)
+	AtualizarSenha(ctx context.Context, usuarioID uint64, senha string) error
+	BuscarVersaoToken(ctx context.Context, usuarioID uint64) (uint64, error)
+	EhAdministrador(ctx context.Context, usuarioID uint64) (bool, error)
+	EmailVerificado(ctx context.Context, usuarioID uint64) (bool, error)
+	VerificarEmail(ctx context.Context, usuarioID uint64, email string) error
 }
 
 type usuarioRepositorio struct {
@@ -17,9 +28,56 @@ func NovoRepositorioDeUsuarios(db *sql.DB) UsuarioRepositorio {
 	return &usuarioRepositorio{db}
 }
 
-func (repositorio *usuarioRepositorio) BuscarPorEmail(email string) (modelos.Usuario, error) {
-	linhas, erro := repositorio.db.Query(
-		"SELECT id, senha FROM usuarios WHERE email = $1", email,
+func (repositorio *usuarioRepositorio) Criar(ctx context.Context, usuario modelos.Usuario) (uint64, error) {
+	defer medir(ctx, "usuarios.criar", "INSERT")()
+
+	query := "INSERT INTO usuarios (nome, nick, email, senha) VALUES ($1, $2, $3, $4) RETURNING id"
+	var ultimoIDInserido uint64
+
+	erro := repositorio.db.QueryRowContext(ctx, query, usuario.Nome, usuario.Nick, usuario.Email, usuario.Senha).Scan(&ultimoIDInserido)
+	if erro != nil {
+		return 0, traduzirErro(erro)
+	}
+
+	return ultimoIDInserido, nil
+}
+
+func (repositorio *usuarioRepositorio) BuscarPorID(ctx context.Context, usuarioID uint64) (modelos.Usuario, error) {
+	defer medir(ctx, "usuarios.buscar_por_id", "SELECT")()
+
+	linhas, erro := repositorio.db.QueryContext(ctx,
+		"SELECT id, nome, nick, email, criadoEm, email_verificado FROM usuarios WHERE id = $1", usuarioID,
+	)
+	if erro != nil {
+		return modelos.Usuario{}, erro
+	}
+	defer linhas.Close()
+
+	var usuario modelos.Usuario
+
+	if !linhas.Next() {
+		return modelos.Usuario{}, nenhumaLinha(linhas)
+	}
+
+	if erro = linhas.Scan(
+		&usuario.ID,
+		&usuario.Nome,
+		&usuario.Nick,
+		&usuario.Email,
+		&usuario.CriadoEm,
+		&usuario.EmailVerificado,
+	); erro != nil {
+		return modelos.Usuario{}, erro
+	}
+
+	return usuario, nil
+}
+
+func (repositorio *usuarioRepositorio) BuscarPorEmail(ctx context.Context, email string) (modelos.Usuario, error) {
+	defer medir(ctx, "usuarios.buscar_por_email", "SELECT")()
+
+	linhas, erro := repositorio.db.QueryContext(ctx,
+		"SELECT id, email, senha, versao_token, totp_ativo FROM usuarios WHERE email = $1", email,
 	)
 
 	if erro != nil {
@@ -29,14 +87,174 @@ func (repositorio *usuarioRepositorio) BuscarPorEmail(email string) (modelos.Usu
 
 	var usuario modelos.Usuario
 
-	if linhas.Next() {
-		if erro = linhas.Scan(
-			&usuario.ID,
-			&usuario.Senha,
-		); erro != nil {
-			return modelos.Usuario{}, erro
-		}
+	if !linhas.Next() {
+		return modelos.Usuario{}, nenhumaLinha(linhas)
+	}
+
+	if erro = linhas.Scan(
+		&usuario.ID,
+		&usuario.Email,
+		&usuario.Senha,
+		&usuario.VersaoToken,
+		&usuario.DoisFatoresAtivo,
+	); erro != nil {
+		return modelos.Usuario{}, erro
 	}
 
 	return usuario, nil
 }
+
+// Atualizar troca os dados do usuário. Um email novo precisa ser verificado de
+// novo.
+func (repositorio *usuarioRepositorio) Atualizar(ctx context.Context, usuarioID uint64, usuario modelos.Usuario) error {
+	defer medir(ctx, "usuarios.atualizar", "UPDATE")()
+
+	statement, erro := repositorio.db.PrepareContext(ctx,
+		"UPDATE usuarios set nome = $1, nick = $2, email_verificado = email_verificado AND email = $3, email = $3 WHERE id = $4",
+	)
+	if erro != nil {
+		return erro
+	}
+	defer statement.Close()
+
+	resultado, erro := statement.ExecContext(ctx, usuario.Nome, usuario.Nick, usuario.Email, usuarioID)
+	if erro != nil {
+		return traduzirErro(erro)
+	}
+
+	return verificarLinhasAfetadas(resultado)
+}
+
+func (repositorio *usuarioRepositorio) Deletar(ctx context.Context, usuarioID uint64) error {
+	defer medir(ctx, "usuarios.deletar", "DELETE")()
+
+	statement, erro := repositorio.db.PrepareContext(ctx, "DELETE FROM usuarios WHERE id = $1")
+	if erro != nil {
+		return erro
+	}
+	defer statement.Close()
+
+	resultado, erro := statement.ExecContext(ctx, usuarioID)
+	if erro != nil {
+		return traduzirErro(erro)
+	}
+
+	return verificarLinhasAfetadas(resultado)
+}
+
+func (repositorio *usuarioRepositorio) BuscarSenha(ctx context.Context, usuarioID uint64) (string, error) {
+	defer medir(ctx, "usuarios.buscar_senha", "SELECT")()
+
+	linhas, erro := repositorio.db.QueryContext(ctx, "SELECT senha FROM usuarios WHERE id = $1", usuarioID)
+	if erro != nil {
+		return "", erro
+	}
+	defer linhas.Close()
+
+	var senha string
+
+	if !linhas.Next() {
+		return "", nenhumaLinha(linhas)
+	}
+
+	if erro = linhas.Scan(&senha); erro != nil {
+		return "", erro
+	}
+
+	return senha, nil
+}
+
+// AtualizarSenha troca a senha do usuário e incrementa a versão dos seus tokens,
+// invalidando todos os tokens emitidos antes da troca, inclusive os de atualização.
+func (repositorio *usuarioRepositorio) AtualizarSenha(ctx context.Context, usuarioID uint64, senha string) error {
+	defer medir(ctx, "usuarios.atualizar_senha", "UPDATE")()
+
+	transacao, erro := repositorio.db.BeginTx(ctx, nil)
+	if erro != nil {
+		return erro
+	}
+	defer transacao.Rollback()
+
+	if erro = trocarSenha(ctx, transacao, usuarioID, senha); erro != nil {
+		return erro
+	}
+
+	return transacao.Commit()
+}
+
+// trocarSenha faz o trabalho de AtualizarSenha dentro da transação informada,
+// que também é usada na redefinição de senha.
+func trocarSenha(ctx context.Context, transacao *sql.Tx, usuarioID uint64, senha string) error {
+	if _, erro := transacao.ExecContext(ctx,
+		"UPDATE usuarios set senha = $1, versao_token = versao_token + 1 WHERE id = $2",
+		senha, usuarioID,
+	); erro != nil {
+		return erro
+	}
+
+	_, erro := transacao.ExecContext(ctx,
+		"UPDATE tokens_atualizacao set revogadoEm = current_timestamp WHERE usuario_id = $1 AND revogadoEm IS NULL",
+		usuarioID,
+	)
+	return erro
+}
+
+func (repositorio *usuarioRepositorio) BuscarVersaoToken(ctx context.Context, usuarioID uint64) (uint64, error) {
+	defer medir(ctx, "usuarios.buscar_versao_token", "SELECT")()
+
+	var versaoToken uint64
+
+	erro := repositorio.db.QueryRowContext(ctx, "SELECT versao_token FROM usuarios WHERE id = $1", usuarioID).Scan(&versaoToken)
+	if erro != nil {
+		return 0, traduzirErro(erro)
+	}
+
+	return versaoToken, nil
+}
+
+// EhAdministrador é consultado a cada uso, e não guardado no token, para que a
+// remoção do papel de administrador valha imediatamente.
+func (repositorio *usuarioRepositorio) EhAdministrador(ctx context.Context, usuarioID uint64) (bool, error) {
+	defer medir(ctx, "usuarios.eh_administrador", "SELECT")()
+
+	var administrador bool
+
+	erro := repositorio.db.QueryRowContext(ctx, "SELECT administrador FROM usuarios WHERE id = $1", usuarioID).Scan(&administrador)
+	if erro != nil {
+		return false, traduzirErro(erro)
+	}
+
+	return administrador, nil
+}
+
+// EmailVerificado informa se o usuário já confirmou o email. É consultado a
+// cada uso, para que a confirmação valha sem um novo login.
+func (repositorio *usuarioRepositorio) EmailVerificado(ctx context.Context, usuarioID uint64) (bool, error) {
+	defer medir(ctx, "usuarios.email_verificado", "SELECT")()
+
+	var verificado bool
+	erro := repositorio.db.QueryRowContext(ctx,
+		"SELECT email_verificado FROM usuarios WHERE id = $1", usuarioID,
+	).Scan(&verificado)
+	if erro != nil {
+		return false, traduzirErro(erro)
+	}
+
+	return verificado, nil
+}
+
+// VerificarEmail confirma o email do usuário. Se o email foi trocado depois
+// do envio do link, nenhuma linha é alterada e ErrNaoEncontrado é retornado.
+func (repositorio *usuarioRepositorio) VerificarEmail(ctx context.Context, usuarioID uint64, email string) error {
+	defer medir(ctx, "usuarios.verificar_email", "UPDATE")()
+
+	resultado, erro := repositorio.db.ExecContext(ctx,
+		"UPDATE usuarios set email_verificado = true WHERE id = $1 AND email = $2",
+		usuarioID, email,
+	)
+	if erro != nil {
+		return traduzirErro(erro)
+	}
+
+	return verificarLinhasAfetadas(resultado)
+}
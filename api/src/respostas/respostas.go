@@ -1,11 +1,64 @@
 package respostas
 
 import (
+	"api/src/erros"
+	"api/src/logs"
+	"api/src/repositorios"
+	"context"
 	"encoding/json"
+	"errors"
 	"log"
+	"log/slog"
 	"net/http"
 )
 
+// prefixoTipoProblema identifica os tipos de problema desta API. O código do
+// erro é acrescentado ao prefixo para formar o campo "type".
+const prefixoTipoProblema = "urn:social-network:erro:"
+
+var (
+	errTempoLimiteExcedido = erros.Novo("interno.tempo_esgotado", "O tempo limite para processar a requisição foi excedido")
+	errInterno             = erros.Novo("interno.erro", "Erro interno do servidor")
+)
+
+// statusPorErro associa cada erro de domínio ao status HTTP que o representa.
+var statusPorErro = []struct {
+	erro   error
+	status int
+}{
+	{repositorios.ErrNaoEncontrado, http.StatusNotFound},
+	{repositorios.ErrConflito, http.StatusConflict},
+	{repositorios.ErrTokenAtualizacaoInvalido, http.StatusUnauthorized},
+	{repositorios.ErrTokenAtualizacaoReutilizado, http.StatusUnauthorized},
+	{repositorios.ErrTokenRedefinicaoInvalido, http.StatusBadRequest},
+}
+
+// codigoPorStatus é usado quando o erro não carrega um código próprio, como
+// os erros de conversão de parâmetros e de leitura de JSON.
+var codigoPorStatus = map[int]string{
+	http.StatusBadRequest:          "requisicao.invalida",
+	http.StatusUnauthorized:        "autenticacao.nao_autorizado",
+	http.StatusForbidden:           "acesso.negado",
+	http.StatusNotFound:            "recurso.nao_encontrado",
+	http.StatusConflict:            "recurso.conflito",
+	http.StatusUnprocessableEntity: "requisicao.ilegivel",
+	http.StatusInternalServerError: "interno.erro",
+	http.StatusGatewayTimeout:      "interno.tempo_esgotado",
+}
+
+// Problema é o corpo de erro no formato da RFC 7807. O campo "erro" repete o
+// detalhe para os clientes que ainda leem o formato antigo.
+type Problema struct {
+	Tipo      string        `json:"type"`
+	Titulo    string        `json:"title"`
+	Status    int           `json:"status"`
+	Detalhe   string        `json:"detail"`
+	Instancia string        `json:"instance,omitempty"`
+	Codigo    string        `json:"code"`
+	Campos    []erros.Campo `json:"errors,omitempty"`
+	Erro      string        `json:"erro"`
+}
+
 func JSON(w http.ResponseWriter, statusCode int, dados interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -17,10 +70,92 @@ func JSON(w http.ResponseWriter, statusCode int, dados interface{}) {
 	}
 }
 
-func Erro(w http.ResponseWriter, statusCode int, erro error) {
-	JSON(w, statusCode, struct {
-		Erro string `json:"erro"`
-	}{
-		Erro: erro.Error(),
-	})
+// Erro responde com um problem+json descrevendo o erro. Erros internos são
+// registrados no log e substituídos por uma mensagem genérica, para não expor
+// detalhes do banco.
+func Erro(w http.ResponseWriter, r *http.Request, statusCode int, erro error) {
+	if statusCode == http.StatusInternalServerError {
+		loggerDaRequisicao(r).Error("Erro interno", "erro", erro)
+		erro = errInterno
+	}
+
+	problema := Problema{
+		Titulo:  http.StatusText(statusCode),
+		Status:  statusCode,
+		Detalhe: erro.Error(),
+		Codigo:  codigoDoErro(statusCode, erro),
+		Erro:    erro.Error(),
+	}
+	problema.Tipo = prefixoTipoProblema + problema.Codigo
+
+	if r != nil {
+		problema.Instancia = r.URL.RequestURI()
+	}
+
+	var validacao *erros.Validacao
+	if errors.As(erro, &validacao) {
+		problema.Campos = validacao.Campos
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(statusCode)
+
+	if erro := json.NewEncoder(w).Encode(problema); erro != nil {
+		loggerDaRequisicao(r).Warn("Erro ao escrever a resposta de erro", "erro", erro)
+	}
+}
+
+// ErroDeDominio responde com o status correspondente ao erro de domínio
+// recebido. Erros desconhecidos são tratados como erro interno.
+func ErroDeDominio(w http.ResponseWriter, r *http.Request, erro error) {
+	Erro(w, r, StatusDoErro(erro), mensagemDoErro(erro))
+}
+
+// StatusDoErro retorna o status HTTP correspondente ao erro.
+func StatusDoErro(erro error) int {
+	for _, mapeamento := range statusPorErro {
+		if errors.Is(erro, mapeamento.erro) {
+			return mapeamento.status
+		}
+	}
+
+	if errors.Is(erro, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
+
+	return http.StatusInternalServerError
+}
+
+func mensagemDoErro(erro error) error {
+	if errors.Is(erro, context.DeadlineExceeded) {
+		return errTempoLimiteExcedido
+	}
+
+	return erro
+}
+
+func codigoDoErro(statusCode int, erro error) string {
+	var validacao *erros.Validacao
+	if errors.As(erro, &validacao) {
+		return validacao.Codigo()
+	}
+
+	var erroComCodigo *erros.Erro
+	if errors.As(erro, &erroComCodigo) {
+		return erroComCodigo.Codigo
+	}
+
+	if codigo, ok := codigoPorStatus[statusCode]; ok {
+		return codigo
+	}
+
+	return "requisicao.erro"
+}
+
+func loggerDaRequisicao(r *http.Request) *slog.Logger {
+	if r == nil {
+		return slog.Default()
+	}
+
+	return logs.DoContexto(r.Context())
 }
@@ -0,0 +1,17 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+func rotasChaves(usuarioController *controllers.UsuarioController) []Rota {
+	return []Rota{
+		{
+			URI:                "/.well-known/jwks.json",
+			Metodo:             http.MethodGet,
+			Funcao:             usuarioController.ChavesPublicas,
+			RequerAutenticacao: false,
+		},
+	}
+}
@@ -0,0 +1,38 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"api/src/limitador"
+	"net/http"
+)
+
+func rotasComentarios(comentariosController *controllers.ComentariosController) []Rota {
+	return []Rota{
+		{
+			URI:                   "/publicacoes/{publicacaoId}/comentarios",
+			Metodo:                http.MethodPost,
+			Funcao:                comentariosController.CriarComentario,
+			RequerAutenticacao:    true,
+			RequerEmailVerificado: true,
+			Limite:                limitador.PorMinuto(30),
+		},
+		{
+			URI:                "/publicacoes/{publicacaoId}/comentarios",
+			Metodo:             http.MethodGet,
+			Funcao:             comentariosController.BuscarComentarios,
+			RequerAutenticacao: true,
+		},
+		{
+			URI:                "/comentarios/{comentarioId}",
+			Metodo:             http.MethodPut,
+			Funcao:             comentariosController.AtualizarComentario,
+			RequerAutenticacao: true,
+		},
+		{
+			URI:                "/comentarios/{comentarioId}",
+			Metodo:             http.MethodDelete,
+			Funcao:             comentariosController.DeletarComentario,
+			RequerAutenticacao: true,
+		},
+	}
+}
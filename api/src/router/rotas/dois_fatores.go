@@ -0,0 +1,32 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"api/src/limitador"
+	"net/http"
+)
+
+func rotasDoisFatores(usuarioController *controllers.UsuarioController) []Rota {
+	return []Rota{
+		{
+			URI:                "/2fa",
+			Metodo:             http.MethodPost,
+			Funcao:             usuarioController.IniciarDoisFatores,
+			RequerAutenticacao: true,
+		},
+		{
+			URI:                "/2fa/confirmar",
+			Metodo:             http.MethodPost,
+			Funcao:             usuarioController.ConfirmarDoisFatores,
+			RequerAutenticacao: true,
+			Limite:             limitador.PorMinuto(5),
+		},
+		{
+			URI:                "/2fa/desativar",
+			Metodo:             http.MethodPost,
+			Funcao:             usuarioController.DesativarDoisFatores,
+			RequerAutenticacao: true,
+			Limite:             limitador.PorMinuto(5),
+		},
+	}
+}
@@ -2,14 +2,44 @@ package rotas
 
 import (
 	"api/src/controllers"
+	"api/src/limitador"
 	"net/http"
 )
 
-func rotaLogin(usuarioController *controllers.UsuarioController) Rota {
-	return Rota{
-		URI:                "/login",
-		Metodo:             http.MethodPost,
-		Funcao:             usuarioController.Login,
-		RequerAutenticacao: false,
+func rotasLogin(usuarioController *controllers.UsuarioController) []Rota {
+	return []Rota{
+		{
+			URI:                "/login",
+			Metodo:             http.MethodPost,
+			Funcao:             usuarioController.Login,
+			RequerAutenticacao: false,
+			Limite:             limitador.PorMinuto(5),
+		},
+		{
+			URI:                "/login/refresh",
+			Metodo:             http.MethodPost,
+			Funcao:             usuarioController.RenovarToken,
+			RequerAutenticacao: false,
+			Limite:             limitador.PorMinuto(10),
+		},
+		{
+			URI:                "/login/2fa",
+			Metodo:             http.MethodPost,
+			Funcao:             usuarioController.LoginDoisFatores,
+			RequerAutenticacao: false,
+			Limite:             limitador.PorMinuto(5),
+		},
+		{
+			URI:                "/usuarios/{usuarioId}/desbloquear-login",
+			Metodo:             http.MethodPost,
+			Funcao:             usuarioController.DesbloquearLogin,
+			RequerAutenticacao: true,
+		},
+		{
+			URI:                "/logout",
+			Metodo:             http.MethodPost,
+			Funcao:             usuarioController.Logout,
+			RequerAutenticacao: false,
+		},
 	}
 }
@@ -2,16 +2,19 @@ package rotas
 
 import (
 	"api/src/controllers"
+	"api/src/limitador"
 	"net/http"
 )
 
 func rotasPublicacoes(publicacoesController *controllers.PublicacoesController) []Rota {
 	return []Rota{
 		{
-			URI:                "/publicacoes",
-			Metodo:             http.MethodPost,
-			Funcao:             publicacoesController.CriarPublicacao,
-			RequerAutenticacao: true,
+			URI:                   "/publicacoes",
+			Metodo:                http.MethodPost,
+			Funcao:                publicacoesController.CriarPublicacao,
+			RequerAutenticacao:    true,
+			RequerEmailVerificado: true,
+			Limite:                limitador.PorMinuto(30),
 		},
 		{
 			URI:                "/publicacoes",
@@ -44,16 +47,25 @@ func rotasPublicacoes(publicacoesController *controllers.PublicacoesController)
 			RequerAutenticacao: true,
 		},
 		{
-			URI:                "/publicacoes/{publicacaoId}/curtir",
-			Metodo:             http.MethodPost,
-			Funcao:             publicacoesController.CurtirPublicacao,
-			RequerAutenticacao: true,
+			URI:                   "/publicacoes/{publicacaoId}/curtir",
+			Metodo:                http.MethodPost,
+			Funcao:                publicacoesController.CurtirPublicacao,
+			RequerAutenticacao:    true,
+			RequerEmailVerificado: true,
+			Limite:                limitador.PorMinuto(60),
 		},
 		{
 			URI:                "/publicacoes/{publicacaoId}/descurtir",
 			Metodo:             http.MethodPost,
 			Funcao:             publicacoesController.DescurtirPublicacao,
 			RequerAutenticacao: true,
+			Limite:             limitador.PorMinuto(60),
+		},
+		{
+			URI:                "/publicacoes/{publicacaoId}/curtidas",
+			Metodo:             http.MethodGet,
+			Funcao:             publicacoesController.BuscarCurtidas,
+			RequerAutenticacao: true,
 		},
 	}
 }
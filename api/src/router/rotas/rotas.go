@@ -2,9 +2,13 @@ package rotas
 
 import (
 	"api/src/controllers"
+	"api/src/limitador"
+	"api/src/metrics"
 	"api/src/middlewares"
+	"api/src/rastreamento"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -14,19 +18,49 @@ type Rota struct {
 	Metodo             string
 	Funcao             http.HandlerFunc
 	RequerAutenticacao bool
+	// RequerEmailVerificado recusa os usuários que não confirmaram o email.
+	// Só tem efeito junto com RequerAutenticacao.
+	RequerEmailVerificado bool
+	// SemMetricas tira a rota das métricas de requisição, como nas
+	// verificações de saúde, que são chamadas com frequência pelo orquestrador.
+	SemMetricas bool
+	// Limite é o orçamento de requisições de cada cliente na rota: por usuário
+	// nas rotas autenticadas e por IP nas demais. O valor zero não limita.
+	Limite limitador.Limite
 }
 
-func Configurar(r *mux.Router, usuarioController *controllers.UsuarioController, publicacoesController *controllers.PublicacoesController) *mux.Router {
-	r.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
+func Configurar(r *mux.Router, tempoLimiteConsulta time.Duration, armazenamentoDeLimites limitador.Armazenamento, usuarioController *controllers.UsuarioController, publicacoesController *controllers.PublicacoesController, seguidoresController *controllers.SeguidoresController, comentariosController *controllers.ComentariosController, senhaController *controllers.SenhaController, saudeController *controllers.SaudeController) *mux.Router {
+	r.Handle("/metrics", promhttp.HandlerFor(metrics.Registro, promhttp.HandlerOpts{Registry: metrics.Registro})).Methods(http.MethodGet)
 
 	rotas := rotasPublicacoes(publicacoesController)
-	rotas = append(rotas, rotaLogin(usuarioController))
+	rotas = append(rotas, rotasUsuarios(usuarioController)...)
+	rotas = append(rotas, rotasSeguidores(seguidoresController)...)
+	rotas = append(rotas, rotasComentarios(comentariosController)...)
+	rotas = append(rotas, rotasLogin(usuarioController)...)
+	rotas = append(rotas, rotasDoisFatores(usuarioController)...)
+	rotas = append(rotas, rotasChaves(usuarioController)...)
+	rotas = append(rotas, rotasSenha(senhaController)...)
+	rotas = append(rotas, rotasSaude(saudeController)...)
 
 	for _, rota := range rotas {
-		handler := middlewares.PrometheusMiddleware(middlewares.Logger(rota.Funcao))
+		handler := rota.Funcao
+
+		if rota.Limite.Ativo() {
+			handler = middlewares.LimitarRequisicoes(armazenamentoDeLimites, rota.Limite, handler)
+		}
+
+		if rota.RequerEmailVerificado {
+			handler = middlewares.ExigirEmailVerificado(usuarioController.Repositorio, handler)
+		}
 
 		if rota.RequerAutenticacao {
-			handler = middlewares.PrometheusMiddleware(middlewares.Logger(middlewares.Autenticar(rota.Funcao)))
+			handler = middlewares.Autenticar(usuarioController.Emissor, usuarioController.Repositorio, handler)
+		}
+
+		handler = middlewares.Logger(rastreamento.Middleware(rota.URI, middlewares.LimitarTempoDeConsulta(tempoLimiteConsulta, handler)))
+
+		if !rota.SemMetricas {
+			handler = middlewares.PrometheusMiddleware(handler)
 		}
 
 		r.HandleFunc(rota.URI, handler).Methods(rota.Metodo)
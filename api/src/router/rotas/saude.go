@@ -0,0 +1,25 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+func rotasSaude(saudeController *controllers.SaudeController) []Rota {
+	return []Rota{
+		{
+			URI:                "/healthz",
+			Metodo:             http.MethodGet,
+			Funcao:             saudeController.Vivo,
+			RequerAutenticacao: false,
+			SemMetricas:        true,
+		},
+		{
+			URI:                "/readyz",
+			Metodo:             http.MethodGet,
+			Funcao:             saudeController.Pronto,
+			RequerAutenticacao: false,
+			SemMetricas:        true,
+		},
+	}
+}
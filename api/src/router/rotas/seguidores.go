@@ -0,0 +1,36 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+func rotasSeguidores(seguidoresController *controllers.SeguidoresController) []Rota {
+	return []Rota{
+		{
+			URI:                   "/usuarios/{usuarioId}/seguir",
+			Metodo:                http.MethodPost,
+			Funcao:                seguidoresController.SeguirUsuario,
+			RequerAutenticacao:    true,
+			RequerEmailVerificado: true,
+		},
+		{
+			URI:                "/usuarios/{usuarioId}/parar-de-seguir",
+			Metodo:             http.MethodPost,
+			Funcao:             seguidoresController.PararDeSeguirUsuario,
+			RequerAutenticacao: true,
+		},
+		{
+			URI:                "/usuarios/{usuarioId}/seguidores",
+			Metodo:             http.MethodGet,
+			Funcao:             seguidoresController.BuscarSeguidores,
+			RequerAutenticacao: true,
+		},
+		{
+			URI:                "/usuarios/{usuarioId}/seguindo",
+			Metodo:             http.MethodGet,
+			Funcao:             seguidoresController.BuscarSeguindo,
+			RequerAutenticacao: true,
+		},
+	}
+}
@@ -0,0 +1,26 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"api/src/limitador"
+	"net/http"
+)
+
+func rotasSenha(senhaController *controllers.SenhaController) []Rota {
+	return []Rota{
+		{
+			URI:                "/senha/esqueci",
+			Metodo:             http.MethodPost,
+			Funcao:             senhaController.EsqueciSenha,
+			RequerAutenticacao: false,
+			Limite:             limitador.PorMinuto(3),
+		},
+		{
+			URI:                "/senha/redefinir",
+			Metodo:             http.MethodPost,
+			Funcao:             senhaController.RedefinirSenha,
+			RequerAutenticacao: false,
+			Limite:             limitador.PorMinuto(5),
+		},
+	}
+}
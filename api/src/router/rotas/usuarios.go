@@ -0,0 +1,58 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"api/src/limitador"
+	"net/http"
+)
+
+func rotasUsuarios(usuarioController *controllers.UsuarioController) []Rota {
+	return []Rota{
+		{
+			URI:                "/usuarios",
+			Metodo:             http.MethodPost,
+			Funcao:             usuarioController.CriarUsuario,
+			RequerAutenticacao: false,
+			Limite:             limitador.PorMinuto(5),
+		},
+		{
+			URI:                "/usuarios/verificar-email",
+			Metodo:             http.MethodPost,
+			Funcao:             usuarioController.VerificarEmail,
+			RequerAutenticacao: false,
+			Limite:             limitador.PorMinuto(10),
+		},
+		{
+			URI:                "/usuarios/reenviar-verificacao-email",
+			Metodo:             http.MethodPost,
+			Funcao:             usuarioController.ReenviarVerificacaoEmail,
+			RequerAutenticacao: true,
+			Limite:             limitador.PorHora(3),
+		},
+		{
+			URI:                "/usuarios/{usuarioId}",
+			Metodo:             http.MethodGet,
+			Funcao:             usuarioController.BuscarUsuario,
+			RequerAutenticacao: true,
+		},
+		{
+			URI:                "/usuarios/{usuarioId}",
+			Metodo:             http.MethodPut,
+			Funcao:             usuarioController.AtualizarUsuario,
+			RequerAutenticacao: true,
+		},
+		{
+			URI:                "/usuarios/{usuarioId}",
+			Metodo:             http.MethodDelete,
+			Funcao:             usuarioController.DeletarUsuario,
+			RequerAutenticacao: true,
+		},
+		{
+			URI:                "/usuarios/{usuarioId}/atualizar-senha",
+			Metodo:             http.MethodPost,
+			Funcao:             usuarioController.AtualizarSenha,
+			RequerAutenticacao: true,
+			Limite:             limitador.PorMinuto(5),
+		},
+	}
+}
@@ -2,11 +2,14 @@ package router
 
 import (
 	"api/src/controllers"
+	"api/src/limitador"
 	"api/src/router/rotas"
+	"time"
+
 	"github.com/gorilla/mux"
 )
 
-func Gerar(usuarioController *controllers.UsuarioController, publicacoesController *controllers.PublicacoesController) *mux.Router {
+func Gerar(tempoLimiteConsulta time.Duration, armazenamentoDeLimites limitador.Armazenamento, usuarioController *controllers.UsuarioController, publicacoesController *controllers.PublicacoesController, seguidoresController *controllers.SeguidoresController, comentariosController *controllers.ComentariosController, senhaController *controllers.SenhaController, saudeController *controllers.SaudeController) *mux.Router {
 	r := mux.NewRouter()
-	return rotas.Configurar(r, usuarioController, publicacoesController)
+	return rotas.Configurar(r, tempoLimiteConsulta, armazenamentoDeLimites, usuarioController, publicacoesController, seguidoresController, comentariosController, senhaController, saudeController)
 }
@@ -0,0 +1,135 @@
+// Package servidor executa a API HTTP e coordena o encerramento gracioso:
+// ao receber o sinal de parada, o servidor deixa de aceitar conexões, espera
+// as requisições em andamento por um tempo limitado e só então libera os
+// recursos registrados, como a conexão com o banco.
+package servidor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Tempos reúne os prazos do servidor HTTP.
+type Tempos struct {
+	// EsperaAntesDeEncerrar mantém o servidor atendendo, já marcado como
+	// encerrando, para que o balanceador veja o /readyz falhar e pare de
+	// enviar tráfego antes que o listener seja fechado.
+	EsperaAntesDeEncerrar time.Duration
+
+	Leitura      time.Duration
+	Escrita      time.Duration
+	Ocioso       time.Duration
+	Encerramento time.Duration
+}
+
+// Finalizador libera um recurso durante o encerramento. O contexto recebido
+// expira junto com o prazo de encerramento.
+type Finalizador struct {
+	Nome   string
+	Funcao func(ctx context.Context) error
+}
+
+// Servidor envolve o http.Server com a sequência de encerramento da API.
+type Servidor struct {
+	http          *http.Server
+	tempos        Tempos
+	finalizadores []Finalizador
+	encerrando    atomic.Bool
+}
+
+// Novo cria o servidor que atende no endereço informado. O handler é
+// informado só na execução, para que as rotas possam consultar o servidor,
+// como a verificação de readiness faz com Encerrando.
+func Novo(endereco string, tempos Tempos) *Servidor {
+	return &Servidor{
+		http: &http.Server{
+			Addr:         endereco,
+			ReadTimeout:  tempos.Leitura,
+			WriteTimeout: tempos.Escrita,
+			IdleTimeout:  tempos.Ocioso,
+		},
+		tempos: tempos,
+	}
+}
+
+// AoEncerrar registra um finalizador. Os finalizadores são executados na
+// ordem em que foram registrados, depois que as requisições terminarem.
+func (s *Servidor) AoEncerrar(nome string, funcao func(ctx context.Context) error) {
+	s.finalizadores = append(s.finalizadores, Finalizador{Nome: nome, Funcao: funcao})
+}
+
+// Encerrando indica se o encerramento já começou.
+func (s *Servidor) Encerrando() bool {
+	return s.encerrando.Load()
+}
+
+// Executar abre o endereço configurado e atende até que o contexto seja
+// cancelado.
+func (s *Servidor) Executar(ctx context.Context, handler http.Handler) error {
+	listener, erro := net.Listen("tcp", s.http.Addr)
+	if erro != nil {
+		return erro
+	}
+
+	return s.Servir(ctx, listener, handler)
+}
+
+// Servir atende as conexões do listener até que o contexto seja cancelado ou
+// o servidor falhe, e então executa o encerramento.
+func (s *Servidor) Servir(ctx context.Context, listener net.Listener, handler http.Handler) error {
+	s.http.Handler = handler
+
+	erroServidor := make(chan error, 1)
+	go func() {
+		erroServidor <- s.http.Serve(listener)
+	}()
+
+	var erro error
+	select {
+	case <-ctx.Done():
+	case erro = <-erroServidor:
+		if errors.Is(erro, http.ErrServerClosed) {
+			erro = nil
+		}
+	}
+
+	if erroEncerramento := s.encerrar(); erroEncerramento != nil {
+		erro = errors.Join(erro, erroEncerramento)
+	}
+
+	return erro
+}
+
+// encerrar para de aceitar conexões, espera as requisições em andamento até o
+// prazo de encerramento e executa os finalizadores. Os finalizadores rodam
+// mesmo quando o prazo expira, para que o banco sempre seja fechado.
+func (s *Servidor) encerrar() error {
+	s.encerrando.Store(true)
+	slog.Info("Encerrando servidor", "espera", s.tempos.EsperaAntesDeEncerrar, "prazo", s.tempos.Encerramento)
+	time.Sleep(s.tempos.EsperaAntesDeEncerrar)
+
+	ctx, cancelar := context.WithTimeout(context.Background(), s.tempos.Encerramento)
+	defer cancelar()
+
+	var erros []error
+	if erro := s.http.Shutdown(ctx); erro != nil {
+		erros = append(erros, fmt.Errorf("encerrar servidor HTTP: %w", erro))
+		s.http.Close()
+	}
+
+	for _, finalizador := range s.finalizadores {
+		if erro := finalizador.Funcao(ctx); erro != nil {
+			erros = append(erros, fmt.Errorf("encerrar %s: %w", finalizador.Nome, erro))
+		}
+	}
+
+	slog.Info("Servidor encerrado")
+
+	return errors.Join(erros...)
+}
@@ -0,0 +1,168 @@
+package servidor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func iniciar(t *testing.T, handler http.Handler, tempos Tempos) (*Servidor, string, context.CancelFunc, <-chan error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	s := Novo(listener.Addr().String(), tempos)
+	ctx, cancelar := context.WithCancel(context.Background())
+
+	resultado := make(chan error, 1)
+	go func() {
+		resultado <- s.Servir(ctx, listener, handler)
+	}()
+
+	return s, "http://" + listener.Addr().String(), cancelar, resultado
+}
+
+func TestServir_WhenStoppedWithRequestInFlight_ExpectedRequestFinishedBeforeFinalizers(t *testing.T) {
+	requisicaoIniciada := make(chan struct{})
+	liberarRequisicao := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(requisicaoIniciada)
+		<-liberarRequisicao
+		w.Write([]byte("ok"))
+	})
+
+	s, endereco, cancelar, resultado := iniciar(t, handler, Tempos{Encerramento: 5 * time.Second})
+
+	var (
+		mutex  sync.Mutex
+		ordem  []string
+		status int
+		corpo  []byte
+	)
+	registrar := func(evento string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		ordem = append(ordem, evento)
+	}
+	s.AoEncerrar("banco", func(ctx context.Context) error {
+		registrar("banco")
+		return nil
+	})
+	s.AoEncerrar("metricas", func(ctx context.Context) error {
+		registrar("metricas")
+		return nil
+	})
+
+	respostaRecebida := make(chan struct{})
+	go func() {
+		defer close(respostaRecebida)
+		resposta, err := http.Get(endereco)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer resposta.Body.Close()
+		status = resposta.StatusCode
+		corpo, _ = io.ReadAll(resposta.Body)
+		registrar("requisicao")
+	}()
+
+	<-requisicaoIniciada
+	cancelar()
+
+	assert.Eventually(t, s.Encerrando, time.Second, 10*time.Millisecond)
+	close(liberarRequisicao)
+
+	assert.NoError(t, <-resultado)
+	<-respostaRecebida
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "ok", string(corpo))
+	assert.Equal(t, []string{"requisicao", "banco", "metricas"}, ordem)
+}
+
+func TestServir_WhenStopped_ExpectedNewConnectionsRefused(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	_, endereco, cancelar, resultado := iniciar(t, handler, Tempos{Encerramento: time.Second})
+
+	cancelar()
+	assert.NoError(t, <-resultado)
+
+	_, err := http.Get(endereco)
+	assert.Error(t, err)
+}
+
+func TestServir_WhenDrainPeriodExpires_ExpectedErrorAndFinalizersStillRun(t *testing.T) {
+	requisicaoIniciada := make(chan struct{})
+	liberarRequisicao := make(chan struct{})
+	defer close(liberarRequisicao)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(requisicaoIniciada)
+		<-liberarRequisicao
+	})
+
+	s, endereco, cancelar, resultado := iniciar(t, handler, Tempos{Encerramento: 50 * time.Millisecond})
+
+	bancoFechado := false
+	s.AoEncerrar("banco", func(ctx context.Context) error {
+		bancoFechado = true
+		return nil
+	})
+
+	go http.Get(endereco)
+	<-requisicaoIniciada
+	cancelar()
+
+	select {
+	case err := <-resultado:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(2 * time.Second):
+		t.Fatal("o encerramento não respeitou o prazo")
+	}
+	assert.True(t, bancoFechado)
+}
+
+func TestServir_WhenFinalizerFails_ExpectedErrorReturnedAndOthersRun(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	s, _, cancelar, resultado := iniciar(t, handler, Tempos{Encerramento: time.Second})
+
+	erroBanco := errors.New("falha ao fechar")
+	metricasFinalizadas := false
+	s.AoEncerrar("banco", func(ctx context.Context) error { return erroBanco })
+	s.AoEncerrar("metricas", func(ctx context.Context) error {
+		metricasFinalizadas = true
+		return nil
+	})
+
+	cancelar()
+
+	assert.ErrorIs(t, <-resultado, erroBanco)
+	assert.True(t, metricasFinalizadas)
+}
+
+func TestServir_WhenStoppedWithShutdownDelay_ExpectedRequestsServedWhileMarkedAsShuttingDown(t *testing.T) {
+	var s *Servidor
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.Encerrando() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+
+	s, endereco, cancelar, resultado := iniciar(t, handler, Tempos{EsperaAntesDeEncerrar: 300 * time.Millisecond, Encerramento: time.Second})
+
+	cancelar()
+	assert.Eventually(t, s.Encerrando, time.Second, 10*time.Millisecond)
+
+	resposta, err := http.Get(endereco)
+	if assert.NoError(t, err) {
+		resposta.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, resposta.StatusCode)
+	}
+
+	assert.NoError(t, <-resultado)
+}
@@ -1,11 +1,9 @@
 #!/bin/bash
 
-# Run application migrations
-if [[ $DATABASE_SCHEMA != "" && $DATABASE_MIGRATIONS_PATH != "" ]]; then
-  echo "Searching for migrations..."
-  uri="$DATABASE_SCHEMA://$POSTGRES_USER:$POSTGRES_PASSWORD@$DATABASE_HOST:$DATABASE_PORT/$POSTGRES_NAME?sslmode=$DATABASE_SSL_MODE"
-  migrate -path $DATABASE_MIGRATIONS_PATH -database $uri up
-fi
+# Wait for the database before starting. Migrations are embedded in the binary
+# and applied on startup when DATABASE_MIGRATE=true (or with the -migrar flag).
+/opt/bin/wait-for-it.sh --timeout=20 "$DATABASE_HOST:$DATABASE_PORT"
 
-# Run application binary
-./main
+# exec replaces the shell, so the binary runs as PID 1 and receives the SIGTERM
+# sent by docker compose to drain requests and close the database.
+exec ./main